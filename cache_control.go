@@ -0,0 +1,370 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// CacheScope says who may cache a response: any shared cache (PUBLIC) or
+// only the requesting client (PRIVATE).
+type CacheScope string
+
+const (
+	CacheScopePublic  CacheScope = "PUBLIC"
+	CacheScopePrivate CacheScope = "PRIVATE"
+)
+
+// CacheHint is a cache-control annotation on a field or type: results may
+// be cached for MaxAge seconds, by caches allowed by Scope. An empty
+// Scope means PUBLIC. With InheritMaxAge, MaxAge is ignored and a
+// non-root field is cacheable as long as its parent is, as leaf fields
+// without a hint are.
+//
+// A field's own hint takes precedence over the hint of the type it
+// returns.
+type CacheHint struct {
+	MaxAge        int
+	Scope         CacheScope
+	InheritMaxAge bool
+}
+
+// CacheControlScopeEnumType is the type of the `scope` argument of
+// CacheControlDirective.
+var CacheControlScopeEnumType = NewEnum(EnumConfig{
+	Name: "CacheControlScope",
+	Values: EnumValueConfigMap{
+		string(CacheScopePublic):  &EnumValueConfig{Value: CacheScopePublic},
+		string(CacheScopePrivate): &EnumValueConfig{Value: CacheScopePrivate},
+	},
+})
+
+// CacheControlDirective declares
+//
+//	@cacheControl(maxAge: Int, scope: CacheControlScope, inheritMaxAge: Boolean)
+//
+// on field definitions, objects, interfaces and unions, the SDL form of
+// CacheHint. ApplyCacheControlDirectives reads its uses. To advertise it
+// through introspection, add it to SchemaConfig.Directives and
+// CacheControlScopeEnumType to SchemaConfig.Types.
+var CacheControlDirective = NewDirective(DirectiveConfig{
+	Name:        "cacheControl",
+	Description: "Sets the cache hint of a field, or of the fields returning a type.",
+	Locations: []string{
+		DirectiveLocationFieldDefinition,
+		DirectiveLocationObject,
+		DirectiveLocationInterface,
+		DirectiveLocationUnion,
+	},
+	Args: FieldConfigArgument{
+		"maxAge":        &ArgumentConfig{Type: Int},
+		"scope":         &ArgumentConfig{Type: CacheControlScopeEnumType},
+		"inheritMaxAge": &ArgumentConfig{Type: Boolean},
+	},
+})
+
+// ApplyCacheControlDirectives sets the cache hints of schema's types and
+// fields from the @cacheControl directives of doc, a type system
+// document describing schema, e.g.
+//
+//	type Post @cacheControl(maxAge: 240) {
+//	  votes: Int @cacheControl(maxAge: 30)
+//	}
+//	extend type Query @cacheControl(maxAge: 60)
+//	extend interface Node @cacheControl(maxAge: 60)
+//
+// Types and fields without the directive keep their hints; the directive
+// on a type or field schema lacks is an error. Hints are read by
+// requests, so apply them before serving any.
+func ApplyCacheControlDirectives(schema *Schema, doc *ast.Document) error {
+	for _, def := range doc.Definitions {
+		var (
+			name       *ast.Name
+			directives []*ast.Directive
+			fields     []*ast.FieldDefinition
+		)
+		switch def := def.(type) {
+		case *ast.ObjectDefinition:
+			name, directives, fields = def.Name, def.Directives, def.Fields
+		case *ast.TypeExtensionDefinition:
+			if def.Definition == nil {
+				continue
+			}
+			name, directives, fields = def.Definition.Name, def.Definition.Directives, def.Definition.Fields
+		case *ast.InterfaceDefinition:
+			name, directives, fields = def.Name, def.Directives, def.Fields
+		case *ast.InterfaceExtensionDefinition:
+			if def.Definition == nil {
+				continue
+			}
+			name, directives, fields = def.Definition.Name, def.Definition.Directives, def.Definition.Fields
+		case *ast.UnionDefinition:
+			name, directives = def.Name, def.Directives
+		case *ast.UnionExtensionDefinition:
+			if def.Definition == nil {
+				continue
+			}
+			name, directives = def.Definition.Name, def.Definition.Directives
+		default:
+			continue
+		}
+		if name == nil {
+			continue
+		}
+		if err := applyCacheControlDirectives(schema, name.Value, directives, fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyCacheControlDirectives(schema *Schema, typeName string, directives []*ast.Directive, fields []*ast.FieldDefinition) error {
+	hint, err := cacheHintFromDirectives(directives)
+	if err != nil {
+		return fmt.Errorf("cacheControl: type %q: %v", typeName, err)
+	}
+	var fieldMap FieldDefinitionMap
+	switch t := schema.Type(typeName).(type) {
+	case *Object:
+		if hint != nil {
+			t.typeConfig.CacheControl = hint
+		}
+		fieldMap = t.Fields()
+	case *Interface:
+		if hint != nil {
+			t.typeConfig.CacheControl = hint
+		}
+		fieldMap = t.Fields()
+	case *Union:
+		if hint != nil {
+			t.typeConfig.CacheControl = hint
+		}
+	default:
+		if hint == nil && !hasCacheControlDirective(fields) {
+			return nil
+		}
+		return fmt.Errorf("cacheControl: %q is not an object, interface or union type of the schema", typeName)
+	}
+	for _, field := range fields {
+		if field == nil || field.Name == nil {
+			continue
+		}
+		hint, err := cacheHintFromDirectives(field.Directives)
+		if err != nil {
+			return fmt.Errorf("cacheControl: field %s.%s: %v", typeName, field.Name.Value, err)
+		}
+		if hint == nil {
+			continue
+		}
+		fieldDef, ok := fieldMap[field.Name.Value]
+		if !ok {
+			return fmt.Errorf("cacheControl: type %q has no field %q", typeName, field.Name.Value)
+		}
+		fieldDef.CacheControl = hint
+	}
+	return nil
+}
+
+func hasCacheControlDirective(fields []*ast.FieldDefinition) bool {
+	for _, field := range fields {
+		if field == nil {
+			continue
+		}
+		for _, d := range field.Directives {
+			if d != nil && d.Name != nil && d.Name.Value == CacheControlDirective.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cacheHintFromDirectives returns the hint set by the @cacheControl
+// directive among directives, or nil if there is none.
+func cacheHintFromDirectives(directives []*ast.Directive) (*CacheHint, error) {
+	var directive *ast.Directive
+	for _, d := range directives {
+		if d == nil || d.Name == nil || d.Name.Value != CacheControlDirective.Name {
+			continue
+		}
+		if directive != nil {
+			return nil, fmt.Errorf("@%s is used more than once", CacheControlDirective.Name)
+		}
+		directive = d
+	}
+	if directive == nil {
+		return nil, nil
+	}
+	argDefs := map[string]*Argument{}
+	for _, arg := range CacheControlDirective.Args {
+		argDefs[arg.Name()] = arg
+	}
+	for _, arg := range directive.Arguments {
+		if arg == nil || arg.Name == nil {
+			continue
+		}
+		argDef, ok := argDefs[arg.Name.Value]
+		if !ok {
+			return nil, fmt.Errorf("unknown argument %q of @%s", arg.Name.Value, CacheControlDirective.Name)
+		}
+		if _, ok := arg.Value.(*ast.Variable); ok {
+			return nil, fmt.Errorf("argument %q of @%s can't be a variable", arg.Name.Value, CacheControlDirective.Name)
+		}
+		if valid, messages := isValidLiteralValue(argDef.Type, arg.Value); !valid {
+			return nil, fmt.Errorf("argument %q of @%s: %s", arg.Name.Value, CacheControlDirective.Name, strings.Join(messages, " "))
+		}
+	}
+	args := getArgumentValues(CacheControlDirective.Args, directive.Arguments, nil)
+	hint := &CacheHint{}
+	if maxAge, ok := args["maxAge"].(int); ok {
+		hint.MaxAge = maxAge
+	}
+	if scope, ok := args["scope"].(CacheScope); ok {
+		hint.Scope = scope
+	}
+	if inherit, ok := args["inheritMaxAge"].(bool); ok {
+		hint.InheritMaxAge = inherit
+	}
+	return hint, nil
+}
+
+// CachePolicy is the cache policy of a whole response: the smallest MaxAge
+// of every field that contributed a hint, and PRIVATE if any of them was
+// private.
+type CachePolicy struct {
+	MaxAge int        `json:"maxAge"`
+	Scope  CacheScope `json:"scope"`
+}
+
+// CacheControlConfig enables cache hint computation for a request.
+//
+// Root fields and fields returning object, interface or union types that
+// have no hint (on the field or on the returned type) get DefaultMaxAge,
+// which is 0 — not cacheable — unless set. Leaf fields without a hint
+// don't affect the policy: they are cacheable as long as their parent is.
+type CacheControlConfig struct {
+	DefaultMaxAge int
+}
+
+// cacheControlExtensionKey is the key of the policy in Result.Extensions.
+const cacheControlExtensionKey = "cacheControl"
+
+// CachePolicy returns the response's cache policy. ok is false when cache
+// control wasn't enabled for the request.
+func (r *Result) CachePolicy() (policy CachePolicy, ok bool) {
+	policy, ok = r.Extensions[cacheControlExtensionKey].(CachePolicy)
+	return policy, ok
+}
+
+// FieldCacheControl is handed to resolvers through ResolveInfo.CacheControl
+// to set a dynamic cache hint for the field being resolved.
+type FieldCacheControl struct {
+	hint *CacheHint
+}
+
+// SetCacheHint replaces the field's static hint (from the field or its
+// type) with hint. It is a no-op on a nil receiver, so resolvers can call
+// it whether or not cache control is enabled.
+func (c *FieldCacheControl) SetCacheHint(hint CacheHint) {
+	if c == nil {
+		return
+	}
+	c.hint = &hint
+}
+
+// cachePolicyAccumulator folds field hints into a response policy. Safe
+// for concurrent use.
+type cachePolicyAccumulator struct {
+	config CacheControlConfig
+
+	mu      sync.Mutex
+	policy  CachePolicy
+	hasHint bool
+}
+
+func newCachePolicyAccumulator(config CacheControlConfig) *cachePolicyAccumulator {
+	return &cachePolicyAccumulator{
+		config: config,
+		policy: CachePolicy{Scope: CacheScopePublic},
+	}
+}
+
+// restrictField folds in the hint for one resolved field.
+func (a *cachePolicyAccumulator) restrictField(fieldDef *FieldDefinition, returnType Output, isRoot bool, dynamic *FieldCacheControl) {
+	hint := fieldDef.CacheControl
+	if dynamic != nil && dynamic.hint != nil {
+		hint = dynamic.hint
+	}
+	named := unwrapNamedType(returnType)
+	if hint == nil {
+		hint = typeCacheHint(named)
+	}
+	if hint != nil && hint.InheritMaxAge {
+		if !isRoot {
+			// the parent's maxAge is in the policy already
+			a.restrictScope(hint.Scope)
+			return
+		}
+		// root fields have no parent to inherit from
+		hint = &CacheHint{MaxAge: a.config.DefaultMaxAge, Scope: hint.Scope}
+	}
+	if hint == nil {
+		if !isRoot && !isCompositeOutput(named) {
+			return
+		}
+		hint = &CacheHint{MaxAge: a.config.DefaultMaxAge}
+	}
+	a.restrict(*hint)
+}
+
+func (a *cachePolicyAccumulator) restrictScope(scope CacheScope) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if scope == CacheScopePrivate {
+		a.policy.Scope = CacheScopePrivate
+	}
+}
+
+func (a *cachePolicyAccumulator) restrict(hint CacheHint) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.hasHint || hint.MaxAge < a.policy.MaxAge {
+		a.policy.MaxAge = hint.MaxAge
+	}
+	a.hasHint = true
+	if hint.Scope == CacheScopePrivate {
+		a.policy.Scope = CacheScopePrivate
+	}
+}
+
+func (a *cachePolicyAccumulator) result() CachePolicy {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.hasHint {
+		return CachePolicy{MaxAge: a.config.DefaultMaxAge, Scope: a.policy.Scope}
+	}
+	return a.policy
+}
+
+// typeCacheHint returns the hint configured on a composite type, if any.
+func typeCacheHint(t Output) *CacheHint {
+	switch t := t.(type) {
+	case *Object:
+		return t.typeConfig.CacheControl
+	case *Interface:
+		return t.typeConfig.CacheControl
+	case *Union:
+		return t.typeConfig.CacheControl
+	}
+	return nil
+}
+
+func isCompositeOutput(t Output) bool {
+	switch t.(type) {
+	case *Object, *Interface, *Union:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,331 @@
+package graphql_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/testutil"
+)
+
+func cacheControlTestSchema(t *testing.T) graphql.Schema {
+	return cacheControlSchema(t, true)
+}
+
+// cacheControlSchema returns the test schema, with its cache hints set in
+// its configuration if hinted is set.
+func cacheControlSchema(t *testing.T, hinted bool) graphql.Schema {
+	hint := func(h graphql.CacheHint) *graphql.CacheHint {
+		if !hinted {
+			return nil
+		}
+		return &h
+	}
+	authorType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Author",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{Type: graphql.String},
+		},
+		CacheControl: hint(graphql.CacheHint{MaxAge: 120}),
+	})
+	postType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Post",
+		Fields: graphql.Fields{
+			"title": &graphql.Field{Type: graphql.String},
+			"votes": &graphql.Field{
+				Type:         graphql.Int,
+				CacheControl: hint(graphql.CacheHint{MaxAge: 30}),
+			},
+			"author": &graphql.Field{Type: authorType},
+			"readByMe": &graphql.Field{
+				Type:         graphql.Boolean,
+				CacheControl: hint(graphql.CacheHint{MaxAge: 240, Scope: graphql.CacheScopePrivate}),
+			},
+		},
+		CacheControl: hint(graphql.CacheHint{MaxAge: 240}),
+	})
+	post := map[string]interface{}{
+		"title":    "hello",
+		"votes":    3,
+		"author":   map[string]interface{}{"name": "jo"},
+		"readByMe": true,
+	}
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"latestPost": &graphql.Field{
+					Type: postType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return post, nil
+					},
+				},
+				"cachedPost": &graphql.Field{
+					Type: postType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						p.Info.CacheControl.SetCacheHint(graphql.CacheHint{MaxAge: 60})
+						return post, nil
+					},
+				},
+				"version": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return "1.0", nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("Error in schema %v", err.Error())
+	}
+	return schema
+}
+
+func TestCacheControl_Policy(t *testing.T) {
+	expectCachePolicies(t, cacheControlTestSchema(t))
+}
+
+func expectCachePolicies(t *testing.T, schema graphql.Schema) {
+	t.Helper()
+	tests := []struct {
+		query    string
+		expected graphql.CachePolicy
+	}{
+		{`{ latestPost { title } }`, graphql.CachePolicy{MaxAge: 240, Scope: graphql.CacheScopePublic}},
+		{`{ latestPost { title votes } }`, graphql.CachePolicy{MaxAge: 30, Scope: graphql.CacheScopePublic}},
+		{`{ latestPost { author { name } } }`, graphql.CachePolicy{MaxAge: 120, Scope: graphql.CacheScopePublic}},
+		{`{ latestPost { readByMe } }`, graphql.CachePolicy{MaxAge: 240, Scope: graphql.CacheScopePrivate}},
+		{`{ cachedPost { title } }`, graphql.CachePolicy{MaxAge: 60, Scope: graphql.CacheScopePublic}},
+		// root fields without hints get the default max age
+		{`{ version }`, graphql.CachePolicy{MaxAge: 0, Scope: graphql.CacheScopePublic}},
+		{`{ latestPost { title } version }`, graphql.CachePolicy{MaxAge: 0, Scope: graphql.CacheScopePublic}},
+	}
+	for _, test := range tests {
+		result := graphql.Do(graphql.Params{
+			Schema:        schema,
+			RequestString: test.query,
+			CacheControl:  &graphql.CacheControlConfig{},
+		})
+		if len(result.Errors) > 0 {
+			t.Fatalf("%s: %v", test.query, result.Errors)
+		}
+		policy, ok := result.CachePolicy()
+		if !ok {
+			t.Fatalf("%s: expected a cache policy", test.query)
+		}
+		if !reflect.DeepEqual(test.expected, policy) {
+			t.Fatalf("%s: expected %+v, got %+v", test.query, test.expected, policy)
+		}
+		if !reflect.DeepEqual(result.Extensions["cacheControl"], policy) {
+			t.Fatalf("%s: expected the policy in extensions, got %v", test.query, result.Extensions)
+		}
+	}
+}
+
+func TestCacheControl_DefaultMaxAge(t *testing.T) {
+	result := graphql.Do(graphql.Params{
+		Schema:        cacheControlTestSchema(t),
+		RequestString: `{ version }`,
+		CacheControl:  &graphql.CacheControlConfig{DefaultMaxAge: 10},
+	})
+	policy, _ := result.CachePolicy()
+	if policy.MaxAge != 10 {
+		t.Fatalf("expected the default max age, got %+v", policy)
+	}
+}
+
+func TestCacheControl_DisabledByDefault(t *testing.T) {
+	result := graphql.Do(graphql.Params{
+		Schema:        cacheControlTestSchema(t),
+		RequestString: `{ cachedPost { title } }`,
+	})
+	if len(result.Errors) > 0 {
+		t.Fatal(result.Errors)
+	}
+	if _, ok := result.CachePolicy(); ok {
+		t.Fatal("expected no cache policy")
+	}
+	if result.Extensions != nil {
+		t.Fatalf("expected no extensions, got %v", result.Extensions)
+	}
+}
+
+func TestCacheControl_Directives(t *testing.T) {
+	schema := cacheControlSchema(t, false)
+	doc := testutil.TestParse(t, `
+type Author @cacheControl(maxAge: 120) {
+  name: String
+}
+
+type Post @cacheControl(maxAge: 240) {
+  title: String
+  votes: Int @cacheControl(maxAge: 30)
+  author: Author
+  readByMe: Boolean @cacheControl(maxAge: 240, scope: PRIVATE)
+}
+
+type Query {
+  latestPost: Post
+}
+`)
+	if err := graphql.ApplyCacheControlDirectives(&schema, doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectCachePolicies(t, schema)
+}
+
+func TestCacheControl_InheritMaxAge(t *testing.T) {
+	schema := cacheControlSchema(t, false)
+	doc := testutil.TestParse(t, `
+type Post @cacheControl(maxAge: 240) {
+  author: Author @cacheControl(inheritMaxAge: true)
+  readByMe: Boolean @cacheControl(inheritMaxAge: true, scope: PRIVATE)
+}
+extend type Query {
+  latestPost: Post @cacheControl(maxAge: 90)
+  version: String @cacheControl(inheritMaxAge: true)
+}
+`)
+	if err := graphql.ApplyCacheControlDirectives(&schema, doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		query    string
+		expected graphql.CachePolicy
+	}{
+		// Author has no hint: without inheritMaxAge it would get 0
+		{`{ latestPost { author { name } } }`, graphql.CachePolicy{MaxAge: 90, Scope: graphql.CacheScopePublic}},
+		{`{ latestPost { readByMe } }`, graphql.CachePolicy{MaxAge: 90, Scope: graphql.CacheScopePrivate}},
+		// root fields have nothing to inherit from
+		{`{ version }`, graphql.CachePolicy{MaxAge: 10, Scope: graphql.CacheScopePublic}},
+	}
+	for _, test := range tests {
+		result := graphql.Do(graphql.Params{
+			Schema:        schema,
+			RequestString: test.query,
+			CacheControl:  &graphql.CacheControlConfig{DefaultMaxAge: 10},
+		})
+		if len(result.Errors) > 0 {
+			t.Fatalf("%s: %v", test.query, result.Errors)
+		}
+		if policy, _ := result.CachePolicy(); !reflect.DeepEqual(test.expected, policy) {
+			t.Fatalf("%s: expected %+v, got %+v", test.query, test.expected, policy)
+		}
+	}
+}
+
+func TestCacheControl_DirectiveErrors(t *testing.T) {
+	tests := []struct {
+		sdl      string
+		expected string
+	}{
+		{`type Nope @cacheControl(maxAge: 1) { a: Int }`, `cacheControl: "Nope" is not an object, interface or union type of the schema`},
+		{`type Post { nope: Int @cacheControl(maxAge: 1) }`, `cacheControl: type "Post" has no field "nope"`},
+		{`type Post @cacheControl(maxAge: "1") { title: String }`, `cacheControl: type "Post": argument "maxAge" of @cacheControl: Expected type "Int", found "1".`},
+		{`type Post @cacheControl(ttl: 1) { title: String }`, `cacheControl: type "Post": unknown argument "ttl" of @cacheControl`},
+		{`type Post { title: String @cacheControl(maxAge: 1) @cacheControl(maxAge: 2) }`, `cacheControl: field Post.title: @cacheControl is used more than once`},
+	}
+	for _, test := range tests {
+		schema := cacheControlSchema(t, false)
+		err := graphql.ApplyCacheControlDirectives(&schema, testutil.TestParse(t, test.sdl))
+		if err == nil || err.Error() != test.expected {
+			t.Fatalf("%s: expected error %q, got %v", test.sdl, test.expected, err)
+		}
+	}
+}
+
+func TestCacheControl_DirectiveInSchema(t *testing.T) {
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name:   "Query",
+			Fields: graphql.Fields{"a": &graphql.Field{Type: graphql.String}},
+		}),
+		Types:      []graphql.Type{graphql.CacheControlScopeEnumType},
+		Directives: append(append([]*graphql.Directive{}, graphql.SpecifiedDirectives...), graphql.CacheControlDirective),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ __schema { directives { name args { name type { name } } } } }`,
+	})
+	if len(result.Errors) > 0 {
+		t.Fatal(result.Errors)
+	}
+	directives := result.Data.(map[string]interface{})["__schema"].(map[string]interface{})["directives"].([]interface{})
+	for _, d := range directives {
+		if d.(map[string]interface{})["name"] == "cacheControl" {
+			return
+		}
+	}
+	t.Fatalf("expected @cacheControl to be introspectable, got %v", directives)
+}
+
+func TestCacheControl_AbstractTypeExtensionDirectives(t *testing.T) {
+	node := graphql.NewInterface(graphql.InterfaceConfig{
+		Name:   "Node",
+		Fields: graphql.Fields{"id": &graphql.Field{Type: graphql.ID}},
+	})
+	user := graphql.NewObject(graphql.ObjectConfig{
+		Name:       "User",
+		Interfaces: []*graphql.Interface{node},
+		Fields:     graphql.Fields{"id": &graphql.Field{Type: graphql.ID}},
+		IsTypeOf:   func(p graphql.IsTypeOfParams) bool { return true },
+	})
+	result := graphql.NewUnion(graphql.UnionConfig{
+		Name:        "SearchResult",
+		Types:       []*graphql.Object{user},
+		ResolveType: func(p graphql.ResolveTypeParams) *graphql.Object { return user },
+	})
+	resolve := func(p graphql.ResolveParams) (interface{}, error) {
+		return map[string]interface{}{"id": "1"}, nil
+	}
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"node":   &graphql.Field{Type: node, Resolve: resolve},
+				"search": &graphql.Field{Type: result, Resolve: resolve},
+			},
+		}),
+		Types: []graphql.Type{user},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	doc := testutil.TestParse(t, `
+extend interface Node @cacheControl(maxAge: 30)
+extend union SearchResult @cacheControl(maxAge: 20, scope: PRIVATE)
+`)
+	if err := graphql.ApplyCacheControlDirectives(&schema, doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		query    string
+		expected graphql.CachePolicy
+	}{
+		{`{ node { id } }`, graphql.CachePolicy{MaxAge: 30, Scope: graphql.CacheScopePublic}},
+		{`{ search { ... on User { id } } }`, graphql.CachePolicy{MaxAge: 20, Scope: graphql.CacheScopePrivate}},
+	}
+	for _, test := range tests {
+		result := graphql.Do(graphql.Params{
+			Schema:        schema,
+			RequestString: test.query,
+			CacheControl:  &graphql.CacheControlConfig{DefaultMaxAge: 60},
+		})
+		if len(result.Errors) > 0 {
+			t.Fatalf("%s: %v", test.query, result.Errors)
+		}
+		if policy, _ := result.CachePolicy(); !reflect.DeepEqual(test.expected, policy) {
+			t.Fatalf("%s: expected %+v, got %+v", test.query, test.expected, policy)
+		}
+	}
+
+	// extensions naming fields the interface lacks are errors too
+	doc = testutil.TestParse(t, `extend interface Node { name: String @cacheControl(maxAge: 1) }`)
+	if err := graphql.ApplyCacheControlDirectives(&schema, doc); err == nil || err.Error() != `cacheControl: type "Node" has no field "name"` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -373,6 +373,9 @@ type ObjectConfig struct {
 	Fields      interface{} `json:"fields"`
 	IsTypeOf    IsTypeOfFn  `json:"isTypeOf"`
 	Description string      `json:"description"`
+
+	// CacheControl is the cache hint for fields returning this type.
+	CacheControl *CacheHint `json:"-"`
 }
 
 type FieldsThunk func() Fields
@@ -532,6 +535,7 @@ func defineFieldMap(ttype Named, fieldMap Fields) (FieldDefinitionMap, error) {
 			Resolve:           field.Resolve,
 			Subscribe:         field.Subscribe,
 			DeprecationReason: field.DeprecationReason,
+			CacheControl:      field.CacheControl,
 		}
 
 		fieldDef.Args = []*Argument{}
@@ -594,6 +598,11 @@ type ResolveInfo struct {
 	RootValue      interface{}
 	Operation      ast.Definition
 	VariableValues map[string]interface{}
+
+	// CacheControl lets the resolver set a dynamic cache hint for the
+	// field. It is nil unless cache control is enabled for the request;
+	// its methods are safe to call on nil.
+	CacheControl *FieldCacheControl
 }
 
 type Fields map[string]*Field
@@ -606,6 +615,7 @@ type Field struct {
 	Subscribe         FieldResolveFn      `json:"-"`
 	DeprecationReason string              `json:"deprecationReason"`
 	Description       string              `json:"description"`
+	CacheControl      *CacheHint          `json:"-"`
 }
 
 type FieldConfigArgument map[string]*ArgumentConfig
@@ -625,6 +635,7 @@ type FieldDefinition struct {
 	Resolve           FieldResolveFn `json:"-"`
 	Subscribe         FieldResolveFn `json:"-"`
 	DeprecationReason string         `json:"deprecationReason"`
+	CacheControl      *CacheHint     `json:"-"`
 }
 
 type FieldArgument struct {
@@ -685,6 +696,9 @@ type InterfaceConfig struct {
 	Fields      interface{} `json:"fields"`
 	ResolveType ResolveTypeFn
 	Description string `json:"description"`
+
+	// CacheControl is the cache hint for fields returning this type.
+	CacheControl *CacheHint `json:"-"`
 }
 
 // ResolveTypeParams Params for ResolveTypeFn()
@@ -805,6 +819,9 @@ type UnionConfig struct {
 	Types       interface{} `json:"types"`
 	ResolveType ResolveTypeFn
 	Description string `json:"description"`
+
+	// CacheControl is the cache hint for fields returning this type.
+	CacheControl *CacheHint `json:"-"`
 }
 
 func NewUnion(config UnionConfig) *Union {
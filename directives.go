@@ -9,6 +9,7 @@ const (
 	DirectiveLocationFragmentDefinition = "FRAGMENT_DEFINITION"
 	DirectiveLocationFragmentSpread     = "FRAGMENT_SPREAD"
 	DirectiveLocationInlineFragment     = "INLINE_FRAGMENT"
+	DirectiveLocationVariableDefinition = "VARIABLE_DEFINITION"
 
 	// Schema Definitions
 	DirectiveLocationSchema               = "SCHEMA"
@@ -0,0 +1,56 @@
+package graphql
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// ErrorPresenterFn maps an error produced while handling a request to the
+// error that is sent to the client. The error the executor built is passed
+// in as-is; the resolver's own error is reachable through
+// FormattedError.OriginalError. Use it to replace internal details (SQL
+// errors, file paths, ...) with client-safe messages.
+type ErrorPresenterFn func(ctx context.Context, err gqlerrors.FormattedError) gqlerrors.FormattedError
+
+// PanicHandlerFn is called for every panic recovered while executing a
+// request, with the recovered value and the stack trace of the goroutine
+// that panicked. It is meant for logging; the panic is still reported to
+// the client as a field error, which ErrorPresenterFn can mask.
+type PanicHandlerFn func(ctx context.Context, recovered interface{}, stack []byte)
+
+// PresentErrors runs every error through presenter. A nil presenter leaves
+// errs untouched. Results are presented by Do and the executor; servers
+// use it for the errors they raise themselves, such as malformed requests.
+func PresentErrors(ctx context.Context, presenter ErrorPresenterFn, errs []gqlerrors.FormattedError) []gqlerrors.FormattedError {
+	if presenter == nil || len(errs) == 0 {
+		return errs
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	presented := make([]gqlerrors.FormattedError, 0, len(errs))
+	for _, err := range errs {
+		presented = append(presented, presenter(ctx, err))
+	}
+	return presented
+}
+
+// reportPanic hands a recovered value to the execution's PanicHandler.
+// The executor itself unwinds non-null violations and completion errors by
+// panicking with *gqlerrors.Error / gqlerrors.FormattedError; those are not
+// panics from user code and are not reported.
+//
+// It must be called from the deferred function that recovered r so that
+// the stack trace still includes the frames that panicked.
+func reportPanic(eCtx *executionContext, r interface{}) {
+	if eCtx == nil || eCtx.PanicHandler == nil {
+		return
+	}
+	switch r.(type) {
+	case *gqlerrors.Error, gqlerrors.FormattedError:
+		return
+	}
+	eCtx.PanicHandler(eCtx.Context, r, debug.Stack())
+}
@@ -0,0 +1,186 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/testutil"
+)
+
+var errSQL = errors.New(`pq: relation "users" does not exist`)
+
+func presenterTestSchema(t *testing.T) graphql.Schema {
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"ok": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return "ok", nil
+					},
+				},
+				"sql": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return nil, errSQL
+					},
+				},
+				"panics": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						panic("nil map write in users.go:42")
+					},
+				},
+				"nonNull": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.String),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return nil, nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("Error in schema %v", err.Error())
+	}
+	return schema
+}
+
+func maskInternalErrors(ctx context.Context, err gqlerrors.FormattedError) gqlerrors.FormattedError {
+	var located *gqlerrors.Error
+	if !errors.As(err.OriginalError(), &located) || located.OriginalError == nil {
+		return err
+	}
+	if !errors.Is(located.OriginalError, errSQL) {
+		return err
+	}
+	err.Message = "internal server error"
+	return err
+}
+
+func TestErrorPresenter_MasksResolverErrors(t *testing.T) {
+	result := graphql.Do(graphql.Params{
+		Schema:         presenterTestSchema(t),
+		RequestString:  `{ ok sql }`,
+		ErrorPresenter: maskInternalErrors,
+	})
+	expectedData := map[string]interface{}{"ok": "ok", "sql": nil}
+	if !reflect.DeepEqual(expectedData, result.Data) {
+		t.Fatalf("Unexpected data, Diff: %v", testutil.Diff(expectedData, result.Data))
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("expected one error, got %v", result.Errors)
+	}
+	err := result.Errors[0]
+	if err.Message != "internal server error" {
+		t.Fatalf("expected masked message, got %q", err.Message)
+	}
+	if !reflect.DeepEqual(err.Path, []interface{}{"sql"}) {
+		t.Fatalf("expected path to be kept, got %v", err.Path)
+	}
+	if len(err.Locations) != 1 {
+		t.Fatalf("expected locations to be kept, got %v", err.Locations)
+	}
+}
+
+func TestErrorPresenter_SeesParseAndValidationErrors(t *testing.T) {
+	var seen []string
+	presenter := func(ctx context.Context, err gqlerrors.FormattedError) gqlerrors.FormattedError {
+		seen = append(seen, err.Message)
+		return err
+	}
+	schema := presenterTestSchema(t)
+	graphql.Do(graphql.Params{Schema: schema, RequestString: `{ ok `, ErrorPresenter: presenter})
+	graphql.Do(graphql.Params{Schema: schema, RequestString: `{ unknown }`, ErrorPresenter: presenter})
+	if len(seen) != 2 {
+		t.Fatalf("expected the presenter to see 2 errors, got %v", seen)
+	}
+	if !strings.HasPrefix(seen[0], "Syntax Error") {
+		t.Fatalf("expected a syntax error, got %q", seen[0])
+	}
+	if !strings.Contains(seen[1], `Cannot query field "unknown"`) {
+		t.Fatalf("expected a validation error, got %q", seen[1])
+	}
+}
+
+func TestErrorPresenter_NoErrorsNoCalls(t *testing.T) {
+	called := false
+	result := graphql.Do(graphql.Params{
+		Schema:        presenterTestSchema(t),
+		RequestString: `{ ok }`,
+		ErrorPresenter: func(ctx context.Context, err gqlerrors.FormattedError) gqlerrors.FormattedError {
+			called = true
+			return err
+		},
+	})
+	if called {
+		t.Fatal("presenter must not be called without errors")
+	}
+	if result.Errors != nil {
+		t.Fatalf("expected nil errors, got %v", result.Errors)
+	}
+}
+
+func TestPanicHandler_ReceivesStackTrace(t *testing.T) {
+	var (
+		recovered interface{}
+		stack     string
+		calls     int
+	)
+	result := graphql.Do(graphql.Params{
+		Schema:        presenterTestSchema(t),
+		RequestString: `{ ok panics }`,
+		PanicHandler: func(ctx context.Context, r interface{}, s []byte) {
+			calls++
+			recovered = r
+			stack = string(s)
+		},
+		ErrorPresenter: func(ctx context.Context, err gqlerrors.FormattedError) gqlerrors.FormattedError {
+			if reflect.DeepEqual(err.Path, []interface{}{"panics"}) {
+				err.Message = "internal server error"
+			}
+			return err
+		},
+	})
+	if calls != 1 {
+		t.Fatalf("expected the panic handler to be called once, got %d", calls)
+	}
+	if recovered != "nil map write in users.go:42" {
+		t.Fatalf("unexpected recovered value %v", recovered)
+	}
+	if !strings.Contains(stack, "presenterTestSchema") {
+		t.Fatalf("expected stack trace to include the panicking resolver, got:\n%s", stack)
+	}
+	for _, err := range result.Errors {
+		if strings.Contains(err.Message, "users.go") {
+			t.Fatalf("panic details leaked to the client: %q", err.Message)
+		}
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Message != "internal server error" {
+		t.Fatalf("unexpected errors %v", result.Errors)
+	}
+}
+
+func TestPanicHandler_IgnoresNonNullViolations(t *testing.T) {
+	calls := 0
+	result := graphql.Do(graphql.Params{
+		Schema:        presenterTestSchema(t),
+		RequestString: `{ nonNull }`,
+		PanicHandler: func(ctx context.Context, r interface{}, s []byte) {
+			calls++
+		},
+	})
+	// The non-null violation is raised by the executor, not by user code.
+	if calls != 0 {
+		t.Fatalf("expected the panic handler not to be called, got %d calls", calls)
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("expected one error, got %v", result.Errors)
+	}
+}
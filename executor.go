@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/graphql-go/graphql/gqlerrors"
 	"github.com/graphql-go/graphql/language/ast"
@@ -21,6 +22,26 @@ type ExecuteParams struct {
 	// Context may be provided to pass application-specific per-request
 	// information to resolve functions.
 	Context context.Context
+
+	// ErrorPresenter, when set, maps every error in the result to the
+	// error sent to the client.
+	ErrorPresenter ErrorPresenterFn
+
+	// PanicHandler, when set, is called with the stack trace of every
+	// panic recovered during execution.
+	PanicHandler PanicHandlerFn
+
+	// CacheControl, when set, enables cache hint computation; the
+	// response's policy is then available from Result.CachePolicy.
+	CacheControl *CacheControlConfig
+
+	// Extensions run for this request after the schema's extensions; one
+	// named like a schema extension replaces it.
+	Extensions []Extension
+
+	// SkipExtensions names the schema extensions not to run for this
+	// request.
+	SkipExtensions []string
 }
 
 // Execute runs an operation against a schema. Behavior is unchanged
@@ -30,10 +51,20 @@ type ExecuteParams struct {
 // returned by PlanQuery and pass it to ExecutePlan to skip the
 // per-call planning work.
 func Execute(p ExecuteParams) (result *Result) {
+	start := time.Now()
+	_, span := startSpan(p.Schema.tracer, p.Context, SpanPlan, SpanAttribute{AttributeOperationName, p.OperationName})
 	plan, err := PlanQuery(&p.Schema, p.AST, p.OperationName)
 	if err != nil {
-		return &Result{Errors: gqlerrors.FormatErrors(err)}
+		span.RecordError(err)
+		span.End()
+		result = &Result{Errors: PresentErrors(p.Context, p.ErrorPresenter, gqlerrors.FormatErrors(err))}
+		if metrics := p.Schema.metrics; metrics != nil && claimOperationReport(p.Context) == nil {
+			reportOperation(metrics, p.OperationName, "", start, result.Errors, "")
+		}
+		return result
 	}
+	span.SetAttributes(SpanAttribute{AttributeOperationType, plan.OperationType()})
+	span.End()
 	return ExecutePlan(plan, p)
 }
 
@@ -54,11 +85,19 @@ type executionContext struct {
 	Operation      ast.Definition
 	VariableValues map[string]interface{}
 	Errors         []gqlerrors.FormattedError
+	Warnings       []gqlerrors.FormattedError
 	Context        context.Context
+	PanicHandler   PanicHandlerFn
+
+	// cacheControl accumulates field cache hints; nil unless enabled.
+	cacheControl *cachePolicyAccumulator
 
 	// plan is set on the ExecutePlan path; it lets abstract fields plan
 	// their concrete-type sub-selections lazily at execute time.
 	plan *Plan
+
+	// extensions are the extensions of the request.
+	extensions []Extension
 }
 
 func buildExecutionContext(p buildExecutionCtxParams) (*executionContext, error) {
@@ -431,6 +470,51 @@ func handleFieldError(r interface{}, fieldNodes []ast.Node, path *ResponsePath,
 	eCtx.Errors = append(eCtx.Errors, gqlerrors.FormatError(err))
 }
 
+// handleResolverError records the error a resolver (or a thunk it
+// returned) produced for a field. Multi-errors are expanded into one located
+// error each and warnings are set aside for the result's extensions. It
+// reports whether the resolved value should still be completed: that is
+// the case when err carried only warnings, or when a multi-error came with
+// a non-nil value — the resolver returned partial data.
+func handleResolverError(err error, result interface{}, fieldNodes []ast.Node, path *ResponsePath, returnType Output, eCtx *executionContext) bool {
+	_, isMulti := err.(interface{ Unwrap() []error })
+	var errs []error
+	for _, e := range gqlerrors.Flatten(err) {
+		if gqlerrors.IsWarning(e) {
+			warning := NewLocatedErrorWithPath(e, fieldNodes, path.AsArray())
+			eCtx.Warnings = append(eCtx.Warnings, gqlerrors.FormatError(warning))
+			continue
+		}
+		errs = append(errs, e)
+	}
+	if len(errs) == 0 {
+		return true
+	}
+	if isMulti && !isNullish(result) {
+		for _, e := range errs {
+			located := NewLocatedErrorWithPath(e, fieldNodes, path.AsArray())
+			eCtx.Errors = append(eCtx.Errors, gqlerrors.FormatError(located))
+		}
+		return true
+	}
+	for _, e := range errs[:len(errs)-1] {
+		located := NewLocatedErrorWithPath(e, fieldNodes, path.AsArray())
+		eCtx.Errors = append(eCtx.Errors, gqlerrors.FormatError(located))
+	}
+	// the last error nulls the field, propagating to the parent for non-null
+	// fields like any other field error
+	handleFieldError(errs[len(errs)-1], fieldNodes, path, returnType, eCtx)
+	return false
+}
+
+// handleFieldPanic is handleFieldError for values recovered from a panic:
+// genuine panics are reported to the PanicHandler before being turned into
+// a field error.
+func handleFieldPanic(r interface{}, fieldNodes []ast.Node, path *ResponsePath, returnType Output, eCtx *executionContext) {
+	reportPanic(eCtx, r)
+	handleFieldError(r, fieldNodes, path, returnType, eCtx)
+}
+
 // completeLeafValue complete a leaf value (Scalar / Enum) by serializing to a valid value, returning nil if serialization is not possible.
 func completeLeafValue(returnType Leaf, result interface{}) interface{} {
 	serializedResult := returnType.Serialize(result)
@@ -27,9 +27,21 @@ type (
 	ResolveFieldFinishFunc func(interface{}, error)
 	// resolveFieldFinishFuncHandler calls the resolveFieldFinishFns for all the extensions
 	resolveFieldFinishFuncHandler func(interface{}, error) []gqlerrors.FormattedError
+
+	// SubscriptionEventFunc is called with the result of each subscription event, before it is sent
+	SubscriptionEventFunc func(*Result)
+	// subscriptionEventFuncHandler calls the SubscriptionEventFuncs for all the extensions
+	subscriptionEventFuncHandler func(*Result) []gqlerrors.FormattedError
+
+	// SubscriptionFinishFunc is called when the subscription terminates, with the error that ended it if any
+	SubscriptionFinishFunc func(error)
+	// subscriptionFinishFuncHandler calls the SubscriptionFinishFuncs for all the extensions
+	subscriptionFinishFuncHandler func(error) []gqlerrors.FormattedError
 )
 
-// Extension is an interface for extensions in graphql
+// Extension is an interface for extensions in graphql. An extension serves
+// every request it runs for, possibly at once: state of a request belongs
+// in the context its hooks return, not in the extension.
 type Extension interface {
 	// Init is used to help you initialize the extension
 	Init(context.Context, *Params) context.Context
@@ -56,15 +68,50 @@ type Extension interface {
 	GetResult(context.Context) interface{}
 }
 
-// handleExtensionsInits handles all the init functions for all the extensions in the schema
-func handleExtensionsInits(p *Params) gqlerrors.FormattedErrors {
+// SubscriptionExtension is implemented by extensions that want to follow
+// the lifecycle of subscriptions, on top of the Extension hooks that run for
+// the subscription setup and for each event's execution.
+type SubscriptionExtension interface {
+	Extension
+
+	// SubscriptionDidStart is called once the subscription's source stream
+	// is set up. The returned functions are called for every event and
+	// when the subscription terminates.
+	SubscriptionDidStart(context.Context) (context.Context, SubscriptionEventFunc, SubscriptionFinishFunc)
+}
+
+// requestExtensions returns the extensions of a request: the schema's,
+// except those named in skip or replaced by an extension of the same name
+// in extra, then extra.
+func requestExtensions(schema *Schema, extra []Extension, skip []string) []Extension {
+	if len(extra) == 0 && len(skip) == 0 {
+		return schema.extensions
+	}
+	names := make(map[string]bool, len(extra)+len(skip))
+	for _, name := range skip {
+		names[name] = true
+	}
+	for _, ext := range extra {
+		names[ext.Name()] = true
+	}
+	exts := make([]Extension, 0, len(schema.extensions)+len(extra))
+	for _, ext := range schema.extensions {
+		if !names[ext.Name()] {
+			exts = append(exts, ext)
+		}
+	}
+	return append(exts, extra...)
+}
+
+// handleExtensionsInits handles all the init functions for all the extensions of the request
+func handleExtensionsInits(p *Params, exts []Extension) gqlerrors.FormattedErrors {
 	errs := gqlerrors.FormattedErrors{}
-	for _, ext := range p.Schema.extensions {
+	for _, ext := range exts {
 		func() {
 			// catch panic from an extension init fn
 			defer func() {
 				if r := recover(); r != nil {
-					errs = append(errs, gqlerrors.FormatError(fmt.Errorf("%s.Init: %v", ext.Name(), r.(error))))
+					errs = append(errs, gqlerrors.FormatError(fmt.Errorf("%s.Init: %v", ext.Name(), r)))
 				}
 			}()
 			// update context
@@ -75,10 +122,11 @@ func handleExtensionsInits(p *Params) gqlerrors.FormattedErrors {
 }
 
 // handleExtensionsParseDidStart runs the ParseDidStart functions for each extension
-func handleExtensionsParseDidStart(p *Params) ([]gqlerrors.FormattedError, parseFinishFuncHandler) {
-	fs := map[string]ParseFinishFunc{}
+func handleExtensionsParseDidStart(p *Params, exts []Extension) ([]gqlerrors.FormattedError, parseFinishFuncHandler) {
+	fs := make([]ParseFinishFunc, 0, len(exts))
+	names := make([]string, 0, len(exts))
 	errs := gqlerrors.FormattedErrors{}
-	for _, ext := range p.Schema.extensions {
+	for _, ext := range exts {
 		var (
 			ctx      context.Context
 			finishFn ParseFinishFunc
@@ -87,23 +135,25 @@ func handleExtensionsParseDidStart(p *Params) ([]gqlerrors.FormattedError, parse
 		func() {
 			defer func() {
 				if r := recover(); r != nil {
-					errs = append(errs, gqlerrors.FormatError(fmt.Errorf("%s.ParseDidStart: %v", ext.Name(), r.(error))))
+					errs = append(errs, gqlerrors.FormatError(fmt.Errorf("%s.ParseDidStart: %v", ext.Name(), r)))
 				}
 			}()
 			ctx, finishFn = ext.ParseDidStart(p.Context)
 			// update context
 			p.Context = ctx
-			fs[ext.Name()] = finishFn
+			fs = append(fs, finishFn)
+			names = append(names, ext.Name())
 		}()
 	}
 	return errs, func(err error) []gqlerrors.FormattedError {
 		errs := gqlerrors.FormattedErrors{}
-		for name, fn := range fs {
+		for i, fn := range fs {
+			name := names[i]
 			func() {
 				// catch panic from a finishFn
 				defer func() {
 					if r := recover(); r != nil {
-						errs = append(errs, gqlerrors.FormatError(fmt.Errorf("%s.ParseFinishFunc: %v", name, r.(error))))
+						errs = append(errs, gqlerrors.FormatError(fmt.Errorf("%s.ParseFinishFunc: %v", name, r)))
 					}
 				}()
 				fn(err)
@@ -114,10 +164,11 @@ func handleExtensionsParseDidStart(p *Params) ([]gqlerrors.FormattedError, parse
 }
 
 // handleExtensionsValidationDidStart notifies the extensions about the start of the validation process
-func handleExtensionsValidationDidStart(p *Params) ([]gqlerrors.FormattedError, validationFinishFuncHandler) {
-	fs := map[string]ValidationFinishFunc{}
+func handleExtensionsValidationDidStart(p *Params, exts []Extension) ([]gqlerrors.FormattedError, validationFinishFuncHandler) {
+	fs := make([]ValidationFinishFunc, 0, len(exts))
+	names := make([]string, 0, len(exts))
 	errs := gqlerrors.FormattedErrors{}
-	for _, ext := range p.Schema.extensions {
+	for _, ext := range exts {
 		var (
 			ctx      context.Context
 			finishFn ValidationFinishFunc
@@ -126,23 +177,25 @@ func handleExtensionsValidationDidStart(p *Params) ([]gqlerrors.FormattedError,
 		func() {
 			defer func() {
 				if r := recover(); r != nil {
-					errs = append(errs, gqlerrors.FormatError(fmt.Errorf("%s.ValidationDidStart: %v", ext.Name(), r.(error))))
+					errs = append(errs, gqlerrors.FormatError(fmt.Errorf("%s.ValidationDidStart: %v", ext.Name(), r)))
 				}
 			}()
 			ctx, finishFn = ext.ValidationDidStart(p.Context)
 			// update context
 			p.Context = ctx
-			fs[ext.Name()] = finishFn
+			fs = append(fs, finishFn)
+			names = append(names, ext.Name())
 		}()
 	}
 	return errs, func(errs []gqlerrors.FormattedError) []gqlerrors.FormattedError {
 		extErrs := gqlerrors.FormattedErrors{}
-		for name, finishFn := range fs {
+		for i, finishFn := range fs {
+			name := names[i]
 			func() {
 				// catch panic from a finishFn
 				defer func() {
 					if r := recover(); r != nil {
-						extErrs = append(extErrs, gqlerrors.FormatError(fmt.Errorf("%s.ValidationFinishFunc: %v", name, r.(error))))
+						extErrs = append(extErrs, gqlerrors.FormatError(fmt.Errorf("%s.ValidationFinishFunc: %v", name, r)))
 					}
 				}()
 				finishFn(errs)
@@ -153,10 +206,11 @@ func handleExtensionsValidationDidStart(p *Params) ([]gqlerrors.FormattedError,
 }
 
 // handleExecutionDidStart handles the ExecutionDidStart functions
-func handleExtensionsExecutionDidStart(p *ExecuteParams) ([]gqlerrors.FormattedError, executionFinishFuncHandler) {
-	fs := map[string]ExecutionFinishFunc{}
+func handleExtensionsExecutionDidStart(p *ExecuteParams, exts []Extension) ([]gqlerrors.FormattedError, executionFinishFuncHandler) {
+	fs := make([]ExecutionFinishFunc, 0, len(exts))
+	names := make([]string, 0, len(exts))
 	errs := gqlerrors.FormattedErrors{}
-	for _, ext := range p.Schema.extensions {
+	for _, ext := range exts {
 		var (
 			ctx      context.Context
 			finishFn ExecutionFinishFunc
@@ -165,23 +219,25 @@ func handleExtensionsExecutionDidStart(p *ExecuteParams) ([]gqlerrors.FormattedE
 		func() {
 			defer func() {
 				if r := recover(); r != nil {
-					errs = append(errs, gqlerrors.FormatError(fmt.Errorf("%s.ExecutionDidStart: %v", ext.Name(), r.(error))))
+					errs = append(errs, gqlerrors.FormatError(fmt.Errorf("%s.ExecutionDidStart: %v", ext.Name(), r)))
 				}
 			}()
 			ctx, finishFn = ext.ExecutionDidStart(p.Context)
 			// update context
 			p.Context = ctx
-			fs[ext.Name()] = finishFn
+			fs = append(fs, finishFn)
+			names = append(names, ext.Name())
 		}()
 	}
 	return errs, func(result *Result) []gqlerrors.FormattedError {
 		extErrs := gqlerrors.FormattedErrors{}
-		for name, finishFn := range fs {
+		for i, finishFn := range fs {
+			name := names[i]
 			func() {
 				// catch panic from a finishFn
 				defer func() {
 					if r := recover(); r != nil {
-						extErrs = append(extErrs, gqlerrors.FormatError(fmt.Errorf("%s.ExecutionFinishFunc: %v", name, r.(error))))
+						extErrs = append(extErrs, gqlerrors.FormatError(fmt.Errorf("%s.ExecutionFinishFunc: %v", name, r)))
 					}
 				}()
 				finishFn(result)
@@ -193,9 +249,10 @@ func handleExtensionsExecutionDidStart(p *ExecuteParams) ([]gqlerrors.FormattedE
 
 // handleResolveFieldDidStart handles the notification of the extensions about the start of a resolve function
 func handleExtensionsResolveFieldDidStart(exts []Extension, p *executionContext, i *ResolveInfo) ([]gqlerrors.FormattedError, resolveFieldFinishFuncHandler) {
-	fs := map[string]ResolveFieldFinishFunc{}
+	fs := make([]ResolveFieldFinishFunc, 0, len(exts))
+	names := make([]string, 0, len(exts))
 	errs := gqlerrors.FormattedErrors{}
-	for _, ext := range p.Schema.extensions {
+	for _, ext := range exts {
 		var (
 			ctx      context.Context
 			finishFn ResolveFieldFinishFunc
@@ -204,23 +261,25 @@ func handleExtensionsResolveFieldDidStart(exts []Extension, p *executionContext,
 		func() {
 			defer func() {
 				if r := recover(); r != nil {
-					errs = append(errs, gqlerrors.FormatError(fmt.Errorf("%s.ResolveFieldDidStart: %v", ext.Name(), r.(error))))
+					errs = append(errs, gqlerrors.FormatError(fmt.Errorf("%s.ResolveFieldDidStart: %v", ext.Name(), r)))
 				}
 			}()
 			ctx, finishFn = ext.ResolveFieldDidStart(p.Context, i)
 			// update context
 			p.Context = ctx
-			fs[ext.Name()] = finishFn
+			fs = append(fs, finishFn)
+			names = append(names, ext.Name())
 		}()
 	}
 	return errs, func(val interface{}, err error) []gqlerrors.FormattedError {
 		extErrs := gqlerrors.FormattedErrors{}
-		for name, finishFn := range fs {
+		for i, finishFn := range fs {
+			name := names[i]
 			func() {
 				// catch panic from a finishFn
 				defer func() {
 					if r := recover(); r != nil {
-						extErrs = append(extErrs, gqlerrors.FormatError(fmt.Errorf("%s.ResolveFieldFinishFunc: %v", name, r.(error))))
+						extErrs = append(extErrs, gqlerrors.FormatError(fmt.Errorf("%s.ResolveFieldFinishFunc: %v", name, r)))
 					}
 				}()
 				finishFn(val, err)
@@ -230,16 +289,83 @@ func handleExtensionsResolveFieldDidStart(exts []Extension, p *executionContext,
 	}
 }
 
-func addExtensionResults(p *ExecuteParams, result *Result) {
-	if len(p.Schema.extensions) != 0 {
-		for _, ext := range p.Schema.extensions {
+// handleExtensionsSubscriptionDidStart notifies the subscription extensions about the start of a subscription
+func handleExtensionsSubscriptionDidStart(p *ExecuteParams, exts []Extension) ([]gqlerrors.FormattedError, subscriptionEventFuncHandler, subscriptionFinishFuncHandler) {
+	var eventFs []SubscriptionEventFunc
+	var finishFs []SubscriptionFinishFunc
+	var names []string
+	errs := gqlerrors.FormattedErrors{}
+	for _, ext := range exts {
+		subExt, ok := ext.(SubscriptionExtension)
+		if !ok {
+			continue
+		}
+		var (
+			ctx      context.Context
+			eventFn  SubscriptionEventFunc
+			finishFn SubscriptionFinishFunc
+		)
+		// catch panic from an extension's subscriptionDidStart function
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					errs = append(errs, gqlerrors.FormatError(fmt.Errorf("%s.SubscriptionDidStart: %v", ext.Name(), r)))
+				}
+			}()
+			ctx, eventFn, finishFn = subExt.SubscriptionDidStart(p.Context)
+			// update context
+			p.Context = ctx
+			eventFs = append(eventFs, eventFn)
+			finishFs = append(finishFs, finishFn)
+			names = append(names, ext.Name())
+		}()
+	}
+	return errs, func(result *Result) []gqlerrors.FormattedError {
+			extErrs := gqlerrors.FormattedErrors{}
+			for i, eventFn := range eventFs {
+				name := names[i]
+				func() {
+					// catch panic from an eventFn
+					defer func() {
+						if r := recover(); r != nil {
+							extErrs = append(extErrs, gqlerrors.FormatError(fmt.Errorf("%s.SubscriptionEventFunc: %v", name, r)))
+						}
+					}()
+					eventFn(result)
+				}()
+			}
+			return extErrs
+		}, func(err error) []gqlerrors.FormattedError {
+			extErrs := gqlerrors.FormattedErrors{}
+			for i, finishFn := range finishFs {
+				name := names[i]
+				func() {
+					// catch panic from a finishFn
+					defer func() {
+						if r := recover(); r != nil {
+							extErrs = append(extErrs, gqlerrors.FormatError(fmt.Errorf("%s.SubscriptionFinishFunc: %v", name, r)))
+						}
+					}()
+					finishFn(err)
+				}()
+			}
+			return extErrs
+		}
+}
+
+func addExtensionResults(p *ExecuteParams, exts []Extension, result *Result) {
+	if len(exts) != 0 {
+		for _, ext := range exts {
 			func() {
 				defer func() {
 					if r := recover(); r != nil {
-						result.Errors = append(result.Errors, gqlerrors.FormatError(fmt.Errorf("%s.GetResult: %v", ext.Name(), r.(error))))
+						result.Errors = append(result.Errors, gqlerrors.FormatError(fmt.Errorf("%s.GetResult: %v", ext.Name(), r)))
 					}
 				}()
 				if ext.HasResult() {
+					if t, ok := ext.(*TracingExtension); ok && !t.traced(p.Context) {
+						return
+					}
 					if result.Extensions == nil {
 						result.Extensions = make(map[string]interface{})
 					}
@@ -469,3 +469,299 @@ func (t *testExt) ExecutionDidStart(ctx context.Context) (context.Context, graph
 func (t *testExt) ResolveFieldDidStart(ctx context.Context, i *graphql.ResolveInfo) (context.Context, graphql.ResolveFieldFinishFunc) {
 	return t.resolveFieldDidStartFn(ctx, i)
 }
+
+func TestExtensionsRunForSubscriptions(t *testing.T) {
+	var calls []string
+	ext := newtestSubscriptionExt("testExt")
+	ext.initFn = func(ctx context.Context, p *graphql.Params) context.Context {
+		calls = append(calls, "Init")
+		return ctx
+	}
+	ext.parseDidStartFn = func(ctx context.Context) (context.Context, graphql.ParseFinishFunc) {
+		calls = append(calls, "ParseDidStart")
+		return ctx, func(err error) {}
+	}
+	ext.validationDidStartFn = func(ctx context.Context) (context.Context, graphql.ValidationFinishFunc) {
+		calls = append(calls, "ValidationDidStart")
+		return ctx, func([]gqlerrors.FormattedError) {}
+	}
+	ext.executionDidStartFn = func(ctx context.Context) (context.Context, graphql.ExecutionFinishFunc) {
+		calls = append(calls, "ExecutionDidStart")
+		return ctx, func(r *graphql.Result) {}
+	}
+	ext.resolveFieldDidStartFn = func(ctx context.Context, i *graphql.ResolveInfo) (context.Context, graphql.ResolveFieldFinishFunc) {
+		calls = append(calls, "ResolveFieldDidStart:"+i.FieldName)
+		return ctx, func(v interface{}, err error) {}
+	}
+	ext.subscriptionDidStartFn = func(ctx context.Context) (context.Context, graphql.SubscriptionEventFunc, graphql.SubscriptionFinishFunc) {
+		calls = append(calls, "SubscriptionDidStart")
+		return ctx, func(r *graphql.Result) {
+				calls = append(calls, fmt.Sprintf("SubscriptionEvent:%v", r.Data))
+			}, func(err error) {
+				calls = append(calls, fmt.Sprintf("SubscriptionFinish:%v", err))
+			}
+	}
+
+	schema := makeSubscriptionSchema(t, graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"counter": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+				Subscribe: makeSubscribeToStringFunction([]string{"a", "b"}),
+			},
+		},
+	})
+	schema.AddExtensions(ext)
+
+	for range graphql.Subscribe(graphql.Params{
+		Schema:        schema,
+		RequestString: `subscription { counter }`,
+		Context:       context.Background(),
+	}) {
+	}
+
+	expected := []string{
+		"Init",
+		"ParseDidStart",
+		"ValidationDidStart",
+		"SubscriptionDidStart",
+		"ExecutionDidStart",
+		"ResolveFieldDidStart:counter",
+		"SubscriptionEvent:map[counter:a]",
+		"ExecutionDidStart",
+		"ResolveFieldDidStart:counter",
+		"SubscriptionEvent:map[counter:b]",
+		"SubscriptionFinish:<nil>",
+	}
+	if !reflect.DeepEqual(expected, calls) {
+		t.Fatalf("Unexpected hook calls, Diff: %v", testutil.Diff(expected, calls))
+	}
+}
+
+func TestExtensionSubscriptionDidStartPanic(t *testing.T) {
+	ext := newtestSubscriptionExt("testExt")
+	ext.subscriptionDidStartFn = func(ctx context.Context) (context.Context, graphql.SubscriptionEventFunc, graphql.SubscriptionFinishFunc) {
+		if true {
+			panic(errors.New("test error"))
+		}
+		return ctx, nil, nil
+	}
+
+	schema := makeSubscriptionSchema(t, graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"counter": &graphql.Field{
+				Type:      graphql.String,
+				Subscribe: makeSubscribeToStringFunction([]string{"a"}),
+			},
+		},
+	})
+	schema.AddExtensions(ext)
+
+	var results []*graphql.Result
+	for result := range graphql.Subscribe(graphql.Params{
+		Schema:        schema,
+		RequestString: `subscription { counter }`,
+		Context:       context.Background(),
+	}) {
+		results = append(results, result)
+	}
+
+	expected := []*graphql.Result{{
+		Errors: []gqlerrors.FormattedError{
+			gqlerrors.FormatError(fmt.Errorf("%s.SubscriptionDidStart: %v", ext.Name(), errors.New("test error"))),
+		},
+	}}
+	if !reflect.DeepEqual(expected, results) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
+func TestExtensionSubscriptionEventAndFinishFuncPanic(t *testing.T) {
+	ext := newtestSubscriptionExt("testExt")
+	ext.subscriptionDidStartFn = func(ctx context.Context) (context.Context, graphql.SubscriptionEventFunc, graphql.SubscriptionFinishFunc) {
+		return ctx, func(r *graphql.Result) {
+				panic("event panic")
+			}, func(err error) {
+				panic(42)
+			}
+	}
+
+	schema := makeSubscriptionSchema(t, graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"counter": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+				Subscribe: makeSubscribeToStringFunction([]string{"a"}),
+			},
+		},
+	})
+	schema.AddExtensions(ext)
+
+	var results []*graphql.Result
+	for result := range graphql.Subscribe(graphql.Params{
+		Schema:        schema,
+		RequestString: `subscription { counter }`,
+		Context:       context.Background(),
+	}) {
+		results = append(results, result)
+	}
+
+	expected := []*graphql.Result{{
+		Data: map[string]interface{}{"counter": "a"},
+		Errors: []gqlerrors.FormattedError{
+			gqlerrors.FormatError(errors.New("testExt.SubscriptionEventFunc: event panic")),
+		},
+	}, {
+		Errors: []gqlerrors.FormattedError{
+			gqlerrors.FormatError(errors.New("testExt.SubscriptionFinishFunc: 42")),
+		},
+	}}
+	if !reflect.DeepEqual(expected, results) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
+func newtestSubscriptionExt(name string) *testSubscriptionExt {
+	ext := &testSubscriptionExt{
+		testExt: newtestExt(name),
+	}
+	ext.subscriptionDidStartFn = func(ctx context.Context) (context.Context, graphql.SubscriptionEventFunc, graphql.SubscriptionFinishFunc) {
+		return ctx, func(r *graphql.Result) {}, func(err error) {}
+	}
+	return ext
+}
+
+type testSubscriptionExt struct {
+	*testExt
+	subscriptionDidStartFn func(ctx context.Context) (context.Context, graphql.SubscriptionEventFunc, graphql.SubscriptionFinishFunc)
+}
+
+func (t *testSubscriptionExt) SubscriptionDidStart(ctx context.Context) (context.Context, graphql.SubscriptionEventFunc, graphql.SubscriptionFinishFunc) {
+	return t.subscriptionDidStartFn(ctx)
+}
+
+// resultExt returns an extension whose result is value and that records
+// the hooks it runs in calls.
+func resultExt(name string, value interface{}, calls *[]string) *testExt {
+	ext := newtestExt(name)
+	ext.initFn = func(ctx context.Context, p *graphql.Params) context.Context {
+		*calls = append(*calls, name+".Init")
+		return ctx
+	}
+	ext.resolveFieldDidStartFn = func(ctx context.Context, i *graphql.ResolveInfo) (context.Context, graphql.ResolveFieldFinishFunc) {
+		*calls = append(*calls, name+".ResolveFieldDidStart")
+		return ctx, func(interface{}, error) {}
+	}
+	ext.hasResultFn = func() bool {
+		return true
+	}
+	ext.getResultFn = func(context.Context) interface{} {
+		return value
+	}
+	return ext
+}
+
+func TestExtensionsPerRequest(t *testing.T) {
+	var calls []string
+	schema := tinit(t)
+	schema.AddExtensions(resultExt("schemaExt", "schema", &calls))
+	requestExt := resultExt("requestExt", "request", &calls)
+
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ a }`,
+		Extensions:    []graphql.Extension{requestExt},
+	})
+	expected := map[string]interface{}{"schemaExt": "schema", "requestExt": "request"}
+	if !reflect.DeepEqual(result.Extensions, expected) {
+		t.Fatalf("expected extensions %v, got %v", expected, result.Extensions)
+	}
+	expectedCalls := []string{"schemaExt.Init", "requestExt.Init", "schemaExt.ResolveFieldDidStart", "requestExt.ResolveFieldDidStart"}
+	if !reflect.DeepEqual(calls, expectedCalls) {
+		t.Fatalf("expected calls %v, got %v", expectedCalls, calls)
+	}
+
+	// the request extension doesn't run for other requests
+	calls = nil
+	result = graphql.Do(graphql.Params{Schema: schema, RequestString: `{ a }`})
+	if expected := map[string]interface{}{"schemaExt": "schema"}; !reflect.DeepEqual(result.Extensions, expected) {
+		t.Fatalf("expected extensions %v, got %v", expected, result.Extensions)
+	}
+}
+
+func TestExtensionsPerRequest_SkipAndReplace(t *testing.T) {
+	var calls []string
+	schema := tinit(t)
+	schema.AddExtensions(resultExt("skipped", 1, &calls), resultExt("replaced", 2, &calls))
+
+	result := graphql.Do(graphql.Params{
+		Schema:         schema,
+		RequestString:  `{ a }`,
+		Extensions:     []graphql.Extension{resultExt("replaced", 3, &calls)},
+		SkipExtensions: []string{"skipped"},
+	})
+	if expected := map[string]interface{}{"replaced": 3}; !reflect.DeepEqual(result.Extensions, expected) {
+		t.Fatalf("expected extensions %v, got %v", expected, result.Extensions)
+	}
+	if expectedCalls := []string{"replaced.Init", "replaced.ResolveFieldDidStart"}; !reflect.DeepEqual(calls, expectedCalls) {
+		t.Fatalf("expected calls %v, got %v", expectedCalls, calls)
+	}
+}
+
+func TestExtensionsPerRequest_ExecutePlan(t *testing.T) {
+	var calls []string
+	schema := tinit(t)
+	pr := graphql.NewPlanCache(graphql.PlanCacheOptions{}).Get(&schema, `{ a }`, "")
+	result := graphql.ExecutePlan(pr.Plan, graphql.ExecuteParams{
+		Schema:     schema,
+		Extensions: []graphql.Extension{resultExt("requestExt", "request", &calls)},
+	})
+	if expected := map[string]interface{}{"requestExt": "request"}; !reflect.DeepEqual(result.Extensions, expected) {
+		t.Fatalf("expected extensions %v, got %v", expected, result.Extensions)
+	}
+	if expectedCalls := []string{"requestExt.ResolveFieldDidStart"}; !reflect.DeepEqual(calls, expectedCalls) {
+		t.Fatalf("expected calls %v, got %v", expectedCalls, calls)
+	}
+}
+
+func TestExtensionsWithTheSameNameAllFinish(t *testing.T) {
+	var finished []int
+	schema := tinit(t)
+	for i := 0; i < 3; i++ {
+		i := i
+		ext := newtestExt("testExt")
+		ext.executionDidStartFn = func(ctx context.Context) (context.Context, graphql.ExecutionFinishFunc) {
+			return ctx, func(*graphql.Result) {
+				finished = append(finished, i)
+			}
+		}
+		schema.AddExtensions(ext)
+	}
+	graphql.Do(graphql.Params{Schema: schema, RequestString: `{ a }`})
+	if expected := []int{0, 1, 2}; !reflect.DeepEqual(finished, expected) {
+		t.Fatalf("expected the finish functions to run in order, got %v", finished)
+	}
+}
+
+func TestAddExtensionsLeavesCopiesAlone(t *testing.T) {
+	var calls []string
+	schema := tinit(t)
+	for i, name := range []string{"a", "b", "c"} {
+		schema.AddExtensions(resultExt(name, i, &calls))
+	}
+	earlier := schema
+	schema.AddExtensions(resultExt("d", 3, &calls))
+	earlier.AddExtensions(resultExt("e", 4, &calls))
+
+	result := graphql.Do(graphql.Params{Schema: schema, RequestString: `{ a }`})
+	if expected := map[string]interface{}{"a": 0, "b": 1, "c": 2, "d": 3}; !reflect.DeepEqual(result.Extensions, expected) {
+		t.Fatalf("expected extensions %v, got %v", expected, result.Extensions)
+	}
+}
@@ -0,0 +1,23 @@
+package gqlerrors
+
+// CodedError is an error with a machine-readable code. FormatError reports
+// the code as `extensions.code`, so clients can branch on it rather than
+// on the message.
+type CodedError struct {
+	Code    string
+	Message string
+}
+
+func NewCodedError(code, message string) *CodedError {
+	return &CodedError{Code: code, Message: message}
+}
+
+// implements Golang's built-in `error` interface
+func (e *CodedError) Error() string {
+	return e.Message
+}
+
+// implements ExtendedError
+func (e *CodedError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.Code}
+}
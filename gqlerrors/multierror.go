@@ -0,0 +1,74 @@
+package gqlerrors
+
+import (
+	"errors"
+	"strings"
+)
+
+// Errors is a list of errors that is itself an error. A resolver returns it
+// to report several problems for one field; the executor expands it into
+// one FormattedError per entry, all located at that field. Any other error
+// implementing `Unwrap() []error` (e.g. the result of errors.Join) is
+// expanded the same way.
+type Errors []error
+
+// implements Golang's built-in `error` interface
+func (errs Errors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (errs Errors) Unwrap() []error {
+	return errs
+}
+
+// Warning marks a resolver error as non-fatal: the field keeps its
+// resolved value and the warning is reported under the "warnings" key of
+// the result's extensions instead of in its errors.
+type Warning struct {
+	Err error
+}
+
+func NewWarning(message string) *Warning {
+	return &Warning{Err: errors.New(message)}
+}
+
+// implements Golang's built-in `error` interface
+func (w *Warning) Error() string {
+	if w.Err == nil {
+		return ""
+	}
+	return w.Err.Error()
+}
+
+func (w *Warning) Unwrap() error {
+	return w.Err
+}
+
+// Flatten expands err into its leaf errors, recursing into every error
+// that implements `Unwrap() []error`. nil entries are dropped.
+func Flatten(err error) []error {
+	if err == nil {
+		return nil
+	}
+	multi, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var flat []error
+	for _, e := range multi.Unwrap() {
+		flat = append(flat, Flatten(e)...)
+	}
+	return flat
+}
+
+// IsWarning reports whether err is, or wraps, a *Warning.
+func IsWarning(err error) bool {
+	var w *Warning
+	return errors.As(err, &w)
+}
@@ -2,10 +2,9 @@ package graphql
 
 import (
 	"context"
+	"time"
 
 	"github.com/graphql-go/graphql/gqlerrors"
-	"github.com/graphql-go/graphql/language/parser"
-	"github.com/graphql-go/graphql/language/source"
 )
 
 type Params struct {
@@ -31,39 +30,101 @@ type Params struct {
 	// Context may be provided to pass application-specific per-request
 	// information to resolve functions.
 	Context context.Context
+
+	// ErrorPresenter, when set, maps every error in the result (parse,
+	// validation and execution errors alike) to the error sent to the
+	// client.
+	ErrorPresenter ErrorPresenterFn
+
+	// PanicHandler, when set, is called with the stack trace of every
+	// panic recovered during execution.
+	PanicHandler PanicHandlerFn
+
+	// CacheControl, when set, enables cache hint computation; the
+	// response's policy is then available from Result.CachePolicy.
+	CacheControl *CacheControlConfig
+
+	// Extensions run for this request after the schema's extensions; one
+	// named like a schema extension replaces it.
+	Extensions []Extension
+
+	// SkipExtensions names the schema extensions not to run for this
+	// request.
+	SkipExtensions []string
+}
+
+// Request is a GraphQL request as clients send it: the JSON body of a
+// POST, the URL parameters of a GET or the payload of a WebSocket
+// subscribe message. DocumentID names a document of a TrustedDocuments
+// registry in place of Query.
+type Request struct {
+	Query         string                 `json:"query"`
+	DocumentID    string                 `json:"documentId,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	Extensions    map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// RequestPlanner resolves client requests to plans. *PlanCache (nil
+// included), *PersistedQueries and *TrustedDocuments implement it, so
+// servers can plan requests through whichever of them is configured.
+type RequestPlanner interface {
+	PlanRequest(ctx context.Context, schema *Schema, req Request) PlanResult
 }
 
 func Do(p Params) *Result {
-	source := source.NewSource(&source.Source{
-		Body: []byte(p.RequestString),
-		Name: "GraphQL request",
-	})
+	if p.Schema.tracer == nil && p.Schema.metrics == nil {
+		return do(p)
+	}
+	start := time.Now()
+	var report *operationReport
+	if p.Schema.metrics != nil {
+		if p.Context == nil {
+			p.Context = context.Background()
+		}
+		report = &operationReport{operationName: p.OperationName}
+		p.Context = context.WithValue(p.Context, operationReportKey{}, report)
+	}
+	ctx, span := startSpan(p.Schema.tracer, p.Context, SpanRequest, SpanAttribute{AttributeOperationName, p.OperationName})
+	p.Context = ctx
+	result := do(p)
+	span.SetAttributes(SpanAttribute{AttributeErrorCount, len(result.Errors)})
+	span.End()
+	if report != nil {
+		report.report(p.Schema.metrics, start, result.Errors)
+	}
+	return result
+}
 
+func do(p Params) *Result {
 	// run init on the extensions
-	extErrs := handleExtensionsInits(&p)
+	exts := requestExtensions(&p.Schema, p.Extensions, p.SkipExtensions)
+	extErrs := handleExtensionsInits(&p, exts)
 	if len(extErrs) != 0 {
 		return &Result{
-			Errors: extErrs,
+			Errors: PresentErrors(p.Context, p.ErrorPresenter, extErrs),
 		}
 	}
 
-	extErrs, parseFinishFn := handleExtensionsParseDidStart(&p)
+	extErrs, parseFinishFn := handleExtensionsParseDidStart(&p, exts)
 	if len(extErrs) != 0 {
 		return &Result{
-			Errors: extErrs,
+			Errors: PresentErrors(p.Context, p.ErrorPresenter, extErrs),
 		}
 	}
 
 	// parse the source
-	AST, err := parser.Parse(parser.ParseParams{Source: source})
+	AST, err := parseTraced(p.Context, &p.Schema, p.RequestString)
 	if err != nil {
+		setOperationErrorCode(p.Context, ErrCodeParseFailed)
+
 		// run parseFinishFuncs for extensions
 		extErrs = parseFinishFn(err)
 
 		// merge the errors from extensions and the original error from parser
 		extErrs = append(extErrs, gqlerrors.FormatErrors(err)...)
 		return &Result{
-			Errors: extErrs,
+			Errors: PresentErrors(p.Context, p.ErrorPresenter, extErrs),
 		}
 	}
 
@@ -71,29 +132,31 @@ func Do(p Params) *Result {
 	extErrs = parseFinishFn(err)
 	if len(extErrs) != 0 {
 		return &Result{
-			Errors: extErrs,
+			Errors: PresentErrors(p.Context, p.ErrorPresenter, extErrs),
 		}
 	}
 
 	// notify extensions about the start of the validation
-	extErrs, validationFinishFn := handleExtensionsValidationDidStart(&p)
+	extErrs, validationFinishFn := handleExtensionsValidationDidStart(&p, exts)
 	if len(extErrs) != 0 {
 		return &Result{
-			Errors: extErrs,
+			Errors: PresentErrors(p.Context, p.ErrorPresenter, extErrs),
 		}
 	}
 
 	// validate document
-	validationResult := ValidateDocument(&p.Schema, AST, nil)
+	validationResult := validateTraced(p.Context, &p.Schema, AST, validationRules(&p.Schema, p.Context))
 
 	if !validationResult.IsValid {
+		setOperationErrorCode(p.Context, ErrCodeValidationFailed)
+
 		// run validation finish functions for extensions
 		extErrs = validationFinishFn(validationResult.Errors)
 
 		// merge the errors from extensions and the original error from parser
 		extErrs = append(extErrs, validationResult.Errors...)
 		return &Result{
-			Errors: extErrs,
+			Errors: PresentErrors(p.Context, p.ErrorPresenter, extErrs),
 		}
 	}
 
@@ -101,16 +164,21 @@ func Do(p Params) *Result {
 	extErrs = validationFinishFn(validationResult.Errors)
 	if len(extErrs) != 0 {
 		return &Result{
-			Errors: extErrs,
+			Errors: PresentErrors(p.Context, p.ErrorPresenter, extErrs),
 		}
 	}
 
 	return Execute(ExecuteParams{
-		Schema:        p.Schema,
-		Root:          p.RootObject,
-		AST:           AST,
-		OperationName: p.OperationName,
-		Args:          p.VariableValues,
-		Context:       p.Context,
+		Schema:         p.Schema,
+		Root:           p.RootObject,
+		AST:            AST,
+		OperationName:  p.OperationName,
+		Args:           p.VariableValues,
+		Context:        p.Context,
+		ErrorPresenter: p.ErrorPresenter,
+		PanicHandler:   p.PanicHandler,
+		CacheControl:   p.CacheControl,
+		Extensions:     p.Extensions,
+		SkipExtensions: p.SkipExtensions,
 	})
 }
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// serveBatch executes a batch of requests and writes the array of their
+// results. The response is always 200: per-request failures, including
+// those that would get another status on their own, are reported in the
+// request's result.
+func (h *Handler) serveBatch(w http.ResponseWriter, r *http.Request, mediaType string, reqs []graphql.Request) {
+	bodies := make([]interface{}, len(reqs))
+	run := func(i int) {
+		result, err := h.execute(r, reqs[i])
+		if err != nil {
+			var he *httpError
+			if errors.As(err, &he) {
+				err = he.err
+			}
+			result = &graphql.Result{Errors: graphql.PresentErrors(r.Context(), h.opts.ErrorPresenter, gqlerrors.FormatErrors(err))}
+		}
+		bodies[i] = responseBody(result)
+	}
+
+	if h.opts.BatchConcurrency <= 1 {
+		for i := range reqs {
+			run(i)
+		}
+	} else {
+		sem := make(chan struct{}, h.opts.BatchConcurrency)
+		var wg sync.WaitGroup
+		for i := range reqs {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				run(i)
+			}(i)
+		}
+		wg.Wait()
+	}
+	writeJSON(w, mediaType, http.StatusOK, bodies)
+}
@@ -0,0 +1,125 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/handler"
+)
+
+func serveBatch(t *testing.T, h http.Handler, body string) (int, []map[string]interface{}) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, post(body, ""))
+	var results []map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+		t.Fatalf("expected a JSON array, got %q", rec.Body.String())
+	}
+	return rec.Code, results
+}
+
+func TestHandler_Batch(t *testing.T) {
+	for name, h := range handlers(t, handler.Options{MaxBatchSize: 5}) {
+		t.Run(name, func(t *testing.T) {
+			status, results := serveBatch(t, h, `[
+				{"query":"query ($name: String) { hello(name: $name) }","variables":{"name":"a"}},
+				{"query":"{ nope }"},
+				{},
+				{"query":"mutation { touch }"}
+			]`)
+			if status != http.StatusOK {
+				t.Fatalf("expected 200, got %d", status)
+			}
+			if len(results) != 4 {
+				t.Fatalf("expected 4 results, got %v", results)
+			}
+			if expected := map[string]interface{}{"hello": "hello a"}; !reflect.DeepEqual(results[0]["data"], expected) {
+				t.Fatalf("expected %v, got %v", expected, results[0])
+			}
+			for _, i := range []int{1, 2} {
+				if _, ok := results[i]["errors"]; !ok {
+					t.Fatalf("expected errors in result %d, got %v", i, results[i])
+				}
+				if _, ok := results[i]["data"]; ok {
+					t.Fatalf("expected no data in result %d, got %v", i, results[i])
+				}
+			}
+			if expected := map[string]interface{}{"touch": true}; !reflect.DeepEqual(results[3]["data"], expected) {
+				t.Fatalf("expected %v, got %v", expected, results[3])
+			}
+		})
+	}
+}
+
+func TestHandler_BatchLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		opts handler.Options
+		body string
+	}{
+		{"disabled", handler.Options{}, `[{"query":"{ hello }"}]`},
+		{"too large", handler.Options{MaxBatchSize: 2}, `[{"query":"{ hello }"},{"query":"{ hello }"},{"query":"{ hello }"}]`},
+		{"empty", handler.Options{MaxBatchSize: 2}, `[]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := serve(t, handler.New(testSchema(t), test.opts), post(test.body, ""))
+			if res.status != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d (%v)", res.status, res.body)
+			}
+		})
+	}
+}
+
+func TestHandler_BatchConcurrency(t *testing.T) {
+	var running, peak int32
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"slow": &graphql.Field{
+					Type: graphql.Int,
+					Args: graphql.FieldConfigArgument{"n": &graphql.ArgumentConfig{Type: graphql.Int}},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						n := atomic.AddInt32(&running, 1)
+						defer atomic.AddInt32(&running, -1)
+						for {
+							old := atomic.LoadInt32(&peak)
+							if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+								break
+							}
+						}
+						time.Sleep(10 * time.Millisecond)
+						return p.Args["n"], nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h := handler.New(&schema, handler.Options{MaxBatchSize: 10, BatchConcurrency: 2})
+
+	var reqs []string
+	for i := 0; i < 6; i++ {
+		reqs = append(reqs, `{"query":"{ slow(n: `+strconv.Itoa(i)+`) }"}`)
+	}
+	_, results := serveBatch(t, h, "["+strings.Join(reqs, ",")+"]")
+	for i, result := range results {
+		if expected := map[string]interface{}{"slow": float64(i)}; !reflect.DeepEqual(result["data"], expected) {
+			t.Fatalf("result %d: expected %v, got %v", i, expected, result)
+		}
+	}
+	if peak := atomic.LoadInt32(&peak); peak != 2 {
+		t.Fatalf("expected at most 2 operations at once, and some overlap; peak was %d", peak)
+	}
+}
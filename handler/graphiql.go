@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"net/http"
+	"strconv"
+)
+
+//go:generate sh graphiql/vendor.sh
+
+// graphiqlFiles holds the page and the GraphiQL bundle vendored by
+// graphiql/vendor.sh.
+//
+//go:embed graphiql
+var graphiqlFiles embed.FS
+
+var graphiqlTemplate = template.Must(template.ParseFS(graphiqlFiles, "graphiql/index.html"))
+
+// GraphiQLOptions configures a GraphiQL handler.
+type GraphiQLOptions struct {
+	// Endpoint is the URL queries and mutations are posted to, relative to
+	// the page or absolute; the default is "/graphql".
+	Endpoint string
+
+	// SubscriptionEndpoint is the URL of a graphql-transport-ws endpoint
+	// (see transport.NewWebSocketHandler), as ws://, wss://, http://,
+	// https:// or relative to the page. Subscriptions are unavailable in
+	// GraphiQL without it.
+	SubscriptionEndpoint string
+
+	// Title is the title of the page; the default is "GraphiQL".
+	Title string
+
+	// DefaultQuery is shown in the editor on a first visit, in place of
+	// GraphiQL's welcome text; later visits restore the editors from the
+	// browser's local storage.
+	DefaultQuery string
+
+	// Disabled makes the handler answer 404, to keep GraphiQL registered
+	// but unreachable in production.
+	Disabled bool
+}
+
+// GraphiQL is an http.Handler serving GraphiQL, the GraphQL IDE, for a
+// GraphQL endpoint. The page is self-contained: GraphiQL and React are
+// vendored in graphiql/ and embedded in the binary, and nothing is loaded
+// from a CDN.
+type GraphiQL struct {
+	page     []byte
+	disabled bool
+}
+
+// NewGraphiQL returns a handler serving GraphiQL:
+//
+//	http.Handle("/graphiql", handler.NewGraphiQL(handler.GraphiQLOptions{
+//		Endpoint:             "/graphql",
+//		SubscriptionEndpoint: "/graphql/ws",
+//		Disabled:             production,
+//	}))
+func NewGraphiQL(opts GraphiQLOptions) *GraphiQL {
+	if opts.Endpoint == "" {
+		opts.Endpoint = "/graphql"
+	}
+	if opts.Title == "" {
+		opts.Title = "GraphiQL"
+	}
+	if opts.Disabled {
+		return &GraphiQL{disabled: true}
+	}
+	var page bytes.Buffer
+	err := graphiqlTemplate.Execute(&page, map[string]interface{}{
+		"Title": opts.Title,
+		"Config": map[string]string{
+			"endpoint":             opts.Endpoint,
+			"subscriptionEndpoint": opts.SubscriptionEndpoint,
+			"defaultQuery":         opts.DefaultQuery,
+		},
+		"React":    template.JS(graphiqlAsset("react.production.min.js")),
+		"ReactDOM": template.JS(graphiqlAsset("react-dom.production.min.js")),
+		"GraphiQL": template.JS(graphiqlAsset("graphiql.min.js")),
+		"Style":    template.CSS(graphiqlAsset("graphiql.min.css")),
+	})
+	if err != nil {
+		// the template and its data are fixed; only a broken build fails
+		panic(err)
+	}
+	return &GraphiQL{page: page.Bytes()}
+}
+
+// graphiqlAsset returns a file of the vendored bundle; a build without it
+// is broken.
+func graphiqlAsset(name string) []byte {
+	b, err := graphiqlFiles.ReadFile("graphiql/" + name)
+	if err != nil {
+		panic("handler: the GraphiQL bundle isn't vendored, run go generate ./handler: " + err.Error())
+	}
+	return b
+}
+
+// ServeHTTP serves the page to GET and HEAD requests.
+func (h *GraphiQL) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.disabled {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	header := w.Header()
+	header.Set("Content-Type", "text/html; charset=utf-8")
+	header.Set("Content-Length", strconv.Itoa(len(h.page)))
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("X-Frame-Options", "DENY")
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(h.page)
+}
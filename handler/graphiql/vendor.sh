@@ -0,0 +1,17 @@
+#!/bin/sh
+# Downloads the GraphiQL bundle NewGraphiQL embeds: GraphiQL and the React
+# it runs on, as UMD builds. Bump the versions here and run
+# `go generate ./handler` to update it.
+set -eu
+
+GRAPHIQL_VERSION=3.0.0
+REACT_VERSION=18.2.0
+
+cd "$(dirname "$0")"
+fetch() {
+	curl -fsSL -o "$1" "https://unpkg.com/$2"
+}
+fetch react.production.min.js "react@$REACT_VERSION/umd/react.production.min.js"
+fetch react-dom.production.min.js "react-dom@$REACT_VERSION/umd/react-dom.production.min.js"
+fetch graphiql.min.js "graphiql@$GRAPHIQL_VERSION/graphiql.min.js"
+fetch graphiql.min.css "graphiql@$GRAPHIQL_VERSION/graphiql.min.css"
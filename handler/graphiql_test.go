@@ -0,0 +1,95 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/handler"
+)
+
+// requireGraphiQLBundle skips tests serving the page in trees the bundle
+// wasn't vendored in yet.
+func requireGraphiQLBundle(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"react.production.min.js", "react-dom.production.min.js", "graphiql.min.js", "graphiql.min.css"} {
+		if _, err := os.Stat("graphiql/" + name); err != nil {
+			t.Skipf("the GraphiQL bundle isn't vendored, run go generate ./handler: %v", err)
+		}
+	}
+}
+
+func TestGraphiQL(t *testing.T) {
+	requireGraphiQLBundle(t)
+	h := handler.NewGraphiQL(handler.GraphiQLOptions{
+		Endpoint:             "/api/graphql",
+		SubscriptionEndpoint: "wss://example.com/graphql/ws",
+		Title:                "Example </title> IDE",
+		DefaultQuery:         "{ hello }",
+	})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphiql", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	page := rec.Body.String()
+	for _, expected := range []string{
+		`"endpoint":"/api/graphql"`,
+		`"subscriptionEndpoint":"wss://example.com/graphql/ws"`,
+		`"defaultQuery":"{ hello }"`,
+		`<title>Example &lt;/title&gt; IDE</title>`,
+		`GraphiQL.createFetcher`,
+		`React.createElement(GraphiQL`,
+	} {
+		if !strings.Contains(page, expected) {
+			t.Fatalf("expected the page to contain %q", expected)
+		}
+	}
+	// the page must work offline: no external scripts or styles
+	for _, unexpected := range []string{`<script src`, `<link`, `ZgotmplZ`} {
+		if strings.Contains(page, unexpected) {
+			t.Fatalf("expected the page not to contain %q", unexpected)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/graphiql", nil))
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 || rec.Header().Get("Content-Length") != strconv.Itoa(len(page)) {
+		t.Fatalf("unexpected HEAD response: %d %q %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphiql", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, HEAD" {
+		t.Fatalf("expected 405, got %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestGraphiQL_Defaults(t *testing.T) {
+	requireGraphiQLBundle(t)
+	rec := httptest.NewRecorder()
+	handler.NewGraphiQL(handler.GraphiQLOptions{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	page := rec.Body.String()
+	for _, expected := range []string{`"endpoint":"/graphql"`, `"subscriptionEndpoint":""`, `"defaultQuery":""`, `<title>GraphiQL</title>`} {
+		if !strings.Contains(page, expected) {
+			t.Fatalf("expected the page to contain %q", expected)
+		}
+	}
+}
+
+func TestGraphiQL_Disabled(t *testing.T) {
+	rec := httptest.NewRecorder()
+	handler.NewGraphiQL(handler.GraphiQLOptions{Disabled: true}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphiql", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "<html") {
+		t.Fatalf("expected no page, got %q", rec.Body.String())
+	}
+}
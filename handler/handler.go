@@ -0,0 +1,311 @@
+// Package handler serves GraphQL over HTTP following the GraphQL-over-HTTP
+// specification:
+//
+//	h := handler.New(&schema, handler.Options{
+//		Cache: graphql.NewPlanCache(graphql.PlanCacheOptions{}),
+//	})
+//	http.Handle("/graphql", h)
+//
+// Queries may be sent with GET or POST; mutations only with POST. Responses
+// are application/json or application/graphql-response+json, as negotiated
+// from the Accept header, with the status codes the specification assigns
+// to each. Batches of requests, sent as a JSON array, are accepted when
+// Options.MaxBatchSize is set, and multipart requests carrying file
+// uploads when Options.MaxUploadSize is. Subscriptions are served by the
+// transport package. NewGraphiQL serves GraphiQL, the in-browser GraphQL
+// IDE, for the endpoint.
+//
+// Endpoints reachable from browsers should enable Options.CSRFPrevention:
+// otherwise any page can make its visitors' browsers send GET requests,
+// or multipart POST requests, to the endpoint. Options.CORS allows other
+// origins to read responses.
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Media types of GraphQL responses.
+const (
+	ContentTypeJSON            = "application/json"
+	ContentTypeGraphQLResponse = "application/graphql-response+json"
+)
+
+const defaultMaxBodySize = 1 << 20
+
+// Options tunes a Handler. Zero values get sensible defaults.
+type Options struct {
+	// Cache, when set, caches the parsed, validated and planned requests;
+	// without one every request is planned afresh. Either way requests
+	// run with graphql.ExecutePlan, so the Init, ParseDidStart and
+	// ValidationDidStart hooks of extensions don't run.
+	Cache *graphql.PlanCache
+
+	// PersistedQueries, when set, serves Automatic Persisted Queries:
+	// requests may then send only the hash of a query registered before,
+	// in `extensions.persistedQuery`. Requests are planned through its
+	// own PlanCache rather than Cache.
+	PersistedQueries *graphql.PersistedQueries
+
+	// TrustedDocuments, when set, only runs the registered documents,
+	// named by `documentId`, by the hash in `extensions.persistedQuery`
+	// or sent as their exact text; other requests are rejected unless the
+	// registry allows arbitrary queries. It takes precedence over
+	// PersistedQueries and Cache.
+	TrustedDocuments *graphql.TrustedDocuments
+
+	RootObject     map[string]interface{}
+	ErrorPresenter graphql.ErrorPresenterFn
+	PanicHandler   graphql.PanicHandlerFn
+	CacheControl   *graphql.CacheControlConfig
+
+	// MaxBodySize caps POST bodies, in bytes; the default is 1MiB.
+	MaxBodySize int64
+
+	// MaxUploadSize enables multipart requests with file uploads, as
+	// values of the graphql.Upload scalar, and caps their size in bytes.
+	// 0 disables them.
+	MaxUploadSize int64
+
+	// UploadMemory is how much of a multipart request is held in memory;
+	// the rest is spooled to temporary files, removed once the request
+	// completes. The default is 10MiB.
+	UploadMemory int64
+
+	// MaxBatchSize enables batched requests: a POST body may then be a
+	// JSON array of up to MaxBatchSize requests, answered with the array
+	// of their results, in order. 0 disables batching.
+	MaxBatchSize int
+
+	// BatchConcurrency caps the operations of a batch executing at once;
+	// 0 or 1 runs them one after the other. Mutations of a batch run
+	// concurrently too when it is above 1.
+	BatchConcurrency int
+
+	// CSRFPrevention rejects requests a browser would send cross-origin
+	// without a CORS preflight: GET requests and POST requests with a
+	// simple content type, such as multipart/form-data, must then carry
+	// one of CSRFHeaders. JSON POST requests always preflight and pass.
+	CSRFPrevention bool
+
+	// CSRFHeaders are the headers, any of which exempts a request from
+	// CSRF prevention; the default is DefaultCSRFHeaders.
+	CSRFHeaders []string
+
+	// CORS, when set, answers CORS preflight requests and adds the CORS
+	// headers to responses to the origins it allows.
+	CORS *CORSOptions
+
+	// DisableIntrospection validates requests with
+	// graphql.NoSchemaIntrospectionRule, rejecting those selecting
+	// __schema or __type, unless TrustRequest reports them as trusted.
+	DisableIntrospection bool
+	TrustRequest         func(r *http.Request) bool
+
+	// Extensions, when set, returns the extensions to run for a request
+	// on top of the schema's, e.g. a graphql.TracingExtension for the
+	// requests to trace.
+	Extensions func(r *http.Request) []graphql.Extension
+}
+
+// Handler is an http.Handler executing GraphQL requests against a schema.
+type Handler struct {
+	schema  *graphql.Schema
+	opts    Options
+	planner graphql.RequestPlanner
+}
+
+// New returns a handler executing requests against schema.
+func New(schema *graphql.Schema, opts Options) *Handler {
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = defaultMaxBodySize
+	}
+	if opts.UploadMemory <= 0 {
+		opts.UploadMemory = defaultUploadMemory
+	}
+	if opts.CSRFHeaders == nil {
+		opts.CSRFHeaders = DefaultCSRFHeaders
+	}
+	h := &Handler{schema: schema, opts: opts, planner: opts.Cache}
+	switch {
+	case opts.TrustedDocuments != nil:
+		h.planner = opts.TrustedDocuments
+	case opts.PersistedQueries != nil:
+		h.planner = opts.PersistedQueries
+	}
+	return h
+}
+
+// ServeHTTP executes the request's operation and writes its result.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.opts.CORS != nil && h.serveCORS(w, r) {
+		return
+	}
+	mediaType, ok := negotiate(r.Header.Get("Accept"))
+	if !ok {
+		h.writeError(w, r, ContentTypeJSON, &httpError{
+			status: http.StatusNotAcceptable,
+			err:    errors.New("Supported response types are application/graphql-response+json and application/json."),
+		})
+		return
+	}
+	if err := h.checkCSRF(r); err != nil {
+		h.writeError(w, r, mediaType, err)
+		return
+	}
+	reqs, batch, err := h.readRequest(w, r)
+	defer closeUploads(r.MultipartForm, reqs)
+	if err != nil {
+		h.writeError(w, r, mediaType, err)
+		return
+	}
+	if batch {
+		h.serveBatch(w, r, mediaType, reqs)
+		return
+	}
+	result, err := h.execute(r, reqs[0])
+	if err != nil {
+		h.writeError(w, r, mediaType, err)
+		return
+	}
+	status := http.StatusOK
+	if isRequestError(result) && mediaType == ContentTypeGraphQLResponse {
+		status = http.StatusBadRequest
+	}
+	writeResult(w, mediaType, status, result)
+}
+
+// execute runs req, sent with r. It returns an *httpError for requests
+// without a query and requests the method doesn't allow.
+func (h *Handler) execute(r *http.Request, req graphql.Request) (*graphql.Result, error) {
+	ctx, method := r.Context(), r.Method
+	if req.Query == "" && req.DocumentID == "" && req.Extensions["persistedQuery"] == nil {
+		return nil, badRequest("Must provide query string.")
+	}
+	if h.opts.DisableIntrospection && (h.opts.TrustRequest == nil || !h.opts.TrustRequest(r)) {
+		ctx = graphql.WithNoSchemaIntrospection(ctx)
+	}
+	var exts []graphql.Extension
+	if h.opts.Extensions != nil {
+		exts = h.opts.Extensions(r)
+	}
+	// a nil cache plans the request afresh, parsing it once
+	pr := h.planner.PlanRequest(ctx, h.schema, req)
+	if len(pr.Errors) > 0 {
+		return &graphql.Result{Errors: graphql.PresentErrors(ctx, h.opts.ErrorPresenter, pr.Errors)}, nil
+	}
+	if err := checkOperationType(method, pr.Plan.OperationType()); err != nil {
+		return nil, err
+	}
+	args := req.Variables
+	if len(pr.SynthArgs) > 0 {
+		args = make(map[string]interface{}, len(req.Variables)+len(pr.SynthArgs))
+		for k, v := range req.Variables {
+			args[k] = v
+		}
+		for k, v := range pr.SynthArgs {
+			args[k] = v
+		}
+	}
+	return graphql.ExecutePlan(pr.Plan, graphql.ExecuteParams{
+		Schema:         *h.schema,
+		Root:           h.opts.RootObject,
+		Args:           args,
+		Context:        ctx,
+		ErrorPresenter: h.opts.ErrorPresenter,
+		PanicHandler:   h.opts.PanicHandler,
+		CacheControl:   h.opts.CacheControl,
+		Extensions:     exts,
+	}), nil
+}
+
+// checkOperationType rejects mutations over GET, which must not have side
+// effects, and subscriptions, which need a streaming transport.
+func checkOperationType(method, opType string) error {
+	switch {
+	case opType == ast.OperationTypeSubscription:
+		return &httpError{
+			status: http.StatusBadRequest,
+			err:    errors.New("Subscriptions are not supported over this endpoint."),
+		}
+	case opType == ast.OperationTypeMutation && method != http.MethodPost:
+		return &httpError{
+			status: http.StatusMethodNotAllowed,
+			allow:  http.MethodPost,
+			err:    errors.New("Mutations can only be sent over POST."),
+		}
+	}
+	return nil
+}
+
+// isRequestError reports whether the request failed before execution
+// started: there is no data, and none of the errors comes from a field.
+func isRequestError(result *graphql.Result) bool {
+	if result.Data != nil || len(result.Errors) == 0 {
+		return false
+	}
+	for _, err := range result.Errors {
+		if len(err.Path) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// httpError is a request rejected at the HTTP level, before GraphQL
+// execution.
+type httpError struct {
+	status int
+	allow  string
+	err    error
+}
+
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, mediaType string, err error) {
+	status := http.StatusBadRequest
+	var he *httpError
+	if errors.As(err, &he) {
+		status = he.status
+		if he.allow != "" {
+			w.Header().Set("Allow", he.allow)
+		}
+		err = he.err
+	}
+	writeResult(w, mediaType, status, &graphql.Result{
+		Errors: graphql.PresentErrors(r.Context(), h.opts.ErrorPresenter, gqlerrors.FormatErrors(err)),
+	})
+}
+
+// requestErrorResponse is the body of a response to a request that was
+// never executed: unlike graphql.Result, it has no data entry.
+type requestErrorResponse struct {
+	Errors     []gqlerrors.FormattedError `json:"errors"`
+	Extensions map[string]interface{}     `json:"extensions,omitempty"`
+}
+
+func writeResult(w http.ResponseWriter, mediaType string, status int, result *graphql.Result) {
+	writeJSON(w, mediaType, status, responseBody(result))
+}
+
+// responseBody returns the JSON body for result.
+func responseBody(result *graphql.Result) interface{} {
+	if isRequestError(result) {
+		return &requestErrorResponse{Errors: result.Errors, Extensions: result.Extensions}
+	}
+	return result
+}
+
+func writeJSON(w http.ResponseWriter, mediaType string, status int, body interface{}) {
+	w.Header().Set("Content-Type", mediaType+"; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
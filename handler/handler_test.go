@@ -0,0 +1,399 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/handler"
+)
+
+func testSchema(t *testing.T) *graphql.Schema {
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"hello": &graphql.Field{
+					Type: graphql.String,
+					Args: graphql.FieldConfigArgument{
+						"name": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: "world"},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return "hello " + p.Args["name"].(string), nil
+					},
+				},
+				"fail": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.String),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return nil, errors.New("failed")
+					},
+				},
+			},
+		}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Mutation",
+			Fields: graphql.Fields{
+				"touch": &graphql.Field{
+					Type: graphql.Boolean,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return true, nil
+					},
+				},
+			},
+		}),
+		Subscription: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Subscription",
+			Fields: graphql.Fields{
+				"ticks": &graphql.Field{Type: graphql.Int},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &schema
+}
+
+type response struct {
+	status      int
+	contentType string
+	allow       string
+	body        map[string]interface{}
+}
+
+func serve(t *testing.T, h http.Handler, r *http.Request) response {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response is not JSON: %q", rec.Body.String())
+	}
+	return response{
+		status:      rec.Code,
+		contentType: rec.Header().Get("Content-Type"),
+		allow:       rec.Header().Get("Allow"),
+		body:        body,
+	}
+}
+
+func post(body, accept string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+	return r
+}
+
+func get(params url.Values, accept string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/graphql?"+params.Encode(), nil)
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+	return r
+}
+
+// handlers returns a handler planning every request afresh and one going
+// through a PlanCache; both must behave the same.
+func handlers(t *testing.T, opts handler.Options) map[string]http.Handler {
+	schema := testSchema(t)
+	cached := opts
+	cached.Cache = graphql.NewPlanCache(graphql.PlanCacheOptions{})
+	return map[string]http.Handler{
+		"Uncached":  handler.New(schema, opts),
+		"PlanCache": handler.New(schema, cached),
+	}
+}
+
+func TestHandler_ExecutesRequests(t *testing.T) {
+	expected := map[string]interface{}{
+		"data": map[string]interface{}{"hello": "hello gopher"},
+	}
+	for name, h := range handlers(t, handler.Options{}) {
+		t.Run(name, func(t *testing.T) {
+			requests := map[string]*http.Request{
+				"POST": post(`{"query":"query ($name: String) { hello(name: $name) }","variables":{"name":"gopher"}}`, ""),
+				"GET": get(url.Values{
+					"query":     {"query ($name: String) { hello(name: $name) }"},
+					"variables": {`{"name":"gopher"}`},
+				}, ""),
+			}
+			for method, r := range requests {
+				res := serve(t, h, r)
+				if res.status != http.StatusOK {
+					t.Fatalf("%s: expected 200, got %d", method, res.status)
+				}
+				if !reflect.DeepEqual(res.body, expected) {
+					t.Fatalf("%s: expected %v, got %v", method, expected, res.body)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_ContentNegotiation(t *testing.T) {
+	tests := []struct {
+		accept      string
+		status      int
+		contentType string
+	}{
+		{"", http.StatusOK, handler.ContentTypeJSON},
+		{"*/*", http.StatusOK, handler.ContentTypeJSON},
+		{"application/json", http.StatusOK, handler.ContentTypeJSON},
+		{"application/graphql-response+json", http.StatusOK, handler.ContentTypeGraphQLResponse},
+		{"application/graphql-response+json, application/json;q=0.9", http.StatusOK, handler.ContentTypeGraphQLResponse},
+		{"application/graphql-response+json;q=0.5, application/json", http.StatusOK, handler.ContentTypeJSON},
+		{"text/html", http.StatusNotAcceptable, handler.ContentTypeJSON},
+	}
+	h := handler.New(testSchema(t), handler.Options{})
+	for _, test := range tests {
+		res := serve(t, h, post(`{"query":"{ hello }"}`, test.accept))
+		if res.status != test.status {
+			t.Fatalf("Accept %q: expected %d, got %d", test.accept, test.status, res.status)
+		}
+		if !strings.HasPrefix(res.contentType, test.contentType) {
+			t.Fatalf("Accept %q: expected %s, got %s", test.accept, test.contentType, res.contentType)
+		}
+	}
+}
+
+func TestHandler_StatusCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    func() *http.Request
+		json   int
+		gqlRes int
+		data   bool
+	}{
+		{
+			name: "parse error",
+			req:  func() *http.Request { return post(`{"query":"{"}`, "") },
+			json: http.StatusOK, gqlRes: http.StatusBadRequest,
+		},
+		{
+			name: "validation error",
+			req:  func() *http.Request { return post(`{"query":"{ nope }"}`, "") },
+			json: http.StatusOK, gqlRes: http.StatusBadRequest,
+		},
+		{
+			name: "variable coercion error",
+			req: func() *http.Request {
+				return post(`{"query":"query ($name: String!) { hello(name: $name) }"}`, "")
+			},
+			json: http.StatusOK, gqlRes: http.StatusBadRequest,
+		},
+		{
+			name: "field error",
+			req:  func() *http.Request { return post(`{"query":"{ fail }"}`, "") },
+			json: http.StatusOK, gqlRes: http.StatusOK, data: true,
+		},
+		{
+			name: "malformed body",
+			req:  func() *http.Request { return post(`{`, "") },
+			json: http.StatusBadRequest, gqlRes: http.StatusBadRequest,
+		},
+		{
+			name: "missing query",
+			req:  func() *http.Request { return post(`{}`, "") },
+			json: http.StatusBadRequest, gqlRes: http.StatusBadRequest,
+		},
+		{
+			name: "unsupported content type",
+			req: func() *http.Request {
+				r := post(`{"query":"{ hello }"}`, "")
+				r.Header.Set("Content-Type", "text/plain")
+				return r
+			},
+			json: http.StatusUnsupportedMediaType, gqlRes: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "unsupported method",
+			req:  func() *http.Request { return httptest.NewRequest(http.MethodPut, "/graphql", nil) },
+			json: http.StatusMethodNotAllowed, gqlRes: http.StatusMethodNotAllowed,
+		},
+		{
+			name: "subscription",
+			req:  func() *http.Request { return post(`{"query":"subscription { ticks }"}`, "") },
+			json: http.StatusBadRequest, gqlRes: http.StatusBadRequest,
+		},
+	}
+	for name, h := range handlers(t, handler.Options{}) {
+		for _, test := range tests {
+			t.Run(name+"/"+test.name, func(t *testing.T) {
+				for accept, status := range map[string]int{
+					handler.ContentTypeJSON:            test.json,
+					handler.ContentTypeGraphQLResponse: test.gqlRes,
+				} {
+					r := test.req()
+					r.Header.Set("Accept", accept)
+					res := serve(t, h, r)
+					if res.status != status {
+						t.Fatalf("%s: expected %d, got %d (%v)", accept, status, res.status, res.body)
+					}
+					if _, ok := res.body["errors"]; !ok {
+						t.Fatalf("%s: expected errors, got %v", accept, res.body)
+					}
+					if _, ok := res.body["data"]; ok != test.data {
+						t.Fatalf("%s: expected data entry: %v, got %v", accept, test.data, res.body)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestHandler_RejectsMutationsOverGet(t *testing.T) {
+	for name, h := range handlers(t, handler.Options{}) {
+		t.Run(name, func(t *testing.T) {
+			res := serve(t, h, get(url.Values{"query": {"mutation { touch }"}}, ""))
+			if res.status != http.StatusMethodNotAllowed {
+				t.Fatalf("expected 405, got %d", res.status)
+			}
+			if res.allow != http.MethodPost {
+				t.Fatalf("expected Allow: POST, got %q", res.allow)
+			}
+
+			res = serve(t, h, post(`{"query":"mutation { touch }"}`, ""))
+			expected := map[string]interface{}{"data": map[string]interface{}{"touch": true}}
+			if res.status != http.StatusOK || !reflect.DeepEqual(res.body, expected) {
+				t.Fatalf("expected the mutation to run over POST, got %d %v", res.status, res.body)
+			}
+		})
+	}
+}
+
+func TestHandler_SelectsOperationOverGet(t *testing.T) {
+	h := handler.New(testSchema(t), handler.Options{})
+	query := "query Q { hello } mutation M { touch }"
+	res := serve(t, h, get(url.Values{"query": {query}, "operationName": {"Q"}}, ""))
+	if res.status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.status)
+	}
+	res = serve(t, h, get(url.Values{"query": {query}, "operationName": {"M"}}, ""))
+	if res.status != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", res.status)
+	}
+}
+
+func TestHandler_MaxBodySize(t *testing.T) {
+	h := handler.New(testSchema(t), handler.Options{MaxBodySize: 32})
+	res := serve(t, h, post(`{"query":"{ hello }"}`, ""))
+	if res.status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.status)
+	}
+	res = serve(t, h, post(`{"query":"{ hello }`+strings.Repeat(" ", 32)+`"}`, ""))
+	if res.status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", res.status)
+	}
+}
+
+func TestHandler_PerRequestExtensions(t *testing.T) {
+	tracing := graphql.NewTracingExtension(graphql.TracingOptions{})
+	opts := handler.Options{
+		Extensions: func(r *http.Request) []graphql.Extension {
+			if r.Header.Get("X-Trace") == "" {
+				return nil
+			}
+			return []graphql.Extension{tracing}
+		},
+	}
+	for name, h := range handlers(t, opts) {
+		t.Run(name, func(t *testing.T) {
+			res := serve(t, h, post(`{"query":"{ hello }"}`, ""))
+			if _, ok := res.body["extensions"]; ok {
+				t.Fatalf("expected no extensions, got %v", res.body)
+			}
+			r := post(`{"query":"{ hello }"}`, "")
+			r.Header.Set("X-Trace", "1")
+			res = serve(t, h, r)
+			extensions, _ := res.body["extensions"].(map[string]interface{})
+			if _, ok := extensions["tracing"]; !ok {
+				t.Fatalf("expected a tracing entry, got %v", res.body)
+			}
+		})
+	}
+}
+
+// errorCode returns the `extensions.code` of the first error of body.
+func errorCode(body map[string]interface{}) interface{} {
+	errs, _ := body["errors"].([]interface{})
+	if len(errs) == 0 {
+		return nil
+	}
+	err, _ := errs[0].(map[string]interface{})
+	extensions, _ := err["extensions"].(map[string]interface{})
+	return extensions["code"]
+}
+
+func TestHandler_PersistedQueries(t *testing.T) {
+	h := handler.New(testSchema(t), handler.Options{
+		PersistedQueries: graphql.NewPersistedQueries(nil, nil),
+	})
+	extensions := `{"persistedQuery":{"version":1,"sha256Hash":"` + graphql.HashQuery("{ hello }") + `"}}`
+	hashOnly := get(url.Values{"extensions": {extensions}}, "")
+
+	res := serve(t, h, hashOnly)
+	if code := errorCode(res.body); code != graphql.ErrCodePersistedQueryNotFound {
+		t.Fatalf("expected %s, got %v", graphql.ErrCodePersistedQueryNotFound, res.body)
+	}
+
+	expected := map[string]interface{}{"data": map[string]interface{}{"hello": "hello world"}}
+	res = serve(t, h, post(`{"query":"{ hello }","extensions":`+extensions+`}`, ""))
+	if res.status != http.StatusOK || !reflect.DeepEqual(res.body, expected) {
+		t.Fatalf("expected the query to be registered and run, got %d %v", res.status, res.body)
+	}
+	res = serve(t, h, get(url.Values{"extensions": {extensions}}, ""))
+	if res.status != http.StatusOK || !reflect.DeepEqual(res.body, expected) {
+		t.Fatalf("expected the registered query to run from its hash, got %d %v", res.status, res.body)
+	}
+
+	// without persisted queries, clients are told to send the text
+	res = serve(t, handler.New(testSchema(t), handler.Options{}), get(url.Values{"extensions": {extensions}}, ""))
+	if code := errorCode(res.body); code != graphql.ErrCodePersistedQueryNotSupported {
+		t.Fatalf("expected %s, got %v", graphql.ErrCodePersistedQueryNotSupported, res.body)
+	}
+}
+
+func TestHandler_TrustedDocuments(t *testing.T) {
+	schema := testSchema(t)
+	td, err := graphql.NewTrustedDocuments(schema, map[string]string{
+		"hello": "{ hello }",
+		"touch": "mutation { touch }",
+	}, graphql.TrustedDocumentsOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h := handler.New(schema, handler.Options{TrustedDocuments: td})
+
+	expected := map[string]interface{}{"data": map[string]interface{}{"hello": "hello world"}}
+	for name, r := range map[string]*http.Request{
+		"POST documentId": post(`{"documentId":"hello"}`, ""),
+		"GET documentId":  get(url.Values{"documentId": {"hello"}}, ""),
+		"registered text": post(`{"query":"{ hello }"}`, ""),
+	} {
+		res := serve(t, h, r)
+		if res.status != http.StatusOK || !reflect.DeepEqual(res.body, expected) {
+			t.Fatalf("%s: expected the document to run, got %d %v", name, res.status, res.body)
+		}
+	}
+
+	res := serve(t, h, post(`{"query":"{ hello(name: \"mallory\") }"}`, ""))
+	if code := errorCode(res.body); code != graphql.ErrCodeTrustedDocumentRequired || res.body["data"] != nil {
+		t.Fatalf("expected %s, got %v", graphql.ErrCodeTrustedDocumentRequired, res.body)
+	}
+	res = serve(t, h, post(`{"documentId":"nope"}`, ""))
+	if code := errorCode(res.body); code != graphql.ErrCodeUnknownDocumentID {
+		t.Fatalf("expected %s, got %v", graphql.ErrCodeUnknownDocumentID, res.body)
+	}
+	res = serve(t, h, get(url.Values{"documentId": {"touch"}}, ""))
+	if res.status != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", res.status)
+	}
+}
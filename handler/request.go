@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+// readRequest decodes the request; batch is set when a POST body holds an
+// array of requests. Malformed requests yield an *httpError carrying the
+// status to answer with.
+func (h *Handler) readRequest(w http.ResponseWriter, r *http.Request) (reqs []graphql.Request, batch bool, err error) {
+	switch r.Method {
+	case http.MethodGet:
+		var req graphql.Request
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.DocumentID = q.Get("documentId")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				return nil, false, badRequest("Variables are invalid JSON: %v", err)
+			}
+		}
+		if v := q.Get("extensions"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Extensions); err != nil {
+				return nil, false, badRequest("Extensions are invalid JSON: %v", err)
+			}
+		}
+		return []graphql.Request{req}, false, nil
+	case http.MethodPost:
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch {
+		case err == nil && mediaType == ContentTypeJSON:
+			return h.readJSONBody(w, r)
+		case err == nil && mediaType == "multipart/form-data" && h.opts.MaxUploadSize > 0:
+			return h.readMultipart(w, r)
+		}
+		return nil, false, &httpError{
+			status: http.StatusUnsupportedMediaType,
+			err:    errors.New("Requests must be sent as application/json."),
+		}
+	default:
+		return nil, false, &httpError{
+			status: http.StatusMethodNotAllowed,
+			allow:  "GET, POST",
+			err:    fmt.Errorf("Method %s is not allowed.", r.Method),
+		}
+	}
+}
+
+// readJSONBody decodes a POST body holding a request or, when batching is
+// enabled, an array of requests.
+func (h *Handler) readJSONBody(w http.ResponseWriter, r *http.Request) ([]graphql.Request, bool, error) {
+	var raw json.RawMessage
+	body := http.MaxBytesReader(w, r.Body, h.opts.MaxBodySize)
+	if err := json.NewDecoder(body).Decode(&raw); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, false, &httpError{
+				status: http.StatusRequestEntityTooLarge,
+				err:    fmt.Errorf("Request body exceeds %d bytes.", h.opts.MaxBodySize),
+			}
+		}
+		return nil, false, badRequest("Request body is invalid JSON: %v", err)
+	}
+	if len(raw) == 0 || raw[0] != '[' {
+		var req graphql.Request
+		if err := json.Unmarshal(raw, &req); err != nil {
+			return nil, false, badRequest("Request body is invalid: %v", err)
+		}
+		return []graphql.Request{req}, false, nil
+	}
+	if h.opts.MaxBatchSize <= 0 {
+		return nil, false, badRequest("Batched requests are not supported.")
+	}
+	var reqs []graphql.Request
+	if err := json.Unmarshal(raw, &reqs); err != nil {
+		return nil, false, badRequest("Request body is invalid: %v", err)
+	}
+	if err := h.checkBatchSize(len(reqs)); err != nil {
+		return nil, false, err
+	}
+	return reqs, true, nil
+}
+
+// checkBatchSize rejects batches of n requests when batching is disabled
+// or n is out of bounds.
+func (h *Handler) checkBatchSize(n int) error {
+	switch {
+	case h.opts.MaxBatchSize <= 0:
+		return badRequest("Batched requests are not supported.")
+	case n == 0:
+		return badRequest("Batch must contain at least one request.")
+	case n > h.opts.MaxBatchSize:
+		return badRequest("Batch of %d requests exceeds the limit of %d.", n, h.opts.MaxBatchSize)
+	}
+	return nil
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &httpError{status: http.StatusBadRequest, err: fmt.Errorf(format, args...)}
+}
+
+// negotiate picks the response media type from an Accept header. Without
+// one, clients get application/json; wildcards also select it, as legacy
+// clients expect.
+func negotiate(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return ContentTypeJSON, true
+	}
+	best, bestQ := "", 0.0
+	for _, item := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(item))
+		if err != nil {
+			continue
+		}
+		var candidate string
+		switch mediaType {
+		case ContentTypeGraphQLResponse:
+			candidate = ContentTypeGraphQLResponse
+		case ContentTypeJSON, "application/*", "*/*":
+			candidate = ContentTypeJSON
+		default:
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > bestQ {
+			best, bestQ = candidate, q
+		}
+	}
+	return best, best != ""
+}
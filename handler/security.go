@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultCSRFHeaders are the headers exempting a request from CSRF
+// prevention by default. Browsers preflight cross-origin requests with
+// any of them, so a page of another origin can't send them unless CORS
+// allows it.
+var DefaultCSRFHeaders = []string{"GraphQL-Require-Preflight", "Apollo-Require-Preflight", "X-Apollo-Operation-Name"}
+
+// checkCSRF rejects, when CSRF prevention is enabled, the requests a
+// browser would send from any page without a preflight: GET requests and
+// POST requests with a simple content type (or none), unless they carry
+// one of the CSRF headers.
+func (h *Handler) checkCSRF(r *http.Request) error {
+	if !h.opts.CSRFPrevention {
+		return nil
+	}
+	if r.Method == http.MethodPost {
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err == nil && !isSimpleContentType(mediaType) {
+			return nil
+		}
+	}
+	for _, header := range h.opts.CSRFHeaders {
+		if r.Header.Get(header) != "" {
+			return nil
+		}
+	}
+	return badRequest("This request has been blocked as a potential Cross-Site Request Forgery. "+
+		"Send it with a Content-Type of application/json, or with a non-empty %s header.",
+		strings.Join(h.opts.CSRFHeaders, " or "))
+}
+
+// isSimpleContentType reports whether browsers send a request with the
+// content type mediaType without a CORS preflight.
+func isSimpleContentType(mediaType string) bool {
+	switch mediaType {
+	case "text/plain", "application/x-www-form-urlencoded", "multipart/form-data":
+		return true
+	}
+	return false
+}
+
+// CORSOptions configures the CORS headers of a Handler.
+type CORSOptions struct {
+	// AllowedOrigins are the origins, such as "https://example.com",
+	// allowed to read responses; "*" allows any.
+	AllowedOrigins []string
+
+	// AllowCredentials lets browsers send cookies and authentication with
+	// cross-origin requests.
+	AllowCredentials bool
+
+	// AllowedHeaders are the request headers cross-origin requests may
+	// set; the default is Accept, Authorization, Content-Type and the
+	// CSRF headers.
+	AllowedHeaders []string
+
+	// ExposedHeaders are the response headers cross-origin requests may
+	// read, besides the CORS-safelisted ones.
+	ExposedHeaders []string
+
+	// MaxAge is how long browsers may cache the answer to a preflight
+	// request; 0 leaves it to the browser.
+	MaxAge time.Duration
+}
+
+// serveCORS adds the CORS headers for the request's origin, and answers
+// it if it is a preflight request, which it then reports.
+func (h *Handler) serveCORS(w http.ResponseWriter, r *http.Request) bool {
+	cors := h.opts.CORS
+	origin := r.Header.Get("Origin")
+	header := w.Header()
+	header.Add("Vary", "Origin")
+	preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+	if preflight {
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+	}
+	allowed, wildcard := false, false
+	for _, o := range cors.AllowedOrigins {
+		if o == "*" {
+			allowed, wildcard = true, true
+		} else if origin != "" && strings.EqualFold(o, origin) {
+			allowed = true
+		}
+	}
+	if origin == "" || !allowed {
+		if preflight {
+			w.WriteHeader(http.StatusForbidden)
+		}
+		return preflight
+	}
+	if wildcard && !cors.AllowCredentials {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+	if cors.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if !preflight {
+		if len(cors.ExposedHeaders) > 0 {
+			header.Set("Access-Control-Expose-Headers", strings.Join(cors.ExposedHeaders, ", "))
+		}
+		return false
+	}
+	allowedHeaders := cors.AllowedHeaders
+	if allowedHeaders == nil {
+		allowedHeaders = append([]string{"Accept", "Authorization", "Content-Type"}, h.opts.CSRFHeaders...)
+	}
+	header.Set("Access-Control-Allow-Methods", "GET, POST")
+	header.Set("Access-Control-Allow-Headers", strings.Join(allowedHeaders, ", "))
+	if cors.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(cors.MaxAge/time.Second)))
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
@@ -0,0 +1,217 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql/handler"
+)
+
+func TestHandler_CSRFPrevention(t *testing.T) {
+	query := url.Values{"query": {"{ hello }"}}
+	tests := []struct {
+		name   string
+		req    func() *http.Request
+		status int
+	}{
+		{
+			name:   "JSON POST",
+			req:    func() *http.Request { return post(`{"query":"{ hello }"}`, "") },
+			status: http.StatusOK,
+		},
+		{
+			name:   "GET",
+			req:    func() *http.Request { return get(query, "") },
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "GET with a preflight header",
+			req: func() *http.Request {
+				r := get(query, "")
+				r.Header.Set("GraphQL-Require-Preflight", "1")
+				return r
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "text/plain POST",
+			req: func() *http.Request {
+				r := post(`{"query":"{ hello }"}`, "")
+				r.Header.Set("Content-Type", "text/plain")
+				return r
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "multipart POST",
+			req: func() *http.Request {
+				return multipartRequest(t,
+					part{field: "operations", content: `{"query":"{ hello }"}`},
+					part{field: "map", content: `{}`},
+				)
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "POST without a content type",
+			req: func() *http.Request {
+				r := post(`{"query":"{ hello }"}`, "")
+				r.Header.Del("Content-Type")
+				return r
+			},
+			status: http.StatusBadRequest,
+		},
+	}
+	h := handler.New(testSchema(t), handler.Options{CSRFPrevention: true, MaxUploadSize: 1 << 20})
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := serve(t, h, test.req())
+			if res.status != test.status {
+				t.Fatalf("expected %d, got %d (%v)", test.status, res.status, res.body)
+			}
+		})
+	}
+
+	// without CSRF prevention, GET requests go through
+	res := serve(t, handler.New(testSchema(t), handler.Options{}), get(query, ""))
+	if res.status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.status)
+	}
+}
+
+func TestHandler_CustomCSRFHeaders(t *testing.T) {
+	h := handler.New(testSchema(t), handler.Options{CSRFPrevention: true, CSRFHeaders: []string{"X-Requested-With"}})
+	r := get(url.Values{"query": {"{ hello }"}}, "")
+	r.Header.Set("GraphQL-Require-Preflight", "1")
+	if res := serve(t, h, r); res.status != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", res.status)
+	}
+	r.Header.Set("X-Requested-With", "XMLHttpRequest")
+	if res := serve(t, h, r); res.status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.status)
+	}
+}
+
+func TestHandler_CORS(t *testing.T) {
+	h := handler.New(testSchema(t), handler.Options{
+		CSRFPrevention: true,
+		CORS: &handler.CORSOptions{
+			AllowedOrigins:   []string{"https://app.example.com"},
+			AllowCredentials: true,
+			ExposedHeaders:   []string{"X-Request-Id"},
+			MaxAge:           10 * time.Minute,
+		},
+	})
+
+	preflight := func(origin string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodOptions, "/graphql", nil)
+		r.Header.Set("Origin", origin)
+		r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		r.Header.Set("Access-Control-Request-Headers", "content-type")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+	rec := preflight("https://app.example.com")
+	expected := map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "GET, POST",
+		"Access-Control-Max-Age":           "600",
+	}
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	for name, value := range expected {
+		if got := rec.Header().Get(name); got != value {
+			t.Fatalf("%s: expected %q, got %q", name, value, got)
+		}
+	}
+	if allowed := rec.Header().Get("Access-Control-Allow-Headers"); !strings.Contains(allowed, "Content-Type") || !strings.Contains(allowed, "GraphQL-Require-Preflight") {
+		t.Fatalf("unexpected Access-Control-Allow-Headers %q", allowed)
+	}
+
+	rec = preflight("https://evil.example.com")
+	if rec.Code != http.StatusForbidden || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("expected a bare 403, got %d %v", rec.Code, rec.Header())
+	}
+
+	r := post(`{"query":"{ hello }"}`, "")
+	r.Header.Set("Origin", "https://app.example.com")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Fatalf("expected the origin to be allowed, got %q", got)
+	}
+	if got := rec.Header().Get("Access-Control-Expose-Headers"); got != "X-Request-Id" {
+		t.Fatalf("unexpected Access-Control-Expose-Headers %q", got)
+	}
+	if got := rec.Header().Get("Vary"); got != "Origin" {
+		t.Fatalf("expected Vary: Origin, got %q", got)
+	}
+}
+
+func TestHandler_CORSWildcard(t *testing.T) {
+	h := handler.New(testSchema(t), handler.Options{
+		CORS: &handler.CORSOptions{AllowedOrigins: []string{"*"}},
+	})
+	r := post(`{"query":"{ hello }"}`, "")
+	r.Header.Set("Origin", "https://anywhere.example.com")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Fatalf("expected *, got %q", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Fatalf("expected no credentials, got %q", got)
+	}
+}
+
+func TestHandler_DisableIntrospection(t *testing.T) {
+	opts := handler.Options{
+		DisableIntrospection: true,
+		TrustRequest: func(r *http.Request) bool {
+			return r.Header.Get("Authorization") == "Bearer admin"
+		},
+	}
+	for name, h := range handlers(t, opts) {
+		t.Run(name, func(t *testing.T) {
+			for _, query := range []string{
+				`{ __schema { queryType { name } } }`,
+				`{ __type(name: \"Query\") { name } }`,
+				`{ ...F } fragment F on Query { hello __schema { types { name } } }`,
+			} {
+				res := serve(t, h, post(`{"query":"`+query+`"}`, handler.ContentTypeGraphQLResponse))
+				if res.status != http.StatusBadRequest {
+					t.Fatalf("%s: expected 400, got %d (%v)", query, res.status, res.body)
+				}
+				errs, _ := res.body["errors"].([]interface{})
+				if len(errs) != 1 {
+					t.Fatalf("%s: expected one error, got %v", query, res.body)
+				}
+				if _, ok := errs[0].(map[string]interface{})["locations"]; !ok {
+					t.Fatalf("%s: expected a located error, got %v", query, errs[0])
+				}
+			}
+
+			res := serve(t, h, post(`{"query":"{ __typename hello }"}`, ""))
+			if res.status != http.StatusOK || res.body["data"] == nil {
+				t.Fatalf("expected __typename to be allowed, got %d %v", res.status, res.body)
+			}
+
+			r := post(`{"query":"{ __schema { queryType { name } } }"}`, "")
+			r.Header.Set("Authorization", "Bearer admin")
+			res = serve(t, h, r)
+			if res.status != http.StatusOK || res.body["errors"] != nil {
+				t.Fatalf("expected trusted requests to introspect, got %d %v", res.status, res.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+const defaultUploadMemory = 10 << 20
+
+// readMultipart decodes a request following the GraphQL multipart request
+// specification: an `operations` field holding the request (or a batch of
+// them) with null placeholders for the files, a `map` field mapping each
+// file part to the placeholders it fills, and the file parts. The files are
+// substituted into the variables as *graphql.UploadFile, before variable
+// coercion, so they reach resolvers through Upload arguments.
+//
+// Parts past UploadMemory are spooled to temporary files; ServeHTTP
+// removes them once the response is written.
+func (h *Handler) readMultipart(w http.ResponseWriter, r *http.Request) (reqs []graphql.Request, batch bool, err error) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.opts.MaxUploadSize)
+	if err := r.ParseMultipartForm(h.opts.UploadMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, false, &httpError{
+				status: http.StatusRequestEntityTooLarge,
+				err:    fmt.Errorf("Request body exceeds %d bytes.", h.opts.MaxUploadSize),
+			}
+		}
+		return nil, false, badRequest("Invalid multipart request: %v", err)
+	}
+	form := r.MultipartForm
+	if len(form.Value["operations"]) != 1 {
+		return nil, false, badRequest(`Multipart requests must have one "operations" field.`)
+	}
+	var operations interface{}
+	if err := json.Unmarshal([]byte(form.Value["operations"][0]), &operations); err != nil {
+		return nil, false, badRequest(`The "operations" field is invalid JSON: %v`, err)
+	}
+	var fileMap map[string][]string
+	if len(form.Value["map"]) != 1 {
+		return nil, false, badRequest(`Multipart requests must have one "map" field.`)
+	}
+	if err := json.Unmarshal([]byte(form.Value["map"][0]), &fileMap); err != nil {
+		return nil, false, badRequest(`The "map" field is invalid: %v`, err)
+	}
+	_, batch = operations.([]interface{})
+	var opened []multipart.File
+	defer func() {
+		// on success the files are closed with the request
+		if err != nil {
+			for _, file := range opened {
+				file.Close()
+			}
+		}
+	}()
+	for key, paths := range fileMap {
+		if len(form.File[key]) == 0 {
+			return nil, false, badRequest("File %q is missing from the request.", key)
+		}
+		header := form.File[key][0]
+		file, err := header.Open()
+		if err != nil {
+			return nil, false, badRequest("File %q can't be read: %v", key, err)
+		}
+		opened = append(opened, file)
+		upload := &graphql.UploadFile{
+			Filename:    header.Filename,
+			ContentType: header.Header.Get("Content-Type"),
+			Size:        header.Size,
+			File:        file,
+		}
+		for _, path := range paths {
+			if err := setUpload(operations, batch, path, upload); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
+	if !batch {
+		op, ok := operations.(map[string]interface{})
+		if !ok {
+			return nil, false, badRequest(`The "operations" field must be an object or an array.`)
+		}
+		req, err := requestFromMap(op)
+		if err != nil {
+			return nil, false, err
+		}
+		return []graphql.Request{req}, false, nil
+	}
+	ops := operations.([]interface{})
+	if err := h.checkBatchSize(len(ops)); err != nil {
+		return nil, false, err
+	}
+	reqs = make([]graphql.Request, len(ops))
+	for i, op := range ops {
+		m, ok := op.(map[string]interface{})
+		if !ok {
+			return nil, false, badRequest("Operation %d must be an object.", i)
+		}
+		req, err := requestFromMap(m)
+		if err != nil {
+			return nil, false, err
+		}
+		reqs[i] = req
+	}
+	return reqs, true, nil
+}
+
+// setUpload replaces the null placeholder at path, e.g. `variables.file`
+// or, in a batch, `0.variables.files.1`, with upload. Only variables may
+// receive files.
+func setUpload(operations interface{}, batch bool, path string, upload *graphql.UploadFile) error {
+	invalid := badRequest("Invalid file path %q.", path)
+	segments := strings.Split(path, ".")
+	variables := 0
+	if batch {
+		variables = 1
+	}
+	if len(segments) < variables+2 || segments[variables] != "variables" {
+		return invalid
+	}
+	parent := operations
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			value, ok := node[segment]
+			if !ok {
+				return invalid
+			}
+			if last {
+				if value != nil {
+					return invalid
+				}
+				node[segment] = upload
+				return nil
+			}
+			parent = value
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return invalid
+			}
+			if last {
+				if node[index] != nil {
+					return invalid
+				}
+				node[index] = upload
+				return nil
+			}
+			parent = node[index]
+		default:
+			return invalid
+		}
+	}
+	return invalid
+}
+
+// requestFromMap converts a decoded request object. It can't go through
+// encoding/json again, which would lose the substituted files.
+func requestFromMap(m map[string]interface{}) (graphql.Request, error) {
+	var req graphql.Request
+	var ok bool
+	if req.Query, ok = m["query"].(string); !ok && m["query"] != nil {
+		return req, badRequest(`The "query" of an operation must be a string.`)
+	}
+	if req.DocumentID, ok = m["documentId"].(string); !ok && m["documentId"] != nil {
+		return req, badRequest(`The "documentId" of an operation must be a string.`)
+	}
+	if req.OperationName, ok = m["operationName"].(string); !ok && m["operationName"] != nil {
+		return req, badRequest(`The "operationName" of an operation must be a string.`)
+	}
+	if req.Variables, ok = m["variables"].(map[string]interface{}); !ok && m["variables"] != nil {
+		return req, badRequest(`The "variables" of an operation must be an object.`)
+	}
+	if req.Extensions, ok = m["extensions"].(map[string]interface{}); !ok && m["extensions"] != nil {
+		return req, badRequest(`The "extensions" of an operation must be an object.`)
+	}
+	return req, nil
+}
+
+// closeUploads closes the files substituted into reqs and removes the
+// temporary files of form.
+func closeUploads(form *multipart.Form, reqs []graphql.Request) {
+	if form == nil {
+		return
+	}
+	for _, req := range reqs {
+		closeUploadValues(req.Variables)
+	}
+	form.RemoveAll()
+}
+
+func closeUploadValues(value interface{}) {
+	switch value := value.(type) {
+	case *graphql.UploadFile:
+		value.File.Close()
+	case map[string]interface{}:
+		for _, v := range value {
+			closeUploadValues(v)
+		}
+	case []interface{}:
+		for _, v := range value {
+			closeUploadValues(v)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package handler_test
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/handler"
+)
+
+func uploadSchema(t *testing.T) *graphql.Schema {
+	read := func(upload *graphql.UploadFile) (string, error) {
+		content, err := io.ReadAll(upload.File)
+		return upload.Filename + " (" + upload.ContentType + "): " + string(content), err
+	}
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name:   "Query",
+			Fields: graphql.Fields{"ok": &graphql.Field{Type: graphql.Boolean}},
+		}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Mutation",
+			Fields: graphql.Fields{
+				"upload": &graphql.Field{
+					Type: graphql.String,
+					Args: graphql.FieldConfigArgument{
+						"file": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Upload)},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return read(p.Args["file"].(*graphql.UploadFile))
+					},
+				},
+				"uploadMany": &graphql.Field{
+					Type: graphql.NewList(graphql.String),
+					Args: graphql.FieldConfigArgument{
+						"files": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.Upload)},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						var out []interface{}
+						for _, f := range p.Args["files"].([]interface{}) {
+							s, err := read(f.(*graphql.UploadFile))
+							if err != nil {
+								return nil, err
+							}
+							out = append(out, s)
+						}
+						return out, nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &schema
+}
+
+type part struct {
+	field, filename, content string
+}
+
+func multipartRequest(t *testing.T, parts ...part) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, p := range parts {
+		if p.filename == "" {
+			mw.WriteField(p.field, p.content)
+			continue
+		}
+		fw, err := mw.CreateFormFile(p.field, p.filename)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		io.WriteString(fw, p.content)
+	}
+	mw.Close()
+	r := httptest.NewRequest(http.MethodPost, "/graphql", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+func TestHandler_Uploads(t *testing.T) {
+	schema := uploadSchema(t)
+	for _, uploadMemory := range []int64{0, 1} {
+		// UploadMemory 1 spools every file to disk
+		h := handler.New(schema, handler.Options{MaxUploadSize: 1 << 20, UploadMemory: uploadMemory, MaxBatchSize: 2})
+
+		res := serve(t, h, multipartRequest(t,
+			part{field: "operations", content: `{"query":"mutation ($file: Upload!) { upload(file: $file) }","variables":{"file":null}}`},
+			part{field: "map", content: `{"0":["variables.file"]}`},
+			part{field: "0", filename: "a.txt", content: "alpha"},
+		))
+		expected := map[string]interface{}{
+			"data": map[string]interface{}{"upload": "a.txt (application/octet-stream): alpha"},
+		}
+		if res.status != http.StatusOK || !reflect.DeepEqual(res.body, expected) {
+			t.Fatalf("expected %v, got %d %v", expected, res.status, res.body)
+		}
+
+		res = serve(t, h, multipartRequest(t,
+			part{field: "operations", content: `{"query":"mutation ($files: [Upload]) { uploadMany(files: $files) }","variables":{"files":[null,null]}}`},
+			part{field: "map", content: `{"0":["variables.files.0"],"1":["variables.files.1"]}`},
+			part{field: "0", filename: "a.txt", content: "alpha"},
+			part{field: "1", filename: "b.txt", content: "beta"},
+		))
+		expected = map[string]interface{}{
+			"data": map[string]interface{}{"uploadMany": []interface{}{
+				"a.txt (application/octet-stream): alpha",
+				"b.txt (application/octet-stream): beta",
+			}},
+		}
+		if res.status != http.StatusOK || !reflect.DeepEqual(res.body, expected) {
+			t.Fatalf("expected %v, got %d %v", expected, res.status, res.body)
+		}
+	}
+}
+
+func TestHandler_UploadsInBatch(t *testing.T) {
+	h := handler.New(uploadSchema(t), handler.Options{MaxUploadSize: 1 << 20, MaxBatchSize: 2})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, multipartRequest(t,
+		part{field: "operations", content: `[
+			{"query":"mutation ($file: Upload!) { upload(file: $file) }","variables":{"file":null}},
+			{"query":"mutation ($file: Upload!) { upload(file: $file) }","variables":{"file":null}}
+		]`},
+		part{field: "map", content: `{"0":["0.variables.file"],"1":["1.variables.file"]}`},
+		part{field: "0", filename: "a.txt", content: "alpha"},
+		part{field: "1", filename: "b.txt", content: "beta"},
+	))
+	expected := `[{"data":{"upload":"a.txt (application/octet-stream): alpha"}},{"data":{"upload":"b.txt (application/octet-stream): beta"}}]`
+	if got := strings.TrimSpace(rec.Body.String()); got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestHandler_UploadErrors(t *testing.T) {
+	operations := part{field: "operations", content: `{"query":"mutation ($file: Upload!) { upload(file: $file) }","variables":{"file":null}}`}
+	file := part{field: "0", filename: "a.txt", content: "alpha"}
+	tests := []struct {
+		name   string
+		opts   handler.Options
+		parts  []part
+		status int
+	}{
+		{
+			name:   "uploads disabled",
+			parts:  []part{operations, {field: "map", content: `{"0":["variables.file"]}`}, file},
+			status: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:   "too large",
+			opts:   handler.Options{MaxUploadSize: 64},
+			parts:  []part{operations, {field: "map", content: `{"0":["variables.file"]}`}, file},
+			status: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:   "missing map",
+			parts:  []part{operations, file},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "missing file",
+			parts:  []part{operations, {field: "map", content: `{"1":["variables.file"]}`}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "path outside variables",
+			parts:  []part{operations, {field: "map", content: `{"0":["query"]}`}, file},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "path to a non-null value",
+			parts:  []part{{field: "operations", content: `{"query":"{ ok }","variables":{"file":"x"}}`}, {field: "map", content: `{"0":["variables.file"]}`}, file},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "batch without batching",
+			parts:  []part{{field: "operations", content: `[{"query":"{ ok }"}]`}, {field: "map", content: `{}`}},
+			status: http.StatusBadRequest,
+		},
+	}
+	schema := uploadSchema(t)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := test.opts
+			if opts.MaxUploadSize == 0 && test.status != http.StatusUnsupportedMediaType {
+				opts.MaxUploadSize = 1 << 20
+			}
+			res := serve(t, handler.New(schema, opts), multipartRequest(t, test.parts...))
+			if res.status != test.status {
+				t.Fatalf("expected %d, got %d (%v)", test.status, res.status, res.body)
+			}
+		})
+	}
+}
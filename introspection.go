@@ -136,6 +136,10 @@ func init() {
 				Value:       DirectiveLocationInlineFragment,
 				Description: "Location adjacent to an inline fragment.",
 			},
+			"VARIABLE_DEFINITION": &EnumValueConfig{
+				Value:       DirectiveLocationVariableDefinition,
+				Description: "Location adjacent to a variable definition.",
+			},
 			"SCHEMA": &EnumValueConfig{
 				Value:       DirectiveLocationSchema,
 				Description: "Location adjacent to a schema definition.",
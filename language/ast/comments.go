@@ -0,0 +1,43 @@
+package ast
+
+import (
+	"github.com/graphql-go/graphql/language/kinds"
+)
+
+// CommentedNode are nodes the parser attaches comments to: definitions,
+// and the items of blocks, i.e. selections, field definitions, enum
+// values, input fields and operation types.
+type CommentedNode interface {
+	Node
+	GetComments() *Comments
+}
+
+// Comments are the comments attached to a node: the comment lines right
+// before it, and the comment ending the line it ends on.
+type Comments struct {
+	Leading  []*Comment
+	Trailing *Comment
+}
+
+// Comment implements Node
+type Comment struct {
+	Kind  string
+	Loc   *Location
+	Value string
+}
+
+func NewComment(c *Comment) *Comment {
+	if c == nil {
+		c = &Comment{}
+	}
+	c.Kind = kinds.Comment
+	return c
+}
+
+func (c *Comment) GetKind() string {
+	return c.Kind
+}
+
+func (c *Comment) GetLoc() *Location {
+	return c.Loc
+}
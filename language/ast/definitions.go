@@ -33,6 +33,7 @@ type OperationDefinition struct {
 	VariableDefinitions []*VariableDefinition
 	Directives          []*Directive
 	SelectionSet        *SelectionSet
+	Comments            *Comments
 }
 
 func NewOperationDefinition(op *OperationDefinition) *OperationDefinition {
@@ -51,6 +52,10 @@ func (op *OperationDefinition) GetLoc() *Location {
 	return op.Loc
 }
 
+func (op *OperationDefinition) GetComments() *Comments {
+	return op.Comments
+}
+
 func (op *OperationDefinition) GetOperation() string {
 	return op.Operation
 }
@@ -81,6 +86,7 @@ type FragmentDefinition struct {
 	TypeCondition       *Named
 	Directives          []*Directive
 	SelectionSet        *SelectionSet
+	Comments            *Comments
 }
 
 func NewFragmentDefinition(fd *FragmentDefinition) *FragmentDefinition {
@@ -96,6 +102,7 @@ func NewFragmentDefinition(fd *FragmentDefinition) *FragmentDefinition {
 		TypeCondition:       fd.TypeCondition,
 		Directives:          fd.Directives,
 		SelectionSet:        fd.SelectionSet,
+		Comments:            fd.Comments,
 	}
 }
 
@@ -107,6 +114,10 @@ func (fd *FragmentDefinition) GetLoc() *Location {
 	return fd.Loc
 }
 
+func (fd *FragmentDefinition) GetComments() *Comments {
+	return fd.Comments
+}
+
 func (fd *FragmentDefinition) GetOperation() string {
 	return fd.Operation
 }
@@ -130,6 +141,7 @@ type VariableDefinition struct {
 	Variable     *Variable
 	Type         Type
 	DefaultValue Value
+	Directives   []*Directive
 }
 
 func NewVariableDefinition(vd *VariableDefinition) *VariableDefinition {
@@ -153,6 +165,7 @@ type TypeExtensionDefinition struct {
 	Kind       string
 	Loc        *Location
 	Definition *ObjectDefinition
+	Comments   *Comments
 }
 
 func NewTypeExtensionDefinition(def *TypeExtensionDefinition) *TypeExtensionDefinition {
@@ -163,6 +176,7 @@ func NewTypeExtensionDefinition(def *TypeExtensionDefinition) *TypeExtensionDefi
 		Kind:       kinds.TypeExtensionDefinition,
 		Loc:        def.Loc,
 		Definition: def.Definition,
+		Comments:   def.Comments,
 	}
 }
 
@@ -174,6 +188,10 @@ func (def *TypeExtensionDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *TypeExtensionDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
 func (def *TypeExtensionDefinition) GetVariableDefinitions() []*VariableDefinition {
 	return []*VariableDefinition{}
 }
@@ -186,6 +204,270 @@ func (def *TypeExtensionDefinition) GetOperation() string {
 	return ""
 }
 
+// SchemaExtensionDefinition implements Node, Definition
+type SchemaExtensionDefinition struct {
+	Kind       string
+	Loc        *Location
+	Definition *SchemaDefinition
+	Comments   *Comments
+}
+
+func NewSchemaExtensionDefinition(def *SchemaExtensionDefinition) *SchemaExtensionDefinition {
+	if def == nil {
+		def = &SchemaExtensionDefinition{}
+	}
+	return &SchemaExtensionDefinition{
+		Kind:       kinds.SchemaExtensionDefinition,
+		Loc:        def.Loc,
+		Definition: def.Definition,
+		Comments:   def.Comments,
+	}
+}
+
+func (def *SchemaExtensionDefinition) GetKind() string {
+	return def.Kind
+}
+
+func (def *SchemaExtensionDefinition) GetLoc() *Location {
+	return def.Loc
+}
+
+func (def *SchemaExtensionDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
+func (def *SchemaExtensionDefinition) GetVariableDefinitions() []*VariableDefinition {
+	return []*VariableDefinition{}
+}
+
+func (def *SchemaExtensionDefinition) GetSelectionSet() *SelectionSet {
+	return &SelectionSet{}
+}
+
+func (def *SchemaExtensionDefinition) GetOperation() string {
+	return ""
+}
+
+// ScalarExtensionDefinition implements Node, Definition
+type ScalarExtensionDefinition struct {
+	Kind       string
+	Loc        *Location
+	Definition *ScalarDefinition
+	Comments   *Comments
+}
+
+func NewScalarExtensionDefinition(def *ScalarExtensionDefinition) *ScalarExtensionDefinition {
+	if def == nil {
+		def = &ScalarExtensionDefinition{}
+	}
+	return &ScalarExtensionDefinition{
+		Kind:       kinds.ScalarExtensionDefinition,
+		Loc:        def.Loc,
+		Definition: def.Definition,
+		Comments:   def.Comments,
+	}
+}
+
+func (def *ScalarExtensionDefinition) GetKind() string {
+	return def.Kind
+}
+
+func (def *ScalarExtensionDefinition) GetLoc() *Location {
+	return def.Loc
+}
+
+func (def *ScalarExtensionDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
+func (def *ScalarExtensionDefinition) GetVariableDefinitions() []*VariableDefinition {
+	return []*VariableDefinition{}
+}
+
+func (def *ScalarExtensionDefinition) GetSelectionSet() *SelectionSet {
+	return &SelectionSet{}
+}
+
+func (def *ScalarExtensionDefinition) GetOperation() string {
+	return ""
+}
+
+// InterfaceExtensionDefinition implements Node, Definition
+type InterfaceExtensionDefinition struct {
+	Kind       string
+	Loc        *Location
+	Definition *InterfaceDefinition
+	Comments   *Comments
+}
+
+func NewInterfaceExtensionDefinition(def *InterfaceExtensionDefinition) *InterfaceExtensionDefinition {
+	if def == nil {
+		def = &InterfaceExtensionDefinition{}
+	}
+	return &InterfaceExtensionDefinition{
+		Kind:       kinds.InterfaceExtensionDefinition,
+		Loc:        def.Loc,
+		Definition: def.Definition,
+		Comments:   def.Comments,
+	}
+}
+
+func (def *InterfaceExtensionDefinition) GetKind() string {
+	return def.Kind
+}
+
+func (def *InterfaceExtensionDefinition) GetLoc() *Location {
+	return def.Loc
+}
+
+func (def *InterfaceExtensionDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
+func (def *InterfaceExtensionDefinition) GetVariableDefinitions() []*VariableDefinition {
+	return []*VariableDefinition{}
+}
+
+func (def *InterfaceExtensionDefinition) GetSelectionSet() *SelectionSet {
+	return &SelectionSet{}
+}
+
+func (def *InterfaceExtensionDefinition) GetOperation() string {
+	return ""
+}
+
+// UnionExtensionDefinition implements Node, Definition
+type UnionExtensionDefinition struct {
+	Kind       string
+	Loc        *Location
+	Definition *UnionDefinition
+	Comments   *Comments
+}
+
+func NewUnionExtensionDefinition(def *UnionExtensionDefinition) *UnionExtensionDefinition {
+	if def == nil {
+		def = &UnionExtensionDefinition{}
+	}
+	return &UnionExtensionDefinition{
+		Kind:       kinds.UnionExtensionDefinition,
+		Loc:        def.Loc,
+		Definition: def.Definition,
+		Comments:   def.Comments,
+	}
+}
+
+func (def *UnionExtensionDefinition) GetKind() string {
+	return def.Kind
+}
+
+func (def *UnionExtensionDefinition) GetLoc() *Location {
+	return def.Loc
+}
+
+func (def *UnionExtensionDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
+func (def *UnionExtensionDefinition) GetVariableDefinitions() []*VariableDefinition {
+	return []*VariableDefinition{}
+}
+
+func (def *UnionExtensionDefinition) GetSelectionSet() *SelectionSet {
+	return &SelectionSet{}
+}
+
+func (def *UnionExtensionDefinition) GetOperation() string {
+	return ""
+}
+
+// EnumExtensionDefinition implements Node, Definition
+type EnumExtensionDefinition struct {
+	Kind       string
+	Loc        *Location
+	Definition *EnumDefinition
+	Comments   *Comments
+}
+
+func NewEnumExtensionDefinition(def *EnumExtensionDefinition) *EnumExtensionDefinition {
+	if def == nil {
+		def = &EnumExtensionDefinition{}
+	}
+	return &EnumExtensionDefinition{
+		Kind:       kinds.EnumExtensionDefinition,
+		Loc:        def.Loc,
+		Definition: def.Definition,
+		Comments:   def.Comments,
+	}
+}
+
+func (def *EnumExtensionDefinition) GetKind() string {
+	return def.Kind
+}
+
+func (def *EnumExtensionDefinition) GetLoc() *Location {
+	return def.Loc
+}
+
+func (def *EnumExtensionDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
+func (def *EnumExtensionDefinition) GetVariableDefinitions() []*VariableDefinition {
+	return []*VariableDefinition{}
+}
+
+func (def *EnumExtensionDefinition) GetSelectionSet() *SelectionSet {
+	return &SelectionSet{}
+}
+
+func (def *EnumExtensionDefinition) GetOperation() string {
+	return ""
+}
+
+// InputObjectExtensionDefinition implements Node, Definition
+type InputObjectExtensionDefinition struct {
+	Kind       string
+	Loc        *Location
+	Definition *InputObjectDefinition
+	Comments   *Comments
+}
+
+func NewInputObjectExtensionDefinition(def *InputObjectExtensionDefinition) *InputObjectExtensionDefinition {
+	if def == nil {
+		def = &InputObjectExtensionDefinition{}
+	}
+	return &InputObjectExtensionDefinition{
+		Kind:       kinds.InputObjectExtensionDefinition,
+		Loc:        def.Loc,
+		Definition: def.Definition,
+		Comments:   def.Comments,
+	}
+}
+
+func (def *InputObjectExtensionDefinition) GetKind() string {
+	return def.Kind
+}
+
+func (def *InputObjectExtensionDefinition) GetLoc() *Location {
+	return def.Loc
+}
+
+func (def *InputObjectExtensionDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
+func (def *InputObjectExtensionDefinition) GetVariableDefinitions() []*VariableDefinition {
+	return []*VariableDefinition{}
+}
+
+func (def *InputObjectExtensionDefinition) GetSelectionSet() *SelectionSet {
+	return &SelectionSet{}
+}
+
+func (def *InputObjectExtensionDefinition) GetOperation() string {
+	return ""
+}
+
 // DirectiveDefinition implements Node, Definition
 type DirectiveDefinition struct {
 	Kind        string
@@ -194,6 +476,7 @@ type DirectiveDefinition struct {
 	Description *StringValue
 	Arguments   []*InputValueDefinition
 	Locations   []*Name
+	Comments    *Comments
 }
 
 func NewDirectiveDefinition(def *DirectiveDefinition) *DirectiveDefinition {
@@ -207,6 +490,7 @@ func NewDirectiveDefinition(def *DirectiveDefinition) *DirectiveDefinition {
 		Description: def.Description,
 		Arguments:   def.Arguments,
 		Locations:   def.Locations,
+		Comments:    def.Comments,
 	}
 }
 
@@ -218,6 +502,10 @@ func (def *DirectiveDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *DirectiveDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
 func (def *DirectiveDefinition) GetVariableDefinitions() []*VariableDefinition {
 	return []*VariableDefinition{}
 }
@@ -9,6 +9,10 @@ type Document struct {
 	Kind        string
 	Loc         *Location
 	Definitions []Node
+
+	// Comments are all the comments of the document, in order, when it
+	// was parsed with comments.
+	Comments []*Comment
 }
 
 func NewDocument(d *Document) *Document {
@@ -19,6 +23,7 @@ func NewDocument(d *Document) *Document {
 		Kind:        kinds.Document,
 		Loc:         d.Loc,
 		Definitions: d.Definitions,
+		Comments:    d.Comments,
 	}
 }
 
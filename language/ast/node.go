@@ -42,4 +42,38 @@ var _ Node = (*EnumDefinition)(nil)
 var _ Node = (*EnumValueDefinition)(nil)
 var _ Node = (*InputObjectDefinition)(nil)
 var _ Node = (*TypeExtensionDefinition)(nil)
+var _ Node = (*SchemaExtensionDefinition)(nil)
+var _ Node = (*ScalarExtensionDefinition)(nil)
+var _ Node = (*InterfaceExtensionDefinition)(nil)
+var _ Node = (*UnionExtensionDefinition)(nil)
+var _ Node = (*EnumExtensionDefinition)(nil)
+var _ Node = (*InputObjectExtensionDefinition)(nil)
 var _ Node = (*DirectiveDefinition)(nil)
+var _ Node = (*Comment)(nil)
+
+// Ensure that the nodes the parser attaches comments to implement
+// CommentedNode
+var _ CommentedNode = (*OperationDefinition)(nil)
+var _ CommentedNode = (*FragmentDefinition)(nil)
+var _ CommentedNode = (*Field)(nil)
+var _ CommentedNode = (*FragmentSpread)(nil)
+var _ CommentedNode = (*InlineFragment)(nil)
+var _ CommentedNode = (*SchemaDefinition)(nil)
+var _ CommentedNode = (*OperationTypeDefinition)(nil)
+var _ CommentedNode = (*ScalarDefinition)(nil)
+var _ CommentedNode = (*ObjectDefinition)(nil)
+var _ CommentedNode = (*FieldDefinition)(nil)
+var _ CommentedNode = (*InputValueDefinition)(nil)
+var _ CommentedNode = (*InterfaceDefinition)(nil)
+var _ CommentedNode = (*UnionDefinition)(nil)
+var _ CommentedNode = (*EnumDefinition)(nil)
+var _ CommentedNode = (*EnumValueDefinition)(nil)
+var _ CommentedNode = (*InputObjectDefinition)(nil)
+var _ CommentedNode = (*TypeExtensionDefinition)(nil)
+var _ CommentedNode = (*SchemaExtensionDefinition)(nil)
+var _ CommentedNode = (*ScalarExtensionDefinition)(nil)
+var _ CommentedNode = (*InterfaceExtensionDefinition)(nil)
+var _ CommentedNode = (*UnionExtensionDefinition)(nil)
+var _ CommentedNode = (*EnumExtensionDefinition)(nil)
+var _ CommentedNode = (*InputObjectExtensionDefinition)(nil)
+var _ CommentedNode = (*DirectiveDefinition)(nil)
@@ -22,6 +22,7 @@ type Field struct {
 	Arguments    []*Argument
 	Directives   []*Directive
 	SelectionSet *SelectionSet
+	Comments     *Comments
 }
 
 func NewField(f *Field) *Field {
@@ -40,6 +41,10 @@ func (f *Field) GetLoc() *Location {
 	return f.Loc
 }
 
+func (f *Field) GetComments() *Comments {
+	return f.Comments
+}
+
 func (f *Field) GetSelectionSet() *SelectionSet {
 	return f.SelectionSet
 }
@@ -50,6 +55,7 @@ type FragmentSpread struct {
 	Loc        *Location
 	Name       *Name
 	Directives []*Directive
+	Comments   *Comments
 }
 
 func NewFragmentSpread(fs *FragmentSpread) *FragmentSpread {
@@ -61,6 +67,7 @@ func NewFragmentSpread(fs *FragmentSpread) *FragmentSpread {
 		Loc:        fs.Loc,
 		Name:       fs.Name,
 		Directives: fs.Directives,
+		Comments:   fs.Comments,
 	}
 }
 
@@ -72,6 +79,10 @@ func (fs *FragmentSpread) GetLoc() *Location {
 	return fs.Loc
 }
 
+func (fs *FragmentSpread) GetComments() *Comments {
+	return fs.Comments
+}
+
 func (fs *FragmentSpread) GetSelectionSet() *SelectionSet {
 	return nil
 }
@@ -83,6 +94,7 @@ type InlineFragment struct {
 	TypeCondition *Named
 	Directives    []*Directive
 	SelectionSet  *SelectionSet
+	Comments      *Comments
 }
 
 func NewInlineFragment(f *InlineFragment) *InlineFragment {
@@ -95,6 +107,7 @@ func NewInlineFragment(f *InlineFragment) *InlineFragment {
 		TypeCondition: f.TypeCondition,
 		Directives:    f.Directives,
 		SelectionSet:  f.SelectionSet,
+		Comments:      f.Comments,
 	}
 }
 
@@ -106,6 +119,10 @@ func (f *InlineFragment) GetLoc() *Location {
 	return f.Loc
 }
 
+func (f *InlineFragment) GetComments() *Comments {
+	return f.Comments
+}
+
 func (f *InlineFragment) GetSelectionSet() *SelectionSet {
 	return f.SelectionSet
 }
@@ -36,6 +36,12 @@ type TypeSystemDefinition interface {
 var _ TypeSystemDefinition = (*SchemaDefinition)(nil)
 var _ TypeSystemDefinition = (TypeDefinition)(nil)
 var _ TypeSystemDefinition = (*TypeExtensionDefinition)(nil)
+var _ TypeSystemDefinition = (*SchemaExtensionDefinition)(nil)
+var _ TypeSystemDefinition = (*ScalarExtensionDefinition)(nil)
+var _ TypeSystemDefinition = (*InterfaceExtensionDefinition)(nil)
+var _ TypeSystemDefinition = (*UnionExtensionDefinition)(nil)
+var _ TypeSystemDefinition = (*EnumExtensionDefinition)(nil)
+var _ TypeSystemDefinition = (*InputObjectExtensionDefinition)(nil)
 var _ TypeSystemDefinition = (*DirectiveDefinition)(nil)
 
 // SchemaDefinition implements Node, Definition
@@ -44,6 +50,7 @@ type SchemaDefinition struct {
 	Loc            *Location
 	Directives     []*Directive
 	OperationTypes []*OperationTypeDefinition
+	Comments       *Comments
 }
 
 func NewSchemaDefinition(def *SchemaDefinition) *SchemaDefinition {
@@ -55,6 +62,7 @@ func NewSchemaDefinition(def *SchemaDefinition) *SchemaDefinition {
 		Loc:            def.Loc,
 		Directives:     def.Directives,
 		OperationTypes: def.OperationTypes,
+		Comments:       def.Comments,
 	}
 }
 
@@ -66,6 +74,10 @@ func (def *SchemaDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *SchemaDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
 func (def *SchemaDefinition) GetVariableDefinitions() []*VariableDefinition {
 	return []*VariableDefinition{}
 }
@@ -84,6 +96,7 @@ type OperationTypeDefinition struct {
 	Loc       *Location
 	Operation string
 	Type      *Named
+	Comments  *Comments
 }
 
 func NewOperationTypeDefinition(def *OperationTypeDefinition) *OperationTypeDefinition {
@@ -95,6 +108,7 @@ func NewOperationTypeDefinition(def *OperationTypeDefinition) *OperationTypeDefi
 		Loc:       def.Loc,
 		Operation: def.Operation,
 		Type:      def.Type,
+		Comments:  def.Comments,
 	}
 }
 
@@ -106,6 +120,10 @@ func (def *OperationTypeDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *OperationTypeDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
 // ScalarDefinition implements Node, Definition
 type ScalarDefinition struct {
 	Kind        string
@@ -113,6 +131,7 @@ type ScalarDefinition struct {
 	Description *StringValue
 	Name        *Name
 	Directives  []*Directive
+	Comments    *Comments
 }
 
 func NewScalarDefinition(def *ScalarDefinition) *ScalarDefinition {
@@ -125,6 +144,7 @@ func NewScalarDefinition(def *ScalarDefinition) *ScalarDefinition {
 		Description: def.Description,
 		Name:        def.Name,
 		Directives:  def.Directives,
+		Comments:    def.Comments,
 	}
 }
 
@@ -136,6 +156,10 @@ func (def *ScalarDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *ScalarDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
 func (def *ScalarDefinition) GetName() *Name {
 	return def.Name
 }
@@ -165,6 +189,7 @@ type ObjectDefinition struct {
 	Interfaces  []*Named
 	Directives  []*Directive
 	Fields      []*FieldDefinition
+	Comments    *Comments
 }
 
 func NewObjectDefinition(def *ObjectDefinition) *ObjectDefinition {
@@ -179,6 +204,7 @@ func NewObjectDefinition(def *ObjectDefinition) *ObjectDefinition {
 		Interfaces:  def.Interfaces,
 		Directives:  def.Directives,
 		Fields:      def.Fields,
+		Comments:    def.Comments,
 	}
 }
 
@@ -190,6 +216,10 @@ func (def *ObjectDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *ObjectDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
 func (def *ObjectDefinition) GetName() *Name {
 	return def.Name
 }
@@ -219,6 +249,7 @@ type FieldDefinition struct {
 	Arguments   []*InputValueDefinition
 	Type        Type
 	Directives  []*Directive
+	Comments    *Comments
 }
 
 func NewFieldDefinition(def *FieldDefinition) *FieldDefinition {
@@ -233,6 +264,7 @@ func NewFieldDefinition(def *FieldDefinition) *FieldDefinition {
 		Arguments:   def.Arguments,
 		Type:        def.Type,
 		Directives:  def.Directives,
+		Comments:    def.Comments,
 	}
 }
 
@@ -244,6 +276,10 @@ func (def *FieldDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *FieldDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
 func (def *FieldDefinition) GetDescription() *StringValue {
 	return def.Description
 }
@@ -257,6 +293,7 @@ type InputValueDefinition struct {
 	Type         Type
 	DefaultValue Value
 	Directives   []*Directive
+	Comments     *Comments
 }
 
 func NewInputValueDefinition(def *InputValueDefinition) *InputValueDefinition {
@@ -271,6 +308,7 @@ func NewInputValueDefinition(def *InputValueDefinition) *InputValueDefinition {
 		Type:         def.Type,
 		DefaultValue: def.DefaultValue,
 		Directives:   def.Directives,
+		Comments:     def.Comments,
 	}
 }
 
@@ -282,6 +320,10 @@ func (def *InputValueDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *InputValueDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
 func (def *InputValueDefinition) GetDescription() *StringValue {
 	return def.Description
 }
@@ -294,6 +336,7 @@ type InterfaceDefinition struct {
 	Description *StringValue
 	Directives  []*Directive
 	Fields      []*FieldDefinition
+	Comments    *Comments
 }
 
 func NewInterfaceDefinition(def *InterfaceDefinition) *InterfaceDefinition {
@@ -307,6 +350,7 @@ func NewInterfaceDefinition(def *InterfaceDefinition) *InterfaceDefinition {
 		Description: def.Description,
 		Directives:  def.Directives,
 		Fields:      def.Fields,
+		Comments:    def.Comments,
 	}
 }
 
@@ -318,6 +362,10 @@ func (def *InterfaceDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *InterfaceDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
 func (def *InterfaceDefinition) GetName() *Name {
 	return def.Name
 }
@@ -346,6 +394,7 @@ type UnionDefinition struct {
 	Description *StringValue
 	Directives  []*Directive
 	Types       []*Named
+	Comments    *Comments
 }
 
 func NewUnionDefinition(def *UnionDefinition) *UnionDefinition {
@@ -359,6 +408,7 @@ func NewUnionDefinition(def *UnionDefinition) *UnionDefinition {
 		Description: def.Description,
 		Directives:  def.Directives,
 		Types:       def.Types,
+		Comments:    def.Comments,
 	}
 }
 
@@ -370,6 +420,10 @@ func (def *UnionDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *UnionDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
 func (def *UnionDefinition) GetName() *Name {
 	return def.Name
 }
@@ -398,6 +452,7 @@ type EnumDefinition struct {
 	Description *StringValue
 	Directives  []*Directive
 	Values      []*EnumValueDefinition
+	Comments    *Comments
 }
 
 func NewEnumDefinition(def *EnumDefinition) *EnumDefinition {
@@ -411,6 +466,7 @@ func NewEnumDefinition(def *EnumDefinition) *EnumDefinition {
 		Description: def.Description,
 		Directives:  def.Directives,
 		Values:      def.Values,
+		Comments:    def.Comments,
 	}
 }
 
@@ -422,6 +478,10 @@ func (def *EnumDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *EnumDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
 func (def *EnumDefinition) GetName() *Name {
 	return def.Name
 }
@@ -449,6 +509,7 @@ type EnumValueDefinition struct {
 	Name        *Name
 	Description *StringValue
 	Directives  []*Directive
+	Comments    *Comments
 }
 
 func NewEnumValueDefinition(def *EnumValueDefinition) *EnumValueDefinition {
@@ -461,6 +522,7 @@ func NewEnumValueDefinition(def *EnumValueDefinition) *EnumValueDefinition {
 		Name:        def.Name,
 		Description: def.Description,
 		Directives:  def.Directives,
+		Comments:    def.Comments,
 	}
 }
 
@@ -472,6 +534,10 @@ func (def *EnumValueDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *EnumValueDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
 func (def *EnumValueDefinition) GetDescription() *StringValue {
 	return def.Description
 }
@@ -484,6 +550,7 @@ type InputObjectDefinition struct {
 	Description *StringValue
 	Directives  []*Directive
 	Fields      []*InputValueDefinition
+	Comments    *Comments
 }
 
 func NewInputObjectDefinition(def *InputObjectDefinition) *InputObjectDefinition {
@@ -497,6 +564,7 @@ func NewInputObjectDefinition(def *InputObjectDefinition) *InputObjectDefinition
 		Description: def.Description,
 		Directives:  def.Directives,
 		Fields:      def.Fields,
+		Comments:    def.Comments,
 	}
 }
 
@@ -508,6 +576,10 @@ func (def *InputObjectDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *InputObjectDefinition) GetComments() *Comments {
+	return def.Comments
+}
+
 func (def *InputObjectDefinition) GetName() *Name {
 	return def.Name
 }
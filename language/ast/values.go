@@ -117,6 +117,8 @@ type StringValue struct {
 	Kind  string
 	Loc   *Location
 	Value string
+	// Block is set for block strings, written between triple quotes.
+	Block bool
 }
 
 func NewStringValue(v *StringValue) *StringValue {
@@ -127,6 +129,7 @@ func NewStringValue(v *StringValue) *StringValue {
 		Kind:  kinds.StringValue,
 		Loc:   v.Loc,
 		Value: v.Value,
+		Block: v.Block,
 	}
 }
 
@@ -4,6 +4,9 @@ const (
 	// Name
 	Name = "Name"
 
+	// Comment
+	Comment = "Comment"
+
 	// Document
 	Document            = "Document"
 	OperationDefinition = "OperationDefinition"
@@ -52,7 +55,13 @@ const (
 	InputObjectDefinition = "InputObjectDefinition" // previously InputObjectTypeDefinition
 
 	// Types Extensions
-	TypeExtensionDefinition = "TypeExtensionDefinition"
+	TypeExtensionDefinition        = "TypeExtensionDefinition"
+	SchemaExtensionDefinition      = "SchemaExtensionDefinition"
+	ScalarExtensionDefinition      = "ScalarExtensionDefinition"
+	InterfaceExtensionDefinition   = "InterfaceExtensionDefinition"
+	UnionExtensionDefinition       = "UnionExtensionDefinition"
+	EnumExtensionDefinition        = "EnumExtensionDefinition"
+	InputObjectExtensionDefinition = "InputObjectExtensionDefinition"
 
 	// Directive Definitions
 	DirectiveDefinition = "DirectiveDefinition"
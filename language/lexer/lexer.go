@@ -34,6 +34,7 @@ const (
 	STRING
 	BLOCK_STRING
 	AMP
+	COMMENT
 )
 
 var tokenDescription = map[TokenKind]string{
@@ -57,6 +58,7 @@ var tokenDescription = map[TokenKind]string{
 	STRING:       "String",
 	BLOCK_STRING: "BlockString",
 	AMP:          "&",
+	COMMENT:      "Comment",
 }
 
 func (kind TokenKind) String() string {
@@ -81,7 +83,8 @@ const (
 )
 
 // Token is a representation of a lexed Token. Value only appears for non-punctuation
-// tokens: NAME, INT, FLOAT, and STRING.
+// tokens: NAME, INT, FLOAT, STRING and COMMENT, whose value is the text after
+// the #.
 type Token struct {
 	Kind  TokenKind
 	Start int
@@ -91,13 +94,25 @@ type Token struct {
 
 type Lexer func(resetPosition int) (Token, error)
 
+// LexOptions tunes a Lexer.
+type LexOptions struct {
+	// Comments makes the lexer return # comments as COMMENT tokens
+	// rather than skip them.
+	Comments bool
+}
+
 func Lex(s *source.Source) Lexer {
+	return LexWithOptions(s, LexOptions{})
+}
+
+// LexWithOptions returns a Lexer for s tuned by opts.
+func LexWithOptions(s *source.Source, opts LexOptions) Lexer {
 	var prevPosition int
 	return func(resetPosition int) (Token, error) {
 		if resetPosition == 0 {
 			resetPosition = prevPosition
 		}
-		token, err := readToken(s, resetPosition)
+		token, err := readToken(s, resetPosition, opts.Comments)
 		if err != nil {
 			return token, err
 		}
@@ -479,10 +494,10 @@ func printCharCode(code rune) string {
 	return fmt.Sprintf(`"\\u%04X"`, code)
 }
 
-func readToken(s *source.Source, fromPosition int) (Token, error) {
+func readToken(s *source.Source, fromPosition int, comments bool) (Token, error) {
 	body := s.Body
 	bodyLength := len(body)
-	position, runePosition := positionAfterWhitespace(body, fromPosition)
+	position, runePosition := positionAfterWhitespace(body, fromPosition, comments)
 	if position >= bodyLength {
 		return makeToken(EOF, position, position, ""), nil
 	}
@@ -541,6 +556,9 @@ func readToken(s *source.Source, fromPosition int) (Token, error) {
 	// }
 	case '}':
 		return makeToken(BRACE_R, position, position+1, ""), nil
+	// #
+	case '#':
+		return readComment(s, position), nil
 	// A-Z
 	case 'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'I', 'J', 'K', 'L', 'M', 'N',
 		'O', 'P', 'Q', 'R', 'S', 'T', 'U', 'V', 'W', 'X', 'Y', 'Z':
@@ -575,6 +593,25 @@ func readToken(s *source.Source, fromPosition int) (Token, error) {
 	return Token{}, gqlerrors.NewSyntaxError(s, runePosition, description)
 }
 
+// Reads a comment from the source, up to the end of its line.
+// #[\u0009\u0020-\uFFFF]*
+func readComment(s *source.Source, start int) Token {
+	body := s.Body
+	position := start + 1
+	for {
+		code, n := runeAt(body, position)
+		if position < len(body) &&
+			code != 0 &&
+			// SourceCharacter but not LineTerminator
+			(code > 0x001F || code == 0x0009) && code != 0x000A && code != 0x000D {
+			position += n
+			continue
+		}
+		break
+	}
+	return makeToken(COMMENT, start, position, string(body[start+1:position]))
+}
+
 // Gets the rune from the byte array at given byte position and it's width in bytes
 func runeAt(body []byte, position int) (code rune, charWidth int) {
 	if len(body) <= position {
@@ -593,9 +630,9 @@ func runeAt(body []byte, position int) (code rune, charWidth int) {
 
 // Reads from body starting at startPosition until it finds a non-whitespace
 // or commented character, then returns the position of that character for lexing.
-// lexing.
+// lexing. Comments are skipped too, unless comments is set.
 // Returns both byte positions and rune position
-func positionAfterWhitespace(body []byte, startPosition int) (position int, runePosition int) {
+func positionAfterWhitespace(body []byte, startPosition int, comments bool) (position int, runePosition int) {
 	bodyLength := len(body)
 	position = startPosition
 	runePosition = startPosition
@@ -615,7 +652,7 @@ func positionAfterWhitespace(body []byte, startPosition int) (position int, rune
 				code == 0x002C {
 				position += n
 				runePosition++
-			} else if code == 35 { // #
+			} else if code == 35 && !comments { // #
 				position += n
 				runePosition++
 				for {
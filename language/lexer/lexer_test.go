@@ -165,6 +165,35 @@ func TestLexer_SkipsWhiteSpace(t *testing.T) {
 	}
 }
 
+func TestLexer_LexesComments(t *testing.T) {
+	body := "# first\n\tfoo # second \r\n#\n"
+	lex := LexWithOptions(createSource(body), LexOptions{Comments: true})
+	expected := []Token{
+		{Kind: COMMENT, Start: 0, End: 7, Value: " first"},
+		{Kind: NAME, Start: 9, End: 12, Value: "foo"},
+		{Kind: COMMENT, Start: 13, End: 22, Value: " second "},
+		{Kind: COMMENT, Start: 24, End: 25, Value: ""},
+		{Kind: EOF, Start: 26, End: 26},
+	}
+	for _, expectedToken := range expected {
+		token, err := lex(0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(token, expectedToken) {
+			t.Fatalf("unexpected token, expected: %v, got: %v", expectedToken, token)
+		}
+	}
+
+	token, err := Lex(createSource(body))(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := (Token{Kind: NAME, Start: 9, End: 12, Value: "foo"}); !reflect.DeepEqual(token, expected) {
+		t.Fatalf("expected comments to be skipped by default, got: %v", token)
+	}
+}
+
 func TestLexer_ErrorsRespectWhitespace(t *testing.T) {
 	body := `
 
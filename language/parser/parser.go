@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/graphql-go/graphql/gqlerrors"
@@ -38,6 +39,11 @@ func init() {
 type ParseOptions struct {
 	NoLocation bool
 	NoSource   bool
+
+	// Comments keeps the # comments of the source: the document lists
+	// them all, and those right before a definition or an item of a
+	// block, or ending its line, are attached to it as ast.Comments.
+	Comments bool
 }
 
 type ParseParams struct {
@@ -51,6 +57,12 @@ type Parser struct {
 	Options  ParseOptions
 	PrevEnd  int
 	Token    lexer.Token
+
+	// comments are the comments lexed so far, and commentStarts their
+	// positions; those from attached on aren't attached to a node yet.
+	comments      []*ast.Comment
+	commentStarts []int
+	attached      int
 }
 
 func Parse(p ParseParams) (*ast.Document, error) {
@@ -108,18 +120,18 @@ func parseName(parser *Parser) (*ast.Name, error) {
 }
 
 func makeParser(s *source.Source, opts ParseOptions) (*Parser, error) {
-	lexToken := lexer.Lex(s)
-	token, err := lexToken(0)
-	if err != nil {
-		return &Parser{}, err
-	}
-	return &Parser{
-		LexToken: lexToken,
+	parser := &Parser{
+		LexToken: lexer.LexWithOptions(s, lexer.LexOptions{Comments: opts.Comments}),
 		Source:   s,
 		Options:  opts,
 		PrevEnd:  0,
-		Token:    token,
-	}, nil
+	}
+	token, err := lex(parser, 0)
+	if err != nil {
+		return &Parser{}, err
+	}
+	parser.Token = token
+	return parser, nil
 }
 
 /* Implements the parsing rules in the Document section. */
@@ -146,7 +158,12 @@ func parseDocument(parser *Parser) (*ast.Document, error) {
 		default:
 			return nil, unexpected(parser, lexer.Token{})
 		}
-		if node, err = item(parser); err != nil {
+		if parser.Options.Comments {
+			node, err = parseCommentedDefinition(parser, item)
+		} else {
+			node, err = item(parser)
+		}
+		if err != nil {
 			return nil, err
 		}
 		nodes = append(nodes, node)
@@ -154,6 +171,7 @@ func parseDocument(parser *Parser) (*ast.Document, error) {
 	return ast.NewDocument(&ast.Document{
 		Loc:         loc(parser, start),
 		Definitions: nodes,
+		Comments:    parser.comments,
 	}), nil
 }
 
@@ -282,10 +300,15 @@ func parseVariableDefinition(parser *Parser) (interface{}, error) {
 			return nil, err
 		}
 	}
+	directives, err := parseDirectives(parser)
+	if err != nil {
+		return nil, err
+	}
 	return ast.NewVariableDefinition(&ast.VariableDefinition{
 		Variable:     variable,
 		Type:         ttype,
 		DefaultValue: defaultValue,
+		Directives:   directives,
 		Loc:          loc(parser, start),
 	}), nil
 }
@@ -1361,7 +1384,17 @@ func parseInputObjectTypeDefinition(parser *Parser) (ast.Node, error) {
 }
 
 /**
- * TypeExtensionDefinition : extend ObjectTypeDefinition
+ * TypeExtension :
+ *   - SchemaExtension
+ *   - ScalarTypeExtension
+ *   - ObjectTypeExtension
+ *   - InterfaceTypeExtension
+ *   - UnionTypeExtension
+ *   - EnumTypeExtension
+ *   - InputObjectTypeExtension
+ *
+ * An extension adds to a definition made elsewhere, so it may leave out
+ * the body of the definition, but must add something to it.
  */
 func parseTypeExtensionDefinition(parser *Parser) (ast.Node, error) {
 	start := parser.Token.Start
@@ -1369,14 +1402,338 @@ func parseTypeExtensionDefinition(parser *Parser) (ast.Node, error) {
 	if err != nil {
 		return nil, err
 	}
+	if parser.Token.Kind == lexer.NAME {
+		switch parser.Token.Value {
+		case lexer.SCHEMA:
+			return parseSchemaExtension(parser, start)
+		case lexer.SCALAR:
+			return parseScalarTypeExtension(parser, start)
+		case lexer.TYPE:
+			return parseObjectTypeExtension(parser, start)
+		case lexer.INTERFACE:
+			return parseInterfaceTypeExtension(parser, start)
+		case lexer.UNION:
+			return parseUnionTypeExtension(parser, start)
+		case lexer.ENUM:
+			return parseEnumTypeExtension(parser, start)
+		case lexer.INPUT:
+			return parseInputObjectTypeExtension(parser, start)
+		}
+	}
+	return nil, unexpected(parser, lexer.Token{})
+}
+
+/**
+ * SchemaExtension :
+ *   - extend schema Directives? { OperationTypeDefinition+ }
+ *   - extend schema Directives
+ */
+func parseSchemaExtension(parser *Parser, start int) (ast.Node, error) {
+	defStart := parser.Token.Start
+	_, err := expectKeyWord(parser, lexer.SCHEMA)
+	if err != nil {
+		return nil, err
+	}
+	directives, err := parseDirectives(parser)
+	if err != nil {
+		return nil, err
+	}
+	operationTypes := []*ast.OperationTypeDefinition{}
+	if peek(parser, lexer.BRACE_L) {
+		operationTypesI, err := reverse(
+			parser,
+			lexer.BRACE_L, parseOperationTypeDefinition, lexer.BRACE_R,
+			true,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, op := range operationTypesI {
+			if op, ok := op.(*ast.OperationTypeDefinition); ok {
+				operationTypes = append(operationTypes, op)
+			}
+		}
+	} else if len(directives) == 0 {
+		return nil, unexpected(parser, lexer.Token{})
+	}
+	definition := ast.NewSchemaDefinition(&ast.SchemaDefinition{
+		OperationTypes: operationTypes,
+		Directives:     directives,
+		Loc:            loc(parser, defStart),
+	})
+	return ast.NewSchemaExtensionDefinition(&ast.SchemaExtensionDefinition{
+		Loc:        loc(parser, start),
+		Definition: definition,
+	}), nil
+}
+
+/**
+ * ScalarTypeExtension : extend scalar Name Directives
+ */
+func parseScalarTypeExtension(parser *Parser, start int) (ast.Node, error) {
+	defStart := parser.Token.Start
+	_, err := expectKeyWord(parser, lexer.SCALAR)
+	if err != nil {
+		return nil, err
+	}
+	name, err := parseName(parser)
+	if err != nil {
+		return nil, err
+	}
+	directives, err := parseDirectives(parser)
+	if err != nil {
+		return nil, err
+	}
+	if len(directives) == 0 {
+		return nil, unexpected(parser, lexer.Token{})
+	}
+	definition := ast.NewScalarDefinition(&ast.ScalarDefinition{
+		Name:       name,
+		Directives: directives,
+		Loc:        loc(parser, defStart),
+	})
+	return ast.NewScalarExtensionDefinition(&ast.ScalarExtensionDefinition{
+		Loc:        loc(parser, start),
+		Definition: definition,
+	}), nil
+}
 
-	definition, err := parseObjectTypeDefinition(parser)
+/**
+ * ObjectTypeExtension :
+ *   - extend type Name ImplementsInterfaces? Directives? { FieldDefinition+ }
+ *   - extend type Name ImplementsInterfaces? Directives
+ *   - extend type Name ImplementsInterfaces
+ */
+func parseObjectTypeExtension(parser *Parser, start int) (ast.Node, error) {
+	defStart := parser.Token.Start
+	_, err := expectKeyWord(parser, lexer.TYPE)
+	if err != nil {
+		return nil, err
+	}
+	name, err := parseName(parser)
 	if err != nil {
 		return nil, err
 	}
+	interfaces, err := parseImplementsInterfaces(parser)
+	if err != nil {
+		return nil, err
+	}
+	directives, err := parseDirectives(parser)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := parseExtensionFieldDefs(parser, len(interfaces) > 0 || len(directives) > 0)
+	if err != nil {
+		return nil, err
+	}
+	definition := ast.NewObjectDefinition(&ast.ObjectDefinition{
+		Name:       name,
+		Loc:        loc(parser, defStart),
+		Interfaces: interfaces,
+		Directives: directives,
+		Fields:     fields,
+	})
 	return ast.NewTypeExtensionDefinition(&ast.TypeExtensionDefinition{
 		Loc:        loc(parser, start),
-		Definition: definition.(*ast.ObjectDefinition),
+		Definition: definition,
+	}), nil
+}
+
+/**
+ * InterfaceTypeExtension :
+ *   - extend interface Name Directives? { FieldDefinition+ }
+ *   - extend interface Name Directives
+ */
+func parseInterfaceTypeExtension(parser *Parser, start int) (ast.Node, error) {
+	defStart := parser.Token.Start
+	_, err := expectKeyWord(parser, lexer.INTERFACE)
+	if err != nil {
+		return nil, err
+	}
+	name, err := parseName(parser)
+	if err != nil {
+		return nil, err
+	}
+	directives, err := parseDirectives(parser)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := parseExtensionFieldDefs(parser, len(directives) > 0)
+	if err != nil {
+		return nil, err
+	}
+	definition := ast.NewInterfaceDefinition(&ast.InterfaceDefinition{
+		Name:       name,
+		Directives: directives,
+		Loc:        loc(parser, defStart),
+		Fields:     fields,
+	})
+	return ast.NewInterfaceExtensionDefinition(&ast.InterfaceExtensionDefinition{
+		Loc:        loc(parser, start),
+		Definition: definition,
+	}), nil
+}
+
+// parseExtensionFieldDefs parses the optional fields of an object or
+// interface extension; they are required unless the extension adds
+// something else.
+func parseExtensionFieldDefs(parser *Parser, optional bool) ([]*ast.FieldDefinition, error) {
+	fields := []*ast.FieldDefinition{}
+	if !peek(parser, lexer.BRACE_L) {
+		if optional {
+			return fields, nil
+		}
+		return nil, unexpected(parser, lexer.Token{})
+	}
+	iFields, err := reverse(parser,
+		lexer.BRACE_L, parseFieldDefinition, lexer.BRACE_R,
+		false,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, iField := range iFields {
+		if iField != nil {
+			fields = append(fields, iField.(*ast.FieldDefinition))
+		}
+	}
+	return fields, nil
+}
+
+/**
+ * UnionTypeExtension :
+ *   - extend union Name Directives? = UnionMembers
+ *   - extend union Name Directives
+ */
+func parseUnionTypeExtension(parser *Parser, start int) (ast.Node, error) {
+	defStart := parser.Token.Start
+	_, err := expectKeyWord(parser, lexer.UNION)
+	if err != nil {
+		return nil, err
+	}
+	name, err := parseName(parser)
+	if err != nil {
+		return nil, err
+	}
+	directives, err := parseDirectives(parser)
+	if err != nil {
+		return nil, err
+	}
+	types := []*ast.Named{}
+	if skp, err := skip(parser, lexer.EQUALS); err != nil {
+		return nil, err
+	} else if skp {
+		if types, err = parseUnionMembers(parser); err != nil {
+			return nil, err
+		}
+	} else if len(directives) == 0 {
+		return nil, unexpected(parser, lexer.Token{})
+	}
+	definition := ast.NewUnionDefinition(&ast.UnionDefinition{
+		Name:       name,
+		Directives: directives,
+		Loc:        loc(parser, defStart),
+		Types:      types,
+	})
+	return ast.NewUnionExtensionDefinition(&ast.UnionExtensionDefinition{
+		Loc:        loc(parser, start),
+		Definition: definition,
+	}), nil
+}
+
+/**
+ * EnumTypeExtension :
+ *   - extend enum Name Directives? { EnumValueDefinition+ }
+ *   - extend enum Name Directives
+ */
+func parseEnumTypeExtension(parser *Parser, start int) (ast.Node, error) {
+	defStart := parser.Token.Start
+	_, err := expectKeyWord(parser, lexer.ENUM)
+	if err != nil {
+		return nil, err
+	}
+	name, err := parseName(parser)
+	if err != nil {
+		return nil, err
+	}
+	directives, err := parseDirectives(parser)
+	if err != nil {
+		return nil, err
+	}
+	values := []*ast.EnumValueDefinition{}
+	if peek(parser, lexer.BRACE_L) {
+		iEnumValueDefs, err := reverse(parser,
+			lexer.BRACE_L, parseEnumValueDefinition, lexer.BRACE_R,
+			false,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, iEnumValueDef := range iEnumValueDefs {
+			if iEnumValueDef != nil {
+				values = append(values, iEnumValueDef.(*ast.EnumValueDefinition))
+			}
+		}
+	} else if len(directives) == 0 {
+		return nil, unexpected(parser, lexer.Token{})
+	}
+	definition := ast.NewEnumDefinition(&ast.EnumDefinition{
+		Name:       name,
+		Directives: directives,
+		Loc:        loc(parser, defStart),
+		Values:     values,
+	})
+	return ast.NewEnumExtensionDefinition(&ast.EnumExtensionDefinition{
+		Loc:        loc(parser, start),
+		Definition: definition,
+	}), nil
+}
+
+/**
+ * InputObjectTypeExtension :
+ *   - extend input Name Directives? { InputValueDefinition+ }
+ *   - extend input Name Directives
+ */
+func parseInputObjectTypeExtension(parser *Parser, start int) (ast.Node, error) {
+	defStart := parser.Token.Start
+	_, err := expectKeyWord(parser, lexer.INPUT)
+	if err != nil {
+		return nil, err
+	}
+	name, err := parseName(parser)
+	if err != nil {
+		return nil, err
+	}
+	directives, err := parseDirectives(parser)
+	if err != nil {
+		return nil, err
+	}
+	fields := []*ast.InputValueDefinition{}
+	if peek(parser, lexer.BRACE_L) {
+		iInputValueDefinitions, err := reverse(parser,
+			lexer.BRACE_L, parseInputValueDef, lexer.BRACE_R,
+			false,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, iInputValueDefinition := range iInputValueDefinitions {
+			if iInputValueDefinition != nil {
+				fields = append(fields, iInputValueDefinition.(*ast.InputValueDefinition))
+			}
+		}
+	} else if len(directives) == 0 {
+		return nil, unexpected(parser, lexer.Token{})
+	}
+	definition := ast.NewInputObjectDefinition(&ast.InputObjectDefinition{
+		Name:       name,
+		Directives: directives,
+		Loc:        loc(parser, defStart),
+		Fields:     fields,
+	})
+	return ast.NewInputObjectExtensionDefinition(&ast.InputObjectExtensionDefinition{
+		Loc:        loc(parser, start),
+		Definition: definition,
 	}), nil
 }
 
@@ -1454,6 +1811,7 @@ func parseStringLiteral(parser *Parser) (*ast.StringValue, error) {
 	}
 	return ast.NewStringValue(&ast.StringValue{
 		Value: token.Value,
+		Block: token.Kind == lexer.BLOCK_STRING,
 		Loc:   loc(parser, token.Start),
 	}), nil
 }
@@ -1492,7 +1850,7 @@ func loc(parser *Parser, start int) *ast.Location {
 // Moves the internal parser object to the next lexed token.
 func advance(parser *Parser) error {
 	parser.PrevEnd = parser.Token.End
-	token, err := parser.LexToken(parser.PrevEnd)
+	token, err := lex(parser, parser.PrevEnd)
 	if err != nil {
 		return err
 	}
@@ -1500,9 +1858,140 @@ func advance(parser *Parser) error {
 	return nil
 }
 
+// lex returns the token lexed from position, recording the comments
+// before it.
+func lex(parser *Parser, position int) (lexer.Token, error) {
+	token, err := parser.LexToken(position)
+	for err == nil && token.Kind == lexer.COMMENT {
+		parser.comments = append(parser.comments, ast.NewComment(&ast.Comment{
+			Value: token.Value,
+			Loc:   tokenLoc(parser, token),
+		}))
+		parser.commentStarts = append(parser.commentStarts, token.Start)
+		token, err = parser.LexToken(token.End)
+	}
+	return token, err
+}
+
 // lookahead retrieves the next token
 func lookahead(parser *Parser) (lexer.Token, error) {
-	return parser.LexToken(parser.Token.End)
+	token, err := parser.LexToken(parser.Token.End)
+	for err == nil && token.Kind == lexer.COMMENT {
+		token, err = parser.LexToken(token.End)
+	}
+	return token, err
+}
+
+func tokenLoc(parser *Parser, token lexer.Token) *ast.Location {
+	if parser.Options.NoLocation {
+		return nil
+	}
+	location := &ast.Location{Start: token.Start, End: token.End}
+	if !parser.Options.NoSource {
+		location.Source = parser.Source
+	}
+	return ast.NewLocation(location)
+}
+
+// parseCommented parses a node with fn and attaches to it the comments
+// right before it, and the comment ending the line it ends on. The
+// comments within the node that none of its children took are dropped.
+func parseCommented(parser *Parser, fn parseFn) (interface{}, error) {
+	leading := takeComments(parser, parser.Token.Start)
+	node, err := fn(parser)
+	if err != nil {
+		return node, err
+	}
+	end := parser.PrevEnd
+	takeComments(parser, end)
+	var trailing *ast.Comment
+	if parser.attached < len(parser.comments) {
+		start := parser.commentStarts[parser.attached]
+		if !bytes.ContainsAny(parser.Source.Body[end:start], "\r\n") {
+			trailing = parser.comments[parser.attached]
+			parser.attached++
+		}
+	}
+	if len(leading) > 0 || trailing != nil {
+		setComments(node, &ast.Comments{Leading: leading, Trailing: trailing})
+	}
+	return node, nil
+}
+
+func parseCommentedDefinition(parser *Parser, fn parseDefinitionFn) (ast.Node, error) {
+	node, err := parseCommented(parser, func(parser *Parser) (interface{}, error) {
+		return fn(parser)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return node.(ast.Node), nil
+}
+
+// takeComments returns the comments not attached yet that start before
+// position, marking them attached.
+func takeComments(parser *Parser, position int) []*ast.Comment {
+	from := parser.attached
+	for parser.attached < len(parser.comments) && parser.commentStarts[parser.attached] < position {
+		parser.attached++
+	}
+	if from == parser.attached {
+		return nil
+	}
+	return parser.comments[from:parser.attached]
+}
+
+func setComments(node interface{}, comments *ast.Comments) {
+	switch node := node.(type) {
+	case *ast.OperationDefinition:
+		node.Comments = comments
+	case *ast.FragmentDefinition:
+		node.Comments = comments
+	case *ast.Field:
+		node.Comments = comments
+	case *ast.FragmentSpread:
+		node.Comments = comments
+	case *ast.InlineFragment:
+		node.Comments = comments
+	case *ast.SchemaDefinition:
+		node.Comments = comments
+	case *ast.OperationTypeDefinition:
+		node.Comments = comments
+	case *ast.ScalarDefinition:
+		node.Comments = comments
+	case *ast.ObjectDefinition:
+		node.Comments = comments
+	case *ast.FieldDefinition:
+		node.Comments = comments
+	case *ast.InputValueDefinition:
+		node.Comments = comments
+	case *ast.InterfaceDefinition:
+		node.Comments = comments
+	case *ast.UnionDefinition:
+		node.Comments = comments
+	case *ast.EnumDefinition:
+		node.Comments = comments
+	case *ast.EnumValueDefinition:
+		node.Comments = comments
+	case *ast.InputObjectDefinition:
+		node.Comments = comments
+	case *ast.TypeExtensionDefinition:
+		node.Comments = comments
+	case *ast.SchemaExtensionDefinition:
+		node.Comments = comments
+	case *ast.ScalarExtensionDefinition:
+		node.Comments = comments
+	case *ast.InterfaceExtensionDefinition:
+		node.Comments = comments
+	case *ast.UnionExtensionDefinition:
+		node.Comments = comments
+	case *ast.EnumExtensionDefinition:
+		node.Comments = comments
+	case *ast.InputObjectExtensionDefinition:
+		node.Comments = comments
+	case *ast.DirectiveDefinition:
+		node.Comments = comments
+	}
 }
 
 // Determines if the next token is of a given kind
@@ -1572,6 +2061,11 @@ func reverse(parser *Parser, openKind lexer.TokenKind, parseFn parseFn, closeKin
 	if err != nil {
 		return nil, err
 	}
+	commented := openKind == lexer.BRACE_L && parser.Options.Comments
+	if commented {
+		// the comments before the block belong to its node
+		takeComments(parser, token.Start)
+	}
 	var nodes []interface{}
 	for {
 		if skp, err := skip(parser, closeKind); err != nil {
@@ -1579,7 +2073,13 @@ func reverse(parser *Parser, openKind lexer.TokenKind, parseFn parseFn, closeKin
 		} else if skp {
 			break
 		}
-		node, err := parseFn(parser)
+		var node interface{}
+		var err error
+		if commented {
+			node, err = parseCommented(parser, parseFn)
+		} else {
+			node, err = parseFn(parser)
+		}
 		if err != nil {
 			return nodes, err
 		}
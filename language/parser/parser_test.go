@@ -165,6 +165,94 @@ func TestParser_ParsesConstantDefaultValues(t *testing.T) {
 	testErrorMessage(t, test)
 }
 
+func TestParser_ParsesVariableDefinitionDirectives(t *testing.T) {
+	source := `query ($id: ID! = "1" @deprecated @other(reason: "x")) { node(id: $id) }`
+	astDoc, err := Parse(ParseParams{Source: source})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	varDef := astDoc.Definitions[0].(*ast.OperationDefinition).VariableDefinitions[0]
+	if len(varDef.Directives) != 2 {
+		t.Fatalf("expected 2 directives, got %v", varDef.Directives)
+	}
+	if name := varDef.Directives[0].Name.Value; name != "deprecated" {
+		t.Fatalf("expected the deprecated directive, got %v", name)
+	}
+	if name := varDef.Directives[1].Name.Value; name != "other" || len(varDef.Directives[1].Arguments) != 1 {
+		t.Fatalf("expected the other directive with its argument, got %v", varDef.Directives[1])
+	}
+	if varDef.Loc.Start != 7 || varDef.Loc.End != 53 {
+		t.Fatalf("expected the variable definition to span its directives, got %v", varDef.Loc)
+	}
+}
+
+func TestParser_AttachesComments(t *testing.T) {
+	source := `# hero
+query Hero { # brace
+  hero(id: 1 # inside
+  ) { name } # after hero
+  # before friends
+  friends
+  # dangling
+} # after query
+enum Episode {
+  NEWHOPE # new hope
+}`
+	astDoc, err := Parse(ParseParams{Source: source, Options: ParseOptions{Comments: true, NoLocation: true}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(astDoc.Comments) != 8 {
+		t.Fatalf("expected 8 comments, got %v", len(astDoc.Comments))
+	}
+	comment := func(value string) *ast.Comment {
+		return ast.NewComment(&ast.Comment{Value: value})
+	}
+
+	op := astDoc.Definitions[0].(*ast.OperationDefinition)
+	expected := &ast.Comments{Leading: []*ast.Comment{comment(" hero")}, Trailing: comment(" after query")}
+	if !reflect.DeepEqual(op.Comments, expected) {
+		t.Fatalf("unexpected operation comments, expected: %v, got: %v", expected, op.Comments)
+	}
+	hero := op.SelectionSet.Selections[0].(*ast.Field)
+	expected = &ast.Comments{Leading: []*ast.Comment{comment(" brace")}, Trailing: comment(" after hero")}
+	if !reflect.DeepEqual(hero.Comments, expected) {
+		t.Fatalf("unexpected hero comments, expected: %v, got: %v", expected, hero.Comments)
+	}
+	if name := hero.SelectionSet.Selections[0].(*ast.Field); name.Comments != nil {
+		t.Fatalf("expected no comments on name, got: %v", name.Comments)
+	}
+	friends := op.SelectionSet.Selections[1].(*ast.Field)
+	expected = &ast.Comments{Leading: []*ast.Comment{comment(" before friends")}}
+	if !reflect.DeepEqual(friends.Comments, expected) {
+		t.Fatalf("unexpected friends comments, expected: %v, got: %v", expected, friends.Comments)
+	}
+	enum := astDoc.Definitions[1].(*ast.EnumDefinition)
+	expected = &ast.Comments{Trailing: comment(" new hope")}
+	if enum.Comments != nil || !reflect.DeepEqual(enum.Values[0].Comments, expected) {
+		t.Fatalf("unexpected enum comments: %v, %v", enum.Comments, enum.Values[0].Comments)
+	}
+
+	astDoc, err = Parse(ParseParams{Source: source})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if astDoc.Comments != nil || astDoc.Definitions[0].(*ast.OperationDefinition).Comments != nil {
+		t.Fatalf("expected comments to be dropped by default")
+	}
+}
+
+func TestParser_CommentLocations(t *testing.T) {
+	astDoc, err := Parse(ParseParams{Source: "{ a } # end", Options: ParseOptions{Comments: true, NoSource: true}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []*ast.Comment{ast.NewComment(&ast.Comment{Value: " end", Loc: &ast.Location{Start: 6, End: 11}})}
+	if !reflect.DeepEqual(astDoc.Comments, expected) {
+		t.Fatalf("unexpected comments, expected: %v, got: %v", expected, astDoc.Comments)
+	}
+}
+
 func TestParser_DoesNotAcceptFragmentsNameOn(t *testing.T) {
 	test := errorMessageTest{
 		`fragment on on on { on }`,
@@ -498,6 +586,27 @@ func TestParser_ParsesEnumValueDefinitionWithDescription(t *testing.T) {
 	}
 }
 
+func TestParser_MarksBlockStrings(t *testing.T) {
+	source := `
+		enum Site {
+			"description 1"
+			DESKTOP
+			"""
+			description 2
+			"""
+			MOBILE
+		}
+	`
+	astDoc, err := Parse(ParseParams{Source: source})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	values := astDoc.Definitions[0].(*ast.EnumDefinition).Values
+	if values[0].Description.Block || !values[1].Description.Block {
+		t.Fatalf("expected only the second description to be a block string, got %v, %v", values[0].Description, values[1].Description)
+	}
+}
+
 func TestParser_DefinitionsWithDescriptions(t *testing.T) {
 	testCases := []struct {
 		name            string
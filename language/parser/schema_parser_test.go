@@ -2,10 +2,12 @@ package parser
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/graphql-go/graphql/gqlerrors"
 	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/kinds"
 	"github.com/graphql-go/graphql/language/location"
 	"github.com/graphql-go/graphql/language/source"
 )
@@ -121,6 +123,118 @@ extend type Hello {
 	}
 }
 
+func TestSchemaParser_ScalarExtension(t *testing.T) {
+	body := `extend scalar Hello @foo`
+	astDoc := parse(t, body)
+	expected := ast.NewDocument(&ast.Document{
+		Loc: testLoc(0, 24),
+		Definitions: []ast.Node{
+			ast.NewScalarExtensionDefinition(&ast.ScalarExtensionDefinition{
+				Loc: testLoc(0, 24),
+				Definition: ast.NewScalarDefinition(&ast.ScalarDefinition{
+					Loc: testLoc(7, 24),
+					Name: ast.NewName(&ast.Name{
+						Value: "Hello",
+						Loc:   testLoc(14, 19),
+					}),
+					Directives: []*ast.Directive{
+						ast.NewDirective(&ast.Directive{
+							Loc: testLoc(20, 24),
+							Name: ast.NewName(&ast.Name{
+								Value: "foo",
+								Loc:   testLoc(21, 24),
+							}),
+							Arguments: []*ast.Argument{},
+						}),
+					},
+				}),
+			}),
+		},
+	})
+	if !reflect.DeepEqual(astDoc, expected) {
+		t.Fatalf("unexpected document, expected: %v, got: %v", expected, astDoc)
+	}
+}
+
+func TestSchemaParser_UnionExtension(t *testing.T) {
+	body := `extend union Hello = World`
+	astDoc := parse(t, body)
+	expected := ast.NewDocument(&ast.Document{
+		Loc: testLoc(0, 26),
+		Definitions: []ast.Node{
+			ast.NewUnionExtensionDefinition(&ast.UnionExtensionDefinition{
+				Loc: testLoc(0, 26),
+				Definition: ast.NewUnionDefinition(&ast.UnionDefinition{
+					Loc: testLoc(7, 26),
+					Name: ast.NewName(&ast.Name{
+						Value: "Hello",
+						Loc:   testLoc(13, 18),
+					}),
+					Directives: []*ast.Directive{},
+					Types: []*ast.Named{
+						ast.NewNamed(&ast.Named{
+							Loc: testLoc(21, 26),
+							Name: ast.NewName(&ast.Name{
+								Value: "World",
+								Loc:   testLoc(21, 26),
+							}),
+						}),
+					},
+				}),
+			}),
+		},
+	})
+	if !reflect.DeepEqual(astDoc, expected) {
+		t.Fatalf("unexpected document, expected: %v, got: %v", expected, astDoc)
+	}
+}
+
+func TestSchemaParser_ExtensionKinds(t *testing.T) {
+	tests := map[string]string{
+		`extend schema @foo`:                    kinds.SchemaExtensionDefinition,
+		`extend schema { query: Query }`:        kinds.SchemaExtensionDefinition,
+		`extend scalar Hello @foo`:              kinds.ScalarExtensionDefinition,
+		`extend type Hello implements World`:    kinds.TypeExtensionDefinition,
+		`extend type Hello @foo`:                kinds.TypeExtensionDefinition,
+		`extend interface Hello { world: Int }`: kinds.InterfaceExtensionDefinition,
+		`extend interface Hello @foo`:           kinds.InterfaceExtensionDefinition,
+		`extend union Hello @foo`:               kinds.UnionExtensionDefinition,
+		`extend enum Hello { WORLD }`:           kinds.EnumExtensionDefinition,
+		`extend enum Hello @foo`:                kinds.EnumExtensionDefinition,
+		`extend input Hello { world: Int }`:     kinds.InputObjectExtensionDefinition,
+		`extend input Hello @foo`:               kinds.InputObjectExtensionDefinition,
+	}
+	for body, kind := range tests {
+		astDoc := parse(t, body)
+		if len(astDoc.Definitions) != 1 || astDoc.Definitions[0].GetKind() != kind {
+			t.Fatalf("expected %q to parse to a %v, got %v", body, kind, astDoc.Definitions)
+		}
+	}
+}
+
+func TestSchemaParser_ExtensionWithoutAdditionsShouldFail(t *testing.T) {
+	tests := map[string]string{
+		`extend schema`:           `Syntax Error GraphQL (1:14) Unexpected EOF`,
+		`extend schema {}`:        `Syntax Error GraphQL (1:15) Unexpected empty IN {}`,
+		`extend scalar Hello`:     `Syntax Error GraphQL (1:20) Unexpected EOF`,
+		`extend type Hello`:       `Syntax Error GraphQL (1:18) Unexpected EOF`,
+		`extend interface Hello`:  `Syntax Error GraphQL (1:23) Unexpected EOF`,
+		`extend union Hello`:      `Syntax Error GraphQL (1:19) Unexpected EOF`,
+		`extend enum Hello`:       `Syntax Error GraphQL (1:18) Unexpected EOF`,
+		`extend input Hello`:      `Syntax Error GraphQL (1:19) Unexpected EOF`,
+		`extend directive @hello`: `Syntax Error GraphQL (1:8) Unexpected Name "directive"`,
+	}
+	for body, expected := range tests {
+		_, err := Parse(ParseParams{Source: body})
+		if err == nil {
+			t.Fatalf("expected %q to fail", body)
+		}
+		if !strings.HasPrefix(err.Error(), expected) {
+			t.Fatalf("unexpected error for %q, expected: %v, got: %v", body, expected, err)
+		}
+	}
+}
+
 func TestSchemaParser_SimpleNonNullType(t *testing.T) {
 
 	body := `
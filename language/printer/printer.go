@@ -238,15 +238,17 @@ var printDocASTReducer = map[string]visitor.VisitFunc{
 			variable := fmt.Sprintf("%v", node.Variable)
 			ttype := fmt.Sprintf("%v", node.Type)
 			defaultValue := fmt.Sprintf("%v", node.DefaultValue)
+			directives := toSliceString(node.Directives)
 
-			return visitor.ActionUpdate, variable + ": " + ttype + wrap(" = ", defaultValue, "")
+			return visitor.ActionUpdate, variable + ": " + ttype + wrap(" = ", defaultValue, "") + wrap(" ", join(directives, " "), "")
 		case map[string]interface{}:
 
 			variable := getMapValueString(node, "Variable")
 			ttype := getMapValueString(node, "Type")
 			defaultValue := getMapValueString(node, "DefaultValue")
+			directives := toSliceString(getMapValue(node, "Directives"))
 
-			return visitor.ActionUpdate, variable + ": " + ttype + wrap(" = ", defaultValue, "")
+			return visitor.ActionUpdate, variable + ": " + ttype + wrap(" = ", defaultValue, "") + wrap(" ", join(directives, " "), "")
 
 		}
 		return visitor.ActionNoChange, nil
@@ -879,19 +881,13 @@ var printDocASTReducer = map[string]visitor.VisitFunc{
 		}
 		return visitor.ActionNoChange, nil
 	},
-	"TypeExtensionDefinition": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.TypeExtensionDefinition:
-			definition := fmt.Sprintf("%v", node.Definition)
-			str := "extend " + definition
-			return visitor.ActionUpdate, str
-		case map[string]interface{}:
-			definition := getMapValueString(node, "Definition")
-			str := "extend " + definition
-			return visitor.ActionUpdate, str
-		}
-		return visitor.ActionNoChange, nil
-	},
+	"SchemaExtensionDefinition":      printExtensionDefinition,
+	"ScalarExtensionDefinition":      printExtensionDefinition,
+	"TypeExtensionDefinition":        printExtensionDefinition,
+	"InterfaceExtensionDefinition":   printExtensionDefinition,
+	"UnionExtensionDefinition":       printExtensionDefinition,
+	"EnumExtensionDefinition":        printExtensionDefinition,
+	"InputObjectExtensionDefinition": printExtensionDefinition,
 	"DirectiveDefinition": func(p visitor.VisitFuncParams) (string, interface{}) {
 		switch node := p.Node.(type) {
 		case *ast.DirectiveDefinition:
@@ -941,6 +937,208 @@ var printDocASTReducer = map[string]visitor.VisitFunc{
 	},
 }
 
+// printExtensionDefinition prints the extension of a type system
+// definition from the parts of its definition, which extendedKinds leave
+// unprinted. Schema and union extensions may leave out the operation
+// types or members their definition can't, so those aren't printed empty.
+func printExtensionDefinition(p visitor.VisitFuncParams) (string, interface{}) {
+	var (
+		kind interface{}
+		def  map[string]interface{}
+	)
+	switch node := p.Node.(type) {
+	case *ast.SchemaExtensionDefinition:
+		if node.Definition != nil {
+			kind, def = node.Kind, typedDefinition(nil, node.Definition.Directives)
+			def["OperationTypes"] = node.Definition.OperationTypes
+		}
+	case *ast.ScalarExtensionDefinition:
+		if node.Definition != nil {
+			kind, def = node.Kind, typedDefinition(node.Definition.Name, node.Definition.Directives)
+		}
+	case *ast.TypeExtensionDefinition:
+		if node.Definition != nil {
+			kind, def = node.Kind, typedDefinition(node.Definition.Name, node.Definition.Directives)
+			def["Interfaces"], def["Fields"] = node.Definition.Interfaces, node.Definition.Fields
+		}
+	case *ast.InterfaceExtensionDefinition:
+		if node.Definition != nil {
+			kind, def = node.Kind, typedDefinition(node.Definition.Name, node.Definition.Directives)
+			def["Fields"] = node.Definition.Fields
+		}
+	case *ast.UnionExtensionDefinition:
+		if node.Definition != nil {
+			kind, def = node.Kind, typedDefinition(node.Definition.Name, node.Definition.Directives)
+			def["Types"] = node.Definition.Types
+		}
+	case *ast.EnumExtensionDefinition:
+		if node.Definition != nil {
+			kind, def = node.Kind, typedDefinition(node.Definition.Name, node.Definition.Directives)
+			def["Values"] = node.Definition.Values
+		}
+	case *ast.InputObjectExtensionDefinition:
+		if node.Definition != nil {
+			kind, def = node.Kind, typedDefinition(node.Definition.Name, node.Definition.Directives)
+			def["Fields"] = node.Definition.Fields
+		}
+	case map[string]interface{}:
+		kind = node["Kind"]
+		def, _ = node["Definition"].(map[string]interface{})
+	}
+	if def == nil {
+		return visitor.ActionNoChange, nil
+	}
+	name := getMapValueString(def, "Name")
+	directives := []string{}
+	for _, directive := range getMapSliceValue(def, "Directives") {
+		directives = append(directives, fmt.Sprintf("%v", directive))
+	}
+	var parts []string
+	switch kind {
+	case "SchemaExtensionDefinition":
+		operationTypes := toSliceString(getMapValue(def, "OperationTypes"))
+		if len(operationTypes) == 0 {
+			parts = []string{"schema", join(directives, " ")}
+		} else {
+			parts = []string{"schema", join(directives, " "), block(operationTypes)}
+		}
+	case "ScalarExtensionDefinition":
+		parts = []string{"scalar", name, join(directives, " ")}
+	case "TypeExtensionDefinition":
+		interfaces := toSliceString(getMapValue(def, "Interfaces"))
+		parts = []string{
+			"type",
+			name,
+			wrap("implements ", join(interfaces, " & "), ""),
+			join(directives, " "),
+			block(getMapValue(def, "Fields")),
+		}
+	case "InterfaceExtensionDefinition":
+		parts = []string{"interface", name, join(directives, " "), block(getMapValue(def, "Fields"))}
+	case "UnionExtensionDefinition":
+		types := toSliceString(getMapValue(def, "Types"))
+		parts = []string{"union", name, join(directives, " "), wrap("= ", join(types, " | "), "")}
+	case "EnumExtensionDefinition":
+		parts = []string{"enum", name, join(directives, " "), block(getMapValue(def, "Values"))}
+	case "InputObjectExtensionDefinition":
+		parts = []string{"input", name, join(directives, " "), block(getMapValue(def, "Fields"))}
+	default:
+		return visitor.ActionNoChange, nil
+	}
+	return visitor.ActionUpdate, "extend " + join(parts, " ")
+}
+
+// typedDefinition returns the parts of an unprinted definition shared by
+// every kind, as printExtensionDefinition reads them.
+func typedDefinition(name *ast.Name, directives []*ast.Directive) map[string]interface{} {
+	def := map[string]interface{}{"Directives": []interface{}{}}
+	if name != nil {
+		def["Name"] = fmt.Sprintf("%v", name)
+	}
+	for _, directive := range directives {
+		def["Directives"] = append(def["Directives"].([]interface{}), fmt.Sprintf("%v", directive.Name))
+	}
+	return def
+}
+
+// extendedKinds are the kinds of the definitions extensions extend; inside
+// an extension their reducers leave them to printExtensionDefinition.
+var extendedKinds = []string{
+	"SchemaDefinition", "ScalarDefinition", "ObjectDefinition", "InterfaceDefinition",
+	"UnionDefinition", "EnumDefinition", "InputObjectDefinition",
+}
+
+// unlessExtended wraps the reducer of a definition to leave the
+// definition of an extension unprinted.
+func unlessExtended(reduce visitor.VisitFunc) visitor.VisitFunc {
+	return func(p visitor.VisitFuncParams) (string, interface{}) {
+		if p.Key == "Definition" {
+			return visitor.ActionNoChange, nil
+		}
+		return reduce(p)
+	}
+}
+
+// commentedKinds are the kinds of the nodes the parser attaches comments
+// to; their reducers print the comments too.
+var commentedKinds = []string{
+	"OperationDefinition", "FragmentDefinition", "Field", "FragmentSpread", "InlineFragment",
+	"SchemaDefinition", "OperationTypeDefinition", "ScalarDefinition", "ObjectDefinition",
+	"FieldDefinition", "InputValueDefinition", "InterfaceDefinition", "UnionDefinition",
+	"EnumDefinition", "EnumValueDefinition", "InputObjectDefinition", "DirectiveDefinition",
+	"SchemaExtensionDefinition", "ScalarExtensionDefinition", "TypeExtensionDefinition",
+	"InterfaceExtensionDefinition", "UnionExtensionDefinition", "EnumExtensionDefinition",
+	"InputObjectExtensionDefinition",
+}
+
+func init() {
+	for _, kind := range commentedKinds {
+		printDocASTReducer[kind] = withComments(printDocASTReducer[kind])
+	}
+	for _, kind := range extendedKinds {
+		printDocASTReducer[kind] = unlessExtended(printDocASTReducer[kind])
+	}
+}
+
+// withComments wraps a reducer to print the comments attached to the node
+// around it: the leading ones on the lines before it, and the trailing
+// one at the end of its last line.
+func withComments(reduce visitor.VisitFunc) visitor.VisitFunc {
+	return func(p visitor.VisitFuncParams) (string, interface{}) {
+		action, result := reduce(p)
+		str, ok := result.(string)
+		if action != visitor.ActionUpdate || !ok {
+			return action, result
+		}
+		// arguments are printed on a single line
+		if len(p.Path) >= 2 && p.Path[len(p.Path)-2] == "Arguments" {
+			return action, result
+		}
+		leading, trailing := getComments(p.Node)
+		if trailing != "" {
+			str += " " + trailing
+		}
+		if len(leading) > 0 {
+			// keep the blank line before a described field above its comments
+			prefix := ""
+			if strings.HasPrefix(str, "\n") {
+				prefix, str = "\n", str[1:]
+			}
+			str = prefix + strings.Join(leading, "\n") + "\n" + str
+		}
+		return action, str
+	}
+}
+
+// getComments returns the printed comments attached to a node.
+func getComments(raw interface{}) (leading []string, trailing string) {
+	var comments []*ast.Comment
+	switch node := raw.(type) {
+	case ast.CommentedNode:
+		c := node.GetComments()
+		if c == nil {
+			return nil, ""
+		}
+		comments = c.Leading
+		if c.Trailing != nil {
+			trailing = "#" + c.Trailing.Value
+		}
+	case map[string]interface{}:
+		c, _ := node["Comments"].(map[string]interface{})
+		comments, _ = c["Leading"].([]*ast.Comment)
+		if t, ok := c["Trailing"].(map[string]interface{}); ok {
+			trailing = "#" + getMapValueString(t, "Value")
+		}
+	}
+	for _, comment := range comments {
+		leading = append(leading, "#"+comment.Value)
+	}
+	return leading, trailing
+}
+
+// Print returns the GraphQL source text of astNode as a string, or astNode
+// formatted with %v if it can't be printed. PrintString prints the same
+// text faster, and reports the nodes it can't print as errors.
 func Print(astNode ast.Node) (printed interface{}) {
 	defer func() interface{} {
 		if r := recover(); r != nil {
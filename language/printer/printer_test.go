@@ -3,6 +3,7 @@ package printer_test
 import (
 	"io/ioutil"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/graphql-go/graphql/language/ast"
@@ -121,6 +122,84 @@ func TestPrinter_CorrectlyPrintsNonQueryOperationsWithoutName(t *testing.T) {
 	}
 }
 
+func TestPrinter_PrintsVariableDefinitionDirectives(t *testing.T) {
+	query := `query ($id: ID! = "1" @deprecated, $b: Int @a(x: 1) @b) { id }`
+	expected := `query ($id: ID! = "1" @deprecated, $b: Int @a(x: 1) @b) {
+  id
+}
+`
+	results := printer.Print(parse(t, query))
+	if !reflect.DeepEqual(expected, results) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
+func TestPrinter_PrintsComments(t *testing.T) {
+	query := `# The hero query
+query Hero($id: ID) {
+  hero(id: $id) { # the hero
+    name # the name
+    ...Friends
+  }
+} # end of query
+
+"""Some type"""
+type Foo { # first
+  a: Int # a
+  "described"
+  b(x: Int # dropped
+  ): String
+}
+
+enum Episode {
+  # the first one
+  NEWHOPE
+  EMPIRE # the second one
+}
+`
+	expected := `# The hero query
+query Hero($id: ID) {
+  hero(id: $id) {
+    # the hero
+    name # the name
+    ...Friends
+  }
+} # end of query
+
+"""Some type"""
+type Foo {
+  # first
+  a: Int # a
+  
+  """described"""
+  b(x: Int): String
+}
+
+enum Episode {
+  # the first one
+  NEWHOPE
+  EMPIRE # the second one
+}
+`
+	astDoc, err := parser.Parse(parser.ParseParams{
+		Source:  query,
+		Options: parser.ParseOptions{Comments: true},
+	})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	results := printer.Print(astDoc)
+	if !reflect.DeepEqual(expected, results) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+
+	// without comments the document prints as before
+	results = printer.Print(parse(t, query))
+	if strings.Contains(results.(string), "#") {
+		t.Fatalf("expected no comments, got %v", results)
+	}
+}
+
 func TestPrinter_PrintsKitchenSink(t *testing.T) {
 	b, err := ioutil.ReadFile("../../kitchen-sink.graphql")
 	if err != nil {
@@ -125,6 +125,83 @@ directive @include(if: Boolean!) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
 	}
 }
 
+func TestSchemaPrinter_PrintsExtensions(t *testing.T) {
+	query := `extend schema @onSchema
+extend schema { subscription: SubscriptionType }
+extend scalar CustomScalar @onScalar
+extend type Foo implements Bar
+extend interface Bar { five: Int }
+extend interface Bar @onInterface
+extend union Feed = Photo | Video
+extend union Feed @onUnion
+extend enum Site { WATCH }
+extend enum Site @onEnum
+extend input InputType { other: Float }
+extend input InputType @onInputObject
+`
+	astDoc := parse(t, query)
+	expected := `extend schema @onSchema
+
+extend schema {
+  subscription: SubscriptionType
+}
+
+extend scalar CustomScalar @onScalar
+
+extend type Foo implements Bar {}
+
+extend interface Bar {
+  five: Int
+}
+
+extend interface Bar @onInterface {}
+
+extend union Feed = Photo | Video
+
+extend union Feed @onUnion
+
+extend enum Site {
+  WATCH
+}
+
+extend enum Site @onEnum {}
+
+extend input InputType {
+  other: Float
+}
+
+extend input InputType @onInputObject {}
+`
+	results := printer.Print(astDoc)
+	if !reflect.DeepEqual(expected, results) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+	if reprinted := printer.Print(parse(t, results.(string))); !reflect.DeepEqual(expected, reprinted) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, reprinted))
+	}
+}
+
+func TestSchemaPrinter_PrintsExtensionsWithEveryPart(t *testing.T) {
+	astDoc := parse(t, `extend schema @onSchema { query: QueryType }
+extend type Foo implements Bar & Baz @onType { seven(argument: [String]): Int }
+extend union Feed @onUnion = Photo | Video
+`)
+	expected := `extend schema @onSchema {
+  query: QueryType
+}
+
+extend type Foo implements Bar & Baz @onType {
+  seven(argument: [String]): Int
+}
+
+extend union Feed @onUnion = Photo | Video
+`
+	results := printer.Print(astDoc)
+	if !reflect.DeepEqual(expected, results) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
 func TestSchemaPrinter_PrintsAllDescriptions(t *testing.T) {
 	b, err := ioutil.ReadFile("../../schema-all-descriptions.graphql")
 	if err != nil {
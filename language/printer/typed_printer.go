@@ -0,0 +1,1445 @@
+package printer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/kinds"
+)
+
+// PrintOptions tunes the text PrintStringWithOptions and PrintToWithOptions
+// print. The zero value prints what Print does.
+type PrintOptions struct {
+	// Minify prints the text on a single line, without comments and
+	// without the whitespace and commas that don't separate tokens.
+	Minify bool
+
+	// IndentWidth is the number of spaces per indentation level; the
+	// default is 2.
+	IndentWidth int
+
+	// MaxLineWidth, when set, puts the arguments of fields, field
+	// definitions and directive definitions, and the variable definitions
+	// of operations, one per line when they would make their line longer.
+	MaxLineWidth int
+
+	// BlockStrings prints descriptions as they were written, as block
+	// strings or as strings, rather than always as block strings.
+	BlockStrings bool
+}
+
+const defaultIndentWidth = 2
+
+// PrintTo writes the GraphQL source text of node to w. The text is the
+// one Print returns, but PrintTo walks the typed AST directly rather than
+// through the visitor, writing the text as it goes, and it fails on nodes
+// it can't print in full, such as a field without a name or a variable
+// definition without a type, instead of printing them partially. It stops
+// at the first error, which may leave the text printed before it in w.
+func PrintTo(w io.Writer, node ast.Node) error {
+	return PrintToWithOptions(w, node, PrintOptions{})
+}
+
+// PrintToWithOptions writes the GraphQL source text of node to w, printed
+// as opts ask; see PrintTo.
+func PrintToWithOptions(w io.Writer, node ast.Node, opts PrintOptions) error {
+	if opts.IndentWidth <= 0 {
+		opts.IndentWidth = defaultIndentWidth
+	}
+	bw := bufio.NewWriter(w)
+	if err := newPrinter(bw, opts).node(node); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// PrintString returns the GraphQL source text of node; see PrintTo.
+func PrintString(node ast.Node) (string, error) {
+	return PrintStringWithOptions(node, PrintOptions{})
+}
+
+// PrintStringWithOptions returns the GraphQL source text of node, printed
+// as opts ask; see PrintTo.
+func PrintStringWithOptions(node ast.Node, opts PrintOptions) (string, error) {
+	var s strings.Builder
+	if err := PrintToWithOptions(&s, node, opts); err != nil {
+		return "", err
+	}
+	return s.String(), nil
+}
+
+// printer writes the source text of the nodes it's given to w, indenting
+// every line it starts by depth levels. Minified, it writes the space
+// between two tokens only where they would otherwise run together.
+type printer struct {
+	w        *bufio.Writer
+	opts     PrintOptions
+	indent   string
+	depth    int
+	separate bool
+	// last is the last byte written, and column the width of the line
+	// being written.
+	last   byte
+	column int
+	// holding keeps what's written in held rather than writing it to w,
+	// while wrapIfLong measures it.
+	holding bool
+	held    []byte
+}
+
+func newPrinter(w *bufio.Writer, opts PrintOptions) *printer {
+	return &printer{w: w, opts: opts, indent: strings.Repeat(" ", opts.IndentWidth)}
+}
+
+func errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("printer: "+format, args...)
+}
+
+// emit writes s as it is.
+func (p *printer) emit(s string) error {
+	if s == "" {
+		return nil
+	}
+	if p.holding {
+		p.held = append(p.held, s...)
+	} else if _, err := p.w.WriteString(s); err != nil {
+		return err
+	}
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		p.column = utf8.RuneCountInString(s[i+1:])
+	} else {
+		p.column += utf8.RuneCountInString(s)
+	}
+	p.last = s[len(s)-1]
+	return nil
+}
+
+// write writes s, a token or the text of a description, indenting the
+// lines it starts.
+func (p *printer) write(s string) error {
+	if s == "" {
+		return nil
+	}
+	if p.separate {
+		p.separate = false
+		if p.last != 0 && runTogether(p.last, s[0]) {
+			if err := p.emit(" "); err != nil {
+				return err
+			}
+		}
+	}
+	for p.depth > 0 && !p.opts.Minify {
+		i := strings.IndexByte(s, '\n')
+		if i < 0 {
+			break
+		}
+		if err := p.emit(s[:i+1]); err != nil {
+			return err
+		}
+		if err := p.writeIndent(); err != nil {
+			return err
+		}
+		s = s[i+1:]
+	}
+	return p.emit(s)
+}
+
+// runTogether reports whether a token ending with last and one starting
+// with first must be separated to be read back as two tokens.
+func runTogether(last, first byte) bool {
+	switch {
+	case isNameByte(last):
+		return isNameByte(first) || first == '.' && last >= '0' && last <= '9'
+	case last == '"':
+		return first == '"'
+	}
+	return false
+}
+
+func isNameByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z'
+}
+
+func (p *printer) writeIndent() error {
+	for i := 0; i < p.depth; i++ {
+		if err := p.emit(p.indent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// space separates two tokens on a line.
+func (p *printer) space() error {
+	if p.opts.Minify {
+		p.separate = true
+		return nil
+	}
+	return p.emit(" ")
+}
+
+// newline starts a new, indented line.
+func (p *printer) newline() error {
+	if p.opts.Minify {
+		p.separate = true
+		return nil
+	}
+	if err := p.emit("\n"); err != nil {
+		return err
+	}
+	return p.writeIndent()
+}
+
+// comma separates the items of a list on a line.
+func (p *printer) comma() error {
+	if p.opts.Minify {
+		p.separate = true
+		return nil
+	}
+	return p.emit(", ")
+}
+
+// token writes s after a space.
+func (p *printer) token(s string) error {
+	if err := p.space(); err != nil {
+		return err
+	}
+	return p.write(s)
+}
+
+// wrapIfLong writes what print writes on a single line, unless it and
+// extra more characters would make the current line longer than
+// MaxLineWidth: the single line is held back and measured from where it
+// starts, then written, or dropped for print to write the text wrapped.
+func (p *printer) wrapIfLong(wrappable bool, extra int, print func(wrap bool) error) error {
+	if !wrappable || p.opts.MaxLineWidth <= 0 || p.opts.Minify || p.holding {
+		return print(false)
+	}
+	column, last, separate := p.column, p.last, p.separate
+	p.holding = true
+	err := print(false)
+	p.holding = false
+	held := p.held
+	p.held = p.held[:0]
+	if err != nil {
+		return err
+	}
+	line := held
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line, extra = line[:i], 0
+	}
+	if column+utf8.RuneCount(line)+extra <= p.opts.MaxLineWidth {
+		_, err := p.w.Write(held)
+		return err
+	}
+	p.column, p.last, p.separate = column, last, separate
+	return print(true)
+}
+
+func (p *printer) node(node ast.Node) error {
+	switch node := node.(type) {
+	case nil:
+		return errorf("cannot print a nil node")
+	case *ast.Name:
+		if node == nil {
+			return errorf("nil %s", kinds.Name)
+		}
+		return p.write(node.Value)
+	case *ast.Document:
+		return p.document(node)
+	case *ast.SelectionSet:
+		return p.selectionSet(node)
+	case *ast.Argument:
+		return p.argument(node)
+	case *ast.ObjectField:
+		return p.objectField(node)
+	case *ast.Directive:
+		return p.directive(node)
+	case *ast.VariableDefinition:
+		return p.variableDefinition(node)
+	case *ast.FieldDefinition:
+		return p.fieldDefinition(node)
+	case *ast.InputValueDefinition:
+		return p.inputValueDefinition(node, true)
+	case *ast.EnumValueDefinition:
+		return p.enumValueDefinition(node)
+	case *ast.OperationTypeDefinition:
+		return p.operationTypeDefinition(node)
+	case *ast.Field, *ast.FragmentSpread, *ast.InlineFragment:
+		return p.selection(node.(ast.Selection))
+	case *ast.Variable, *ast.IntValue, *ast.FloatValue, *ast.StringValue, *ast.BooleanValue,
+		*ast.EnumValue, *ast.ListValue, *ast.ObjectValue:
+		return p.value(node.(ast.Value))
+	case *ast.Named, *ast.List, *ast.NonNull:
+		return p.typ(node.(ast.Type))
+	}
+	return p.definition(node)
+}
+
+func (p *printer) document(node *ast.Document) error {
+	if node == nil {
+		return errorf("nil %s", kinds.Document)
+	}
+	for i, definition := range node.Definitions {
+		if i > 0 {
+			if err := p.newline(); err != nil {
+				return err
+			}
+			if err := p.newline(); err != nil {
+				return err
+			}
+		}
+		if err := p.definition(definition); err != nil {
+			return err
+		}
+	}
+	return p.newline()
+}
+
+func (p *printer) definition(node ast.Node) error {
+	switch node := node.(type) {
+	case nil:
+		return errorf("nil definition")
+	case *ast.OperationDefinition:
+		return p.operationDefinition(node)
+	case *ast.FragmentDefinition:
+		return p.fragmentDefinition(node)
+	case *ast.SchemaDefinition:
+		return p.schemaDefinition(node, false)
+	case *ast.ScalarDefinition:
+		return p.scalarDefinition(node, false)
+	case *ast.ObjectDefinition:
+		return p.objectDefinition(node, false)
+	case *ast.InterfaceDefinition:
+		return p.interfaceDefinition(node, false)
+	case *ast.UnionDefinition:
+		return p.unionDefinition(node, false)
+	case *ast.EnumDefinition:
+		return p.enumDefinition(node, false)
+	case *ast.InputObjectDefinition:
+		return p.inputObjectDefinition(node, false)
+	case *ast.DirectiveDefinition:
+		return p.directiveDefinition(node)
+	case *ast.SchemaExtensionDefinition:
+		if node == nil {
+			return errorf("nil %s", kinds.SchemaExtensionDefinition)
+		}
+		if node.Definition == nil {
+			return errorf("%s has no Definition", kinds.SchemaExtensionDefinition)
+		}
+		return p.extension(node.Comments, func() error { return p.schemaDefinition(node.Definition, true) })
+	case *ast.ScalarExtensionDefinition:
+		if node == nil {
+			return errorf("nil %s", kinds.ScalarExtensionDefinition)
+		}
+		if node.Definition == nil {
+			return errorf("%s has no Definition", kinds.ScalarExtensionDefinition)
+		}
+		return p.extension(node.Comments, func() error { return p.scalarDefinition(node.Definition, true) })
+	case *ast.TypeExtensionDefinition:
+		if node == nil {
+			return errorf("nil %s", kinds.TypeExtensionDefinition)
+		}
+		if node.Definition == nil {
+			return errorf("%s has no Definition", kinds.TypeExtensionDefinition)
+		}
+		return p.extension(node.Comments, func() error { return p.objectDefinition(node.Definition, true) })
+	case *ast.InterfaceExtensionDefinition:
+		if node == nil {
+			return errorf("nil %s", kinds.InterfaceExtensionDefinition)
+		}
+		if node.Definition == nil {
+			return errorf("%s has no Definition", kinds.InterfaceExtensionDefinition)
+		}
+		return p.extension(node.Comments, func() error { return p.interfaceDefinition(node.Definition, true) })
+	case *ast.UnionExtensionDefinition:
+		if node == nil {
+			return errorf("nil %s", kinds.UnionExtensionDefinition)
+		}
+		if node.Definition == nil {
+			return errorf("%s has no Definition", kinds.UnionExtensionDefinition)
+		}
+		return p.extension(node.Comments, func() error { return p.unionDefinition(node.Definition, true) })
+	case *ast.EnumExtensionDefinition:
+		if node == nil {
+			return errorf("nil %s", kinds.EnumExtensionDefinition)
+		}
+		if node.Definition == nil {
+			return errorf("%s has no Definition", kinds.EnumExtensionDefinition)
+		}
+		return p.extension(node.Comments, func() error { return p.enumDefinition(node.Definition, true) })
+	case *ast.InputObjectExtensionDefinition:
+		if node == nil {
+			return errorf("nil %s", kinds.InputObjectExtensionDefinition)
+		}
+		if node.Definition == nil {
+			return errorf("%s has no Definition", kinds.InputObjectExtensionDefinition)
+		}
+		return p.extension(node.Comments, func() error { return p.inputObjectDefinition(node.Definition, true) })
+	}
+	return errorf("cannot print %T", node)
+}
+
+// name writes the name of a node of the given kind, which must have one.
+func (p *printer) name(name *ast.Name, kind string) error {
+	if name == nil || name.Value == "" {
+		return errorf("%s has no Name", kind)
+	}
+	return p.write(name.Value)
+}
+
+// leadingComments writes the comments on the lines before a node.
+func (p *printer) leadingComments(comments *ast.Comments) error {
+	if comments == nil || p.opts.Minify {
+		return nil
+	}
+	for _, comment := range comments.Leading {
+		if comment == nil {
+			return errorf("nil %s", kinds.Comment)
+		}
+		if err := p.write("#" + comment.Value); err != nil {
+			return err
+		}
+		if err := p.newline(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trailingComment writes the comment at the end of a node's last line.
+func (p *printer) trailingComment(comments *ast.Comments) error {
+	if comments != nil && comments.Trailing != nil && !p.opts.Minify {
+		return p.write(" #" + comments.Trailing.Value)
+	}
+	return nil
+}
+
+func hasDescription(description *ast.StringValue) bool {
+	return description != nil && description.Value != ""
+}
+
+// description writes a description, followed by a line break: as a block
+// string, unless BlockStrings is set and it was written as a string.
+func (p *printer) description(description *ast.StringValue) error {
+	var err error
+	switch {
+	case p.opts.BlockStrings && !description.Block:
+		err = p.write(strconv.Quote(description.Value))
+	case p.opts.BlockStrings:
+		err = p.blockString(strings.Replace(description.Value, `"""`, `\"""`, -1))
+	default:
+		err = p.blockString(description.Value)
+	}
+	if err != nil {
+		return err
+	}
+	return p.newline()
+}
+
+func (p *printer) blockString(value string) error {
+	if strings.ContainsRune(value, '\n') {
+		return p.write(`"""` + "\n" + value + "\n" + `"""`)
+	}
+	return p.write(`"""` + value + `"""`)
+}
+
+// describedItem writes what precedes an item of a type definition's block:
+// a blank line and its description, if it has one, around its comments.
+func (p *printer) describedItem(description *ast.StringValue, comments *ast.Comments) error {
+	if !hasDescription(description) {
+		return p.leadingComments(comments)
+	}
+	if err := p.newline(); err != nil {
+		return err
+	}
+	if err := p.leadingComments(comments); err != nil {
+		return err
+	}
+	return p.description(description)
+}
+
+// described writes what precedes a type system definition: its comments
+// and its description. The definition of an extension has neither; the
+// extension has the comments.
+func (p *printer) described(description *ast.StringValue, comments *ast.Comments, extension bool) error {
+	if extension {
+		return nil
+	}
+	if err := p.leadingComments(comments); err != nil {
+		return err
+	}
+	if hasDescription(description) {
+		return p.description(description)
+	}
+	return nil
+}
+
+// definitionEnd writes what follows a type system definition: its
+// trailing comment, unless it's the definition of an extension.
+func (p *printer) definitionEnd(comments *ast.Comments, extension bool) error {
+	if extension {
+		return nil
+	}
+	return p.trailingComment(comments)
+}
+
+// block writes n items, each on its own line, in an indented "{ }" block.
+func (p *printer) block(n int, item func(i int) error) error {
+	if n == 0 {
+		return p.write("{}")
+	}
+	if err := p.write("{"); err != nil {
+		return err
+	}
+	p.depth++
+	for i := 0; i < n; i++ {
+		if err := p.newline(); err != nil {
+			return err
+		}
+		if err := item(i); err != nil {
+			return err
+		}
+	}
+	p.depth--
+	if err := p.newline(); err != nil {
+		return err
+	}
+	return p.write("}")
+}
+
+// list writes n items in parentheses: on a single line, or, if wrap is
+// set, each on its own line.
+func (p *printer) list(n int, wrap bool, item func(i int) error) error {
+	if err := p.write("("); err != nil {
+		return err
+	}
+	if wrap {
+		p.depth++
+		for i := 0; i < n; i++ {
+			if err := p.newline(); err != nil {
+				return err
+			}
+			if err := item(i); err != nil {
+				return err
+			}
+		}
+		p.depth--
+		if err := p.newline(); err != nil {
+			return err
+		}
+		return p.write(")")
+	}
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			if err := p.comma(); err != nil {
+				return err
+			}
+		}
+		if err := item(i); err != nil {
+			return err
+		}
+	}
+	return p.write(")")
+}
+
+func (p *printer) directives(directives []*ast.Directive) error {
+	for i, directive := range directives {
+		if i > 0 {
+			if err := p.space(); err != nil {
+				return err
+			}
+		}
+		if err := p.directive(directive); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// spacedDirectives writes the directives, if any, after a space.
+func (p *printer) spacedDirectives(directives []*ast.Directive) error {
+	if len(directives) == 0 {
+		return nil
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	return p.directives(directives)
+}
+
+func (p *printer) directive(node *ast.Directive) error {
+	if node == nil {
+		return errorf("nil %s", kinds.Directive)
+	}
+	if err := p.write("@"); err != nil {
+		return err
+	}
+	if err := p.name(node.Name, kinds.Directive); err != nil {
+		return err
+	}
+	return p.arguments(node.Arguments, false)
+}
+
+func (p *printer) arguments(args []*ast.Argument, wrap bool) error {
+	if len(args) == 0 {
+		return nil
+	}
+	return p.list(len(args), wrap, func(i int) error {
+		return p.argument(args[i])
+	})
+}
+
+func (p *printer) argument(node *ast.Argument) error {
+	if node == nil {
+		return errorf("nil %s", kinds.Argument)
+	}
+	if err := p.name(node.Name, kinds.Argument); err != nil {
+		return err
+	}
+	if node.Value == nil {
+		return errorf("%s has no Value", kinds.Argument)
+	}
+	if err := p.write(":"); err != nil {
+		return err
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	return p.value(node.Value)
+}
+
+func (p *printer) operationDefinition(node *ast.OperationDefinition) error {
+	if node == nil {
+		return errorf("nil %s", kinds.OperationDefinition)
+	}
+	if node.SelectionSet == nil {
+		return errorf("%s has no SelectionSet", kinds.OperationDefinition)
+	}
+	if err := p.leadingComments(node.Comments); err != nil {
+		return err
+	}
+	err := p.wrapIfLong(len(node.VariableDefinitions) > 0, 1, func(wrap bool) error {
+		return p.operationHead(node, wrap)
+	})
+	if err != nil {
+		return err
+	}
+	if err := p.selectionSet(node.SelectionSet); err != nil {
+		return err
+	}
+	return p.trailingComment(node.Comments)
+}
+
+// operationHead writes what precedes the selection set of an operation.
+func (p *printer) operationHead(node *ast.OperationDefinition, wrap bool) error {
+	var name string
+	if node.Name != nil {
+		name = node.Name.Value
+	}
+	// Anonymous queries with no directives or variable definitions can use
+	// the query short form.
+	if name == "" && len(node.Directives) == 0 && len(node.VariableDefinitions) == 0 && node.Operation == ast.OperationTypeQuery {
+		return nil
+	}
+	if node.Operation != "" {
+		if err := p.write(node.Operation); err != nil {
+			return err
+		}
+		if err := p.space(); err != nil {
+			return err
+		}
+	}
+	if name != "" || len(node.VariableDefinitions) > 0 {
+		if err := p.write(name); err != nil {
+			return err
+		}
+		if err := p.variableDefinitions(node.VariableDefinitions, wrap); err != nil {
+			return err
+		}
+		if err := p.space(); err != nil {
+			return err
+		}
+	}
+	if len(node.Directives) > 0 {
+		if err := p.directives(node.Directives); err != nil {
+			return err
+		}
+		return p.space()
+	}
+	return nil
+}
+
+func (p *printer) variableDefinitions(defs []*ast.VariableDefinition, wrap bool) error {
+	if len(defs) == 0 {
+		return nil
+	}
+	return p.list(len(defs), wrap, func(i int) error {
+		return p.variableDefinition(defs[i])
+	})
+}
+
+func (p *printer) variableDefinition(node *ast.VariableDefinition) error {
+	if node == nil {
+		return errorf("nil %s", kinds.VariableDefinition)
+	}
+	if node.Variable == nil {
+		return errorf("%s has no Variable", kinds.VariableDefinition)
+	}
+	if node.Type == nil {
+		return errorf("%s has no Type", kinds.VariableDefinition)
+	}
+	if err := p.variable(node.Variable); err != nil {
+		return err
+	}
+	if err := p.write(":"); err != nil {
+		return err
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	if err := p.typ(node.Type); err != nil {
+		return err
+	}
+	if err := p.defaultValue(node.DefaultValue); err != nil {
+		return err
+	}
+	return p.spacedDirectives(node.Directives)
+}
+
+func (p *printer) defaultValue(value ast.Value) error {
+	if value == nil {
+		return nil
+	}
+	if err := p.token("="); err != nil {
+		return err
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	return p.value(value)
+}
+
+func (p *printer) selectionSet(node *ast.SelectionSet) error {
+	if node == nil {
+		return errorf("nil %s", kinds.SelectionSet)
+	}
+	return p.block(len(node.Selections), func(i int) error {
+		return p.selection(node.Selections[i])
+	})
+}
+
+func (p *printer) selection(node ast.Selection) error {
+	switch node := node.(type) {
+	case nil:
+		return errorf("nil selection")
+	case *ast.Field:
+		return p.field(node)
+	case *ast.FragmentSpread:
+		return p.fragmentSpread(node)
+	case *ast.InlineFragment:
+		return p.inlineFragment(node)
+	}
+	return errorf("cannot print %T", node)
+}
+
+func (p *printer) field(node *ast.Field) error {
+	if node == nil {
+		return errorf("nil %s", kinds.Field)
+	}
+	if err := p.leadingComments(node.Comments); err != nil {
+		return err
+	}
+	extra := 0
+	if node.SelectionSet != nil {
+		extra = len(" {")
+	}
+	err := p.wrapIfLong(len(node.Arguments) > 0, extra, func(wrap bool) error {
+		return p.fieldHead(node, wrap)
+	})
+	if err != nil {
+		return err
+	}
+	if node.SelectionSet != nil {
+		if err := p.space(); err != nil {
+			return err
+		}
+		if err := p.selectionSet(node.SelectionSet); err != nil {
+			return err
+		}
+	}
+	return p.trailingComment(node.Comments)
+}
+
+// fieldHead writes what precedes the selection set of a field.
+func (p *printer) fieldHead(node *ast.Field, wrap bool) error {
+	if node.Alias != nil && node.Alias.Value != "" {
+		if err := p.write(node.Alias.Value); err != nil {
+			return err
+		}
+		if err := p.write(":"); err != nil {
+			return err
+		}
+		if err := p.space(); err != nil {
+			return err
+		}
+	}
+	if err := p.name(node.Name, kinds.Field); err != nil {
+		return err
+	}
+	if err := p.arguments(node.Arguments, wrap); err != nil {
+		return err
+	}
+	return p.spacedDirectives(node.Directives)
+}
+
+func (p *printer) fragmentSpread(node *ast.FragmentSpread) error {
+	if node == nil {
+		return errorf("nil %s", kinds.FragmentSpread)
+	}
+	if err := p.leadingComments(node.Comments); err != nil {
+		return err
+	}
+	if err := p.write("..."); err != nil {
+		return err
+	}
+	if err := p.name(node.Name, kinds.FragmentSpread); err != nil {
+		return err
+	}
+	if err := p.spacedDirectives(node.Directives); err != nil {
+		return err
+	}
+	return p.trailingComment(node.Comments)
+}
+
+func (p *printer) inlineFragment(node *ast.InlineFragment) error {
+	if node == nil {
+		return errorf("nil %s", kinds.InlineFragment)
+	}
+	if node.SelectionSet == nil {
+		return errorf("%s has no SelectionSet", kinds.InlineFragment)
+	}
+	if err := p.leadingComments(node.Comments); err != nil {
+		return err
+	}
+	if err := p.write("..."); err != nil {
+		return err
+	}
+	if node.TypeCondition != nil {
+		if err := p.token("on"); err != nil {
+			return err
+		}
+		if err := p.space(); err != nil {
+			return err
+		}
+		if err := p.named(node.TypeCondition); err != nil {
+			return err
+		}
+	}
+	if err := p.spacedDirectives(node.Directives); err != nil {
+		return err
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	if err := p.selectionSet(node.SelectionSet); err != nil {
+		return err
+	}
+	return p.trailingComment(node.Comments)
+}
+
+func (p *printer) fragmentDefinition(node *ast.FragmentDefinition) error {
+	if node == nil {
+		return errorf("nil %s", kinds.FragmentDefinition)
+	}
+	if node.TypeCondition == nil {
+		return errorf("%s has no TypeCondition", kinds.FragmentDefinition)
+	}
+	if node.SelectionSet == nil {
+		return errorf("%s has no SelectionSet", kinds.FragmentDefinition)
+	}
+	if err := p.leadingComments(node.Comments); err != nil {
+		return err
+	}
+	if err := p.write("fragment"); err != nil {
+		return err
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	if err := p.name(node.Name, kinds.FragmentDefinition); err != nil {
+		return err
+	}
+	if err := p.token("on"); err != nil {
+		return err
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	if err := p.named(node.TypeCondition); err != nil {
+		return err
+	}
+	if err := p.spacedDirectives(node.Directives); err != nil {
+		return err
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	if err := p.selectionSet(node.SelectionSet); err != nil {
+		return err
+	}
+	return p.trailingComment(node.Comments)
+}
+
+func (p *printer) value(node ast.Value) error {
+	switch node := node.(type) {
+	case nil:
+		return errorf("nil value")
+	case *ast.Variable:
+		return p.variable(node)
+	case *ast.IntValue:
+		if node == nil {
+			return errorf("nil %s", kinds.IntValue)
+		}
+		return p.write(node.Value)
+	case *ast.FloatValue:
+		if node == nil {
+			return errorf("nil %s", kinds.FloatValue)
+		}
+		return p.write(node.Value)
+	case *ast.StringValue:
+		if node == nil {
+			return errorf("nil %s", kinds.StringValue)
+		}
+		return p.write(strconv.Quote(node.Value))
+	case *ast.BooleanValue:
+		if node == nil {
+			return errorf("nil %s", kinds.BooleanValue)
+		}
+		return p.write(strconv.FormatBool(node.Value))
+	case *ast.EnumValue:
+		if node == nil {
+			return errorf("nil %s", kinds.EnumValue)
+		}
+		return p.write(node.Value)
+	case *ast.ListValue:
+		if node == nil {
+			return errorf("nil %s", kinds.ListValue)
+		}
+		if err := p.write("["); err != nil {
+			return err
+		}
+		for i, value := range node.Values {
+			if i > 0 {
+				if err := p.comma(); err != nil {
+					return err
+				}
+			}
+			if err := p.value(value); err != nil {
+				return err
+			}
+		}
+		return p.write("]")
+	case *ast.ObjectValue:
+		if node == nil {
+			return errorf("nil %s", kinds.ObjectValue)
+		}
+		if err := p.write("{"); err != nil {
+			return err
+		}
+		for i, field := range node.Fields {
+			if i > 0 {
+				if err := p.comma(); err != nil {
+					return err
+				}
+			}
+			if err := p.objectField(field); err != nil {
+				return err
+			}
+		}
+		return p.write("}")
+	}
+	return errorf("cannot print %T", node)
+}
+
+func (p *printer) variable(node *ast.Variable) error {
+	if node == nil {
+		return errorf("nil %s", kinds.Variable)
+	}
+	if err := p.write("$"); err != nil {
+		return err
+	}
+	return p.name(node.Name, kinds.Variable)
+}
+
+func (p *printer) objectField(node *ast.ObjectField) error {
+	if node == nil {
+		return errorf("nil %s", kinds.ObjectField)
+	}
+	if err := p.name(node.Name, kinds.ObjectField); err != nil {
+		return err
+	}
+	if node.Value == nil {
+		return errorf("%s has no Value", kinds.ObjectField)
+	}
+	if err := p.write(":"); err != nil {
+		return err
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	return p.value(node.Value)
+}
+
+func (p *printer) typ(node ast.Type) error {
+	switch node := node.(type) {
+	case nil:
+		return errorf("nil type")
+	case *ast.Named:
+		return p.named(node)
+	case *ast.List:
+		if node == nil {
+			return errorf("nil %s", kinds.List)
+		}
+		if node.Type == nil {
+			return errorf("%s has no Type", kinds.List)
+		}
+		if err := p.write("["); err != nil {
+			return err
+		}
+		if err := p.typ(node.Type); err != nil {
+			return err
+		}
+		return p.write("]")
+	case *ast.NonNull:
+		if node == nil {
+			return errorf("nil %s", kinds.NonNull)
+		}
+		if node.Type == nil {
+			return errorf("%s has no Type", kinds.NonNull)
+		}
+		if err := p.typ(node.Type); err != nil {
+			return err
+		}
+		return p.write("!")
+	}
+	return errorf("cannot print %T", node)
+}
+
+func (p *printer) named(node *ast.Named) error {
+	if node == nil {
+		return errorf("nil %s", kinds.Named)
+	}
+	return p.name(node.Name, kinds.Named)
+}
+
+// schemaDefinition writes a schema definition, or the definition of a
+// schema extension, which leaves out an empty block.
+func (p *printer) schemaDefinition(node *ast.SchemaDefinition, extension bool) error {
+	if node == nil {
+		return errorf("nil %s", kinds.SchemaDefinition)
+	}
+	if err := p.described(nil, node.Comments, extension); err != nil {
+		return err
+	}
+	if err := p.write("schema"); err != nil {
+		return err
+	}
+	if err := p.spacedDirectives(node.Directives); err != nil {
+		return err
+	}
+	if !extension || len(node.OperationTypes) > 0 {
+		if err := p.space(); err != nil {
+			return err
+		}
+		err := p.block(len(node.OperationTypes), func(i int) error {
+			return p.operationTypeDefinition(node.OperationTypes[i])
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return p.definitionEnd(node.Comments, extension)
+}
+
+func (p *printer) operationTypeDefinition(node *ast.OperationTypeDefinition) error {
+	if node == nil {
+		return errorf("nil %s", kinds.OperationTypeDefinition)
+	}
+	if node.Type == nil {
+		return errorf("%s has no Type", kinds.OperationTypeDefinition)
+	}
+	if err := p.leadingComments(node.Comments); err != nil {
+		return err
+	}
+	if err := p.write(node.Operation); err != nil {
+		return err
+	}
+	if err := p.write(":"); err != nil {
+		return err
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	if err := p.named(node.Type); err != nil {
+		return err
+	}
+	return p.trailingComment(node.Comments)
+}
+
+// typeHead writes the keyword and the name of a type definition, after its
+// description and comments.
+func (p *printer) typeHead(keyword string, description *ast.StringValue, comments *ast.Comments, name *ast.Name, kind string, extension bool) error {
+	if err := p.described(description, comments, extension); err != nil {
+		return err
+	}
+	if err := p.write(keyword); err != nil {
+		return err
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	return p.name(name, kind)
+}
+
+func (p *printer) scalarDefinition(node *ast.ScalarDefinition, extension bool) error {
+	if node == nil {
+		return errorf("nil %s", kinds.ScalarDefinition)
+	}
+	if err := p.typeHead("scalar", node.Description, node.Comments, node.Name, kinds.ScalarDefinition, extension); err != nil {
+		return err
+	}
+	if err := p.spacedDirectives(node.Directives); err != nil {
+		return err
+	}
+	return p.definitionEnd(node.Comments, extension)
+}
+
+func (p *printer) objectDefinition(node *ast.ObjectDefinition, extension bool) error {
+	if node == nil {
+		return errorf("nil %s", kinds.ObjectDefinition)
+	}
+	if err := p.typeHead("type", node.Description, node.Comments, node.Name, kinds.ObjectDefinition, extension); err != nil {
+		return err
+	}
+	for i, iface := range node.Interfaces {
+		keyword := "&"
+		if i == 0 {
+			keyword = "implements"
+		}
+		if err := p.token(keyword); err != nil {
+			return err
+		}
+		if err := p.space(); err != nil {
+			return err
+		}
+		if err := p.named(iface); err != nil {
+			return err
+		}
+	}
+	if err := p.spacedDirectives(node.Directives); err != nil {
+		return err
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	if err := p.fieldDefinitions(node.Fields); err != nil {
+		return err
+	}
+	return p.definitionEnd(node.Comments, extension)
+}
+
+func (p *printer) fieldDefinitions(fields []*ast.FieldDefinition) error {
+	return p.block(len(fields), func(i int) error {
+		return p.fieldDefinition(fields[i])
+	})
+}
+
+func (p *printer) fieldDefinition(node *ast.FieldDefinition) error {
+	if node == nil {
+		return errorf("nil %s", kinds.FieldDefinition)
+	}
+	if node.Type == nil {
+		return errorf("%s has no Type", kinds.FieldDefinition)
+	}
+	if err := p.describedItem(node.Description, node.Comments); err != nil {
+		return err
+	}
+	err := p.wrapIfLong(len(node.Arguments) > 0, 0, func(wrap bool) error {
+		return p.fieldDefinitionLine(node, wrap)
+	})
+	if err != nil {
+		return err
+	}
+	return p.trailingComment(node.Comments)
+}
+
+// fieldDefinitionLine writes a field definition but for its description
+// and comments.
+func (p *printer) fieldDefinitionLine(node *ast.FieldDefinition, wrap bool) error {
+	if err := p.name(node.Name, kinds.FieldDefinition); err != nil {
+		return err
+	}
+	if err := p.argumentDefinitions(node.Arguments, wrap); err != nil {
+		return err
+	}
+	if err := p.write(":"); err != nil {
+		return err
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	if err := p.typ(node.Type); err != nil {
+		return err
+	}
+	return p.spacedDirectives(node.Directives)
+}
+
+// argumentDefinitions writes the arguments of a field or a directive: on
+// a single line, unless wrap is set or one of them has a description.
+func (p *printer) argumentDefinitions(args []*ast.InputValueDefinition, wrap bool) error {
+	if len(args) == 0 {
+		return nil
+	}
+	for _, arg := range args {
+		if arg != nil && hasDescription(arg.Description) {
+			wrap = true
+			break
+		}
+	}
+	return p.list(len(args), wrap, func(i int) error {
+		return p.inputValueDefinition(args[i], false)
+	})
+}
+
+// inputValueDefinition writes an argument or an input field definition;
+// the comments of arguments are left out, as they are printed on a single
+// line.
+func (p *printer) inputValueDefinition(node *ast.InputValueDefinition, comments bool) error {
+	if node == nil {
+		return errorf("nil %s", kinds.InputValueDefinition)
+	}
+	if node.Type == nil {
+		return errorf("%s has no Type", kinds.InputValueDefinition)
+	}
+	var c *ast.Comments
+	if comments {
+		c = node.Comments
+	}
+	if err := p.describedItem(node.Description, c); err != nil {
+		return err
+	}
+	if err := p.name(node.Name, kinds.InputValueDefinition); err != nil {
+		return err
+	}
+	if err := p.write(":"); err != nil {
+		return err
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	if err := p.typ(node.Type); err != nil {
+		return err
+	}
+	if err := p.defaultValue(node.DefaultValue); err != nil {
+		return err
+	}
+	if err := p.spacedDirectives(node.Directives); err != nil {
+		return err
+	}
+	return p.trailingComment(c)
+}
+
+func (p *printer) interfaceDefinition(node *ast.InterfaceDefinition, extension bool) error {
+	if node == nil {
+		return errorf("nil %s", kinds.InterfaceDefinition)
+	}
+	if err := p.typeHead("interface", node.Description, node.Comments, node.Name, kinds.InterfaceDefinition, extension); err != nil {
+		return err
+	}
+	if err := p.spacedDirectives(node.Directives); err != nil {
+		return err
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	if err := p.fieldDefinitions(node.Fields); err != nil {
+		return err
+	}
+	return p.definitionEnd(node.Comments, extension)
+}
+
+// unionDefinition writes a union definition, or the definition of a union
+// extension, which leaves out "=" when it adds no members.
+func (p *printer) unionDefinition(node *ast.UnionDefinition, extension bool) error {
+	if node == nil {
+		return errorf("nil %s", kinds.UnionDefinition)
+	}
+	if err := p.typeHead("union", node.Description, node.Comments, node.Name, kinds.UnionDefinition, extension); err != nil {
+		return err
+	}
+	if err := p.spacedDirectives(node.Directives); err != nil {
+		return err
+	}
+	if !extension || len(node.Types) > 0 {
+		if err := p.token("="); err != nil {
+			return err
+		}
+		if len(node.Types) == 0 && !p.opts.Minify {
+			if err := p.space(); err != nil {
+				return err
+			}
+		}
+	}
+	for i, t := range node.Types {
+		if i > 0 {
+			if err := p.token("|"); err != nil {
+				return err
+			}
+		}
+		if err := p.space(); err != nil {
+			return err
+		}
+		if err := p.named(t); err != nil {
+			return err
+		}
+	}
+	return p.definitionEnd(node.Comments, extension)
+}
+
+func (p *printer) enumDefinition(node *ast.EnumDefinition, extension bool) error {
+	if node == nil {
+		return errorf("nil %s", kinds.EnumDefinition)
+	}
+	if err := p.typeHead("enum", node.Description, node.Comments, node.Name, kinds.EnumDefinition, extension); err != nil {
+		return err
+	}
+	if err := p.spacedDirectives(node.Directives); err != nil {
+		return err
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	err := p.block(len(node.Values), func(i int) error {
+		return p.enumValueDefinition(node.Values[i])
+	})
+	if err != nil {
+		return err
+	}
+	return p.definitionEnd(node.Comments, extension)
+}
+
+func (p *printer) enumValueDefinition(node *ast.EnumValueDefinition) error {
+	if node == nil {
+		return errorf("nil %s", kinds.EnumValueDefinition)
+	}
+	if err := p.describedItem(node.Description, node.Comments); err != nil {
+		return err
+	}
+	if err := p.name(node.Name, kinds.EnumValueDefinition); err != nil {
+		return err
+	}
+	if err := p.spacedDirectives(node.Directives); err != nil {
+		return err
+	}
+	return p.trailingComment(node.Comments)
+}
+
+func (p *printer) inputObjectDefinition(node *ast.InputObjectDefinition, extension bool) error {
+	if node == nil {
+		return errorf("nil %s", kinds.InputObjectDefinition)
+	}
+	if err := p.typeHead("input", node.Description, node.Comments, node.Name, kinds.InputObjectDefinition, extension); err != nil {
+		return err
+	}
+	if err := p.spacedDirectives(node.Directives); err != nil {
+		return err
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	err := p.block(len(node.Fields), func(i int) error {
+		return p.inputValueDefinition(node.Fields[i], true)
+	})
+	if err != nil {
+		return err
+	}
+	return p.definitionEnd(node.Comments, extension)
+}
+
+func (p *printer) directiveDefinition(node *ast.DirectiveDefinition) error {
+	if node == nil {
+		return errorf("nil %s", kinds.DirectiveDefinition)
+	}
+	if err := p.described(node.Description, node.Comments, false); err != nil {
+		return err
+	}
+	err := p.wrapIfLong(len(node.Arguments) > 0, 0, func(wrap bool) error {
+		return p.directiveDefinitionLine(node, wrap)
+	})
+	if err != nil {
+		return err
+	}
+	return p.trailingComment(node.Comments)
+}
+
+// directiveDefinitionLine writes a directive definition but for its
+// description and comments.
+func (p *printer) directiveDefinitionLine(node *ast.DirectiveDefinition, wrap bool) error {
+	if err := p.write("directive"); err != nil {
+		return err
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	if err := p.write("@"); err != nil {
+		return err
+	}
+	if err := p.name(node.Name, kinds.DirectiveDefinition); err != nil {
+		return err
+	}
+	if err := p.argumentDefinitions(node.Arguments, wrap); err != nil {
+		return err
+	}
+	if err := p.token("on"); err != nil {
+		return err
+	}
+	for i, location := range node.Locations {
+		if i > 0 {
+			if err := p.token("|"); err != nil {
+				return err
+			}
+		}
+		if err := p.space(); err != nil {
+			return err
+		}
+		if err := p.name(location, kinds.DirectiveDefinition+" location"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extension writes the extension of a type system definition, printed by
+// definition, with the comments of the extension.
+func (p *printer) extension(comments *ast.Comments, definition func() error) error {
+	if err := p.leadingComments(comments); err != nil {
+		return err
+	}
+	if err := p.write("extend"); err != nil {
+		return err
+	}
+	if err := p.space(); err != nil {
+		return err
+	}
+	if err := definition(); err != nil {
+		return err
+	}
+	return p.trailingComment(comments)
+}
@@ -0,0 +1,422 @@
+package printer_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/printer"
+	"github.com/graphql-go/graphql/testutil"
+)
+
+func expectPrintsLikePrint(t *testing.T, node ast.Node) {
+	t.Helper()
+	expected := printer.Print(node)
+	results, err := printer.PrintString(node)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
+func TestPrintString_PrintsLikePrint(t *testing.T) {
+	for _, file := range []string{
+		"../../kitchen-sink.graphql",
+		"../../schema-kitchen-sink.graphql",
+		"../../schema-all-descriptions.graphql",
+	} {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatalf("unable to load %s", file)
+		}
+		expectPrintsLikePrint(t, parse(t, string(b)))
+	}
+
+	sources := []string{
+		`{ a }`,
+		`query { a }`,
+		`mutation { a }`,
+		`query Q($a: [Int!]! = [1, 2], $b: In = {a: "x\ny", b: true} @d) @dir { ...F ... on T @x { b } ... { c } }`,
+		`fragment F on T @d(a: $a) { alias: f(a: 1.5, b: ENUM, c: "s") @skip(if: false) { g } }`,
+		`schema @d { query: Q mutation: M }`,
+		`extend schema @onSchema
+extend schema { subscription: S }
+extend type Foo implements Bar & Baz @d
+extend union Feed = Photo | Video
+extend union Feed @onUnion
+extend input I { a: Int = 1 @d }`,
+		`"""
+multi
+line
+"""
+type Foo {
+  """field"""
+  a(
+    """arg"""
+    x: Int = 1
+    y: String
+  ): Int
+  b(x: Int, y: Int @d): [Int]!
+}
+
+"""directive"""
+directive @d(
+  """the argument"""
+  a: Int
+) on FIELD | QUERY`,
+		`enum E { "a" A B @d }`,
+		`union U @d = A | B`,
+		`input I { a: Int, b: [String!] = ["x"] }`,
+		`interface I @d { a: Int }`,
+	}
+	for _, source := range sources {
+		expectPrintsLikePrint(t, parse(t, source))
+	}
+
+	// comments
+	b, err := ioutil.ReadFile("../../kitchen-sink.graphql")
+	if err != nil {
+		t.Fatalf("unable to load kitchen-sink.graphql")
+	}
+	for _, source := range append(sources, string(b), `# The hero query
+query Hero($id: ID) {
+  hero(id: $id) { # the hero
+    name # the name
+    ...Friends
+  }
+} # end of query
+
+"""Some type"""
+type Foo { # first
+  a: Int # a
+  "described"
+  b(x: Int # dropped
+  ): String
+}
+
+# an extension
+extend input I {
+  # the field
+  a: Int
+}
+`) {
+		astDoc, err := parser.Parse(parser.ParseParams{
+			Source:  source,
+			Options: parser.ParseOptions{Comments: true},
+		})
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		expectPrintsLikePrint(t, astDoc)
+	}
+}
+
+func TestPrintString_PrintsNodes(t *testing.T) {
+	astDoc := parse(t, `query Q($a: Int = 1) { f(a: {b: [1]}) @d { g } }`)
+	op := astDoc.Definitions[0].(*ast.OperationDefinition)
+	field := op.SelectionSet.Selections[0].(*ast.Field)
+	for _, node := range []ast.Node{
+		op,
+		op.VariableDefinitions[0],
+		op.VariableDefinitions[0].Type,
+		op.SelectionSet,
+		field,
+		field.Arguments[0],
+		field.Arguments[0].Value,
+		field.Directives[0],
+		field.Name,
+	} {
+		expectPrintsLikePrint(t, node)
+	}
+}
+
+func TestPrintTo_WritesSource(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printer.PrintTo(&buf, parse(t, `query Q { a }`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "query Q {\n  a\n}\n"
+	if buf.String() != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, buf.String()))
+	}
+}
+
+func TestPrintString_ReportsIncompleteNodes(t *testing.T) {
+	tests := []struct {
+		node     ast.Node
+		expected string
+	}{
+		{nil, "printer: cannot print a nil node"},
+		{(*ast.Field)(nil), "printer: nil Field"},
+		{ast.NewField(nil), "printer: Field has no Name"},
+		{ast.NewField(&ast.Field{Name: ast.NewName(&ast.Name{Value: ""})}), "printer: Field has no Name"},
+		{ast.NewOperationDefinition(&ast.OperationDefinition{Operation: "query"}), "printer: OperationDefinition has no SelectionSet"},
+		{ast.NewSelectionSet(&ast.SelectionSet{
+			Selections: []ast.Selection{nil},
+		}), "printer: nil selection"},
+		{ast.NewVariableDefinition(&ast.VariableDefinition{
+			Variable: ast.NewVariable(&ast.Variable{Name: ast.NewName(&ast.Name{Value: "a"})}),
+		}), "printer: VariableDefinition has no Type"},
+		{ast.NewArgument(&ast.Argument{Name: ast.NewName(&ast.Name{Value: "a"})}), "printer: Argument has no Value"},
+		{ast.NewDocument(&ast.Document{
+			Definitions: []ast.Node{ast.NewTypeExtensionDefinition(nil)},
+		}), "printer: TypeExtensionDefinition has no Definition"},
+		{ast.NewDocument(&ast.Document{
+			Definitions: []ast.Node{ast.NewName(nil)},
+		}), "printer: cannot print *ast.Name"},
+	}
+	for _, test := range tests {
+		results, err := printer.PrintString(test.node)
+		if err == nil || err.Error() != test.expected {
+			t.Fatalf("expected error %q, got %v", test.expected, err)
+		}
+		if results != "" {
+			t.Fatalf("expected no output, got %q", results)
+		}
+		var buf bytes.Buffer
+		if err := printer.PrintTo(&buf, test.node); err == nil || buf.Len() != 0 {
+			t.Fatalf("expected PrintTo to fail without writing, got %v, %q", err, buf.String())
+		}
+	}
+}
+
+// failingWriter fails every write after the first n bytes.
+type failingWriter struct {
+	n      int
+	writes int
+}
+
+func (w *failingWriter) Write(b []byte) (int, error) {
+	w.writes++
+	if len(b) > w.n {
+		n := w.n
+		w.n = 0
+		return n, errors.New("write failed")
+	}
+	w.n -= len(b)
+	return len(b), nil
+}
+
+func TestPrintTo_StreamsAndStopsAtTheFirstWriteError(t *testing.T) {
+	b, err := ioutil.ReadFile("../../kitchen-sink.graphql")
+	if err != nil {
+		t.Fatalf("unable to load kitchen-sink.graphql")
+	}
+	astDoc := parse(t, strings.Repeat(string(b), 20))
+	expected, err := printer.PrintString(astDoc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the text reaches w in pieces as it's printed
+	w := &failingWriter{n: len(expected)}
+	if err := printer.PrintTo(w, astDoc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.writes < 2 {
+		t.Fatalf("expected the text to be written as it's printed, got %d writes", w.writes)
+	}
+
+	w = &failingWriter{n: 100}
+	if err := printer.PrintTo(w, astDoc); err == nil || err.Error() != "write failed" {
+		t.Fatalf("expected the write error, got %v", err)
+	}
+	if w.writes != 1 {
+		t.Fatalf("expected printing to stop at the failed write, got %d writes", w.writes)
+	}
+}
+
+func BenchmarkPrintString(b *testing.B) {
+	q, err := ioutil.ReadFile("../../kitchen-sink.graphql")
+	if err != nil {
+		b.Fatalf("unable to load kitchen-sink.graphql")
+	}
+
+	query := string(q)
+
+	astDoc, err := parser.Parse(parser.ParseParams{
+		Source: query,
+		Options: parser.ParseOptions{
+			NoLocation: true,
+		},
+	})
+	if err != nil {
+		b.Fatalf("Parse failed: %v", err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := printer.PrintString(astDoc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestPrintStringWithOptions_Minify(t *testing.T) {
+	astDoc, err := parser.Parse(parser.ParseParams{
+		Source: `# The hero query
+query Hero($id: ID, $ep: [Episode!] = [NEWHOPE, EMPIRE]) @cached {
+  hero(id: $id, ids: [1, 2], x: 1.5, s: "", t: "x") { # the hero
+    name # the name
+    ...Friends
+    ... on Droid @include(if: true) { primaryFunction }
+  }
+}
+
+fragment Friends on Character { friends { name } }
+`,
+		Options: parser.ParseOptions{Comments: true},
+	})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	expected := `query Hero($id:ID$ep:[Episode!]=[NEWHOPE EMPIRE])@cached{hero(id:$id ids:[1 2]x:1.5 s:""t:"x"){name...Friends...on Droid@include(if:true){primaryFunction}}}fragment Friends on Character{friends{name}}`
+	results, err := printer.PrintStringWithOptions(astDoc, printer.PrintOptions{Minify: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+
+	// minified documents read back as the documents they were printed from
+	for _, file := range []string{
+		"../../kitchen-sink.graphql",
+		"../../schema-kitchen-sink.graphql",
+		"../../schema-all-descriptions.graphql",
+	} {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatalf("unable to load %s", file)
+		}
+		expected := printer.Print(parse(t, string(b)))
+		minified, err := printer.PrintStringWithOptions(parse(t, string(b)), printer.PrintOptions{Minify: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if strings.Contains(minified, "\n  ") || strings.Contains(minified, ",") {
+			t.Fatalf("expected %s to be minified, got %v", file, minified)
+		}
+		if results := printer.Print(parse(t, minified)); results != expected {
+			t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+		}
+	}
+}
+
+func TestPrintStringWithOptions_IndentWidth(t *testing.T) {
+	astDoc := parse(t, `{ a { b(x: 1) } } type Foo { "described" c: Int }`)
+	expected := `{
+    a {
+        b(x: 1)
+    }
+}
+
+type Foo {
+    
+    """described"""
+    c: Int
+}
+`
+	results, err := printer.PrintStringWithOptions(astDoc, printer.PrintOptions{IndentWidth: 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
+func TestPrintStringWithOptions_MaxLineWidth(t *testing.T) {
+	astDoc := parse(t, `
+query Search($text: String!, $first: Int = 10) {
+  search(text: $text, first: $first) @include(if: true) { id }
+  short(a: 1) { id }
+}
+
+type Query {
+  search(text: String!, first: Int = 10): [Result]
+  id(a: Int): ID
+}
+
+directive @cost(complexity: Int, multipliers: [String]) on FIELD_DEFINITION
+`)
+	expected := `query Search(
+  $text: String!
+  $first: Int = 10
+) {
+  search(
+    text: $text
+    first: $first
+  ) @include(if: true) {
+    id
+  }
+  short(a: 1) {
+    id
+  }
+}
+
+type Query {
+  search(
+    text: String!
+    first: Int = 10
+  ): [Result]
+  id(a: Int): ID
+}
+
+directive @cost(
+  complexity: Int
+  multipliers: [String]
+) on FIELD_DEFINITION
+`
+	results, err := printer.PrintStringWithOptions(astDoc, printer.PrintOptions{MaxLineWidth: 40})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
+func TestPrintStringWithOptions_BlockStrings(t *testing.T) {
+	astDoc := parse(t, `
+"A \"quoted\" type"
+type Foo {
+  """
+  A field
+  on two lines
+  """
+  a: Int
+  """Says \""" """
+  b: Int
+}
+`)
+	expected := `"A \"quoted\" type"
+type Foo {
+  
+  """
+  A field
+  on two lines
+  """
+  a: Int
+  
+  """Says \""" """
+  b: Int
+}
+`
+	results, err := printer.PrintStringWithOptions(astDoc, printer.PrintOptions{BlockStrings: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+	if reprinted, _ := printer.PrintStringWithOptions(parse(t, results), printer.PrintOptions{BlockStrings: true}); reprinted != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, reprinted))
+	}
+}
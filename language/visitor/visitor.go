@@ -30,6 +30,7 @@ var QueryDocumentKeys = KeyMap{
 		"Variable",
 		"Type",
 		"DefaultValue",
+		"Directives",
 	},
 	"Variable":     []string{"Name"},
 	"SelectionSet": []string{"Selections"},
@@ -135,7 +136,13 @@ var QueryDocumentKeys = KeyMap{
 		"Fields",
 	},
 
-	"TypeExtensionDefinition": []string{"Definition"},
+	"TypeExtensionDefinition":        []string{"Definition"},
+	"SchemaExtensionDefinition":      []string{"Definition"},
+	"ScalarExtensionDefinition":      []string{"Definition"},
+	"InterfaceExtensionDefinition":   []string{"Definition"},
+	"UnionExtensionDefinition":       []string{"Definition"},
+	"EnumExtensionDefinition":        []string{"Definition"},
+	"InputObjectExtensionDefinition": []string{"Definition"},
 
 	"DirectiveDefinition": []string{"Name", "Arguments", "Locations"},
 }
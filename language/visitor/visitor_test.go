@@ -824,6 +824,75 @@ func TestVisitor_VisitsKitchenSink(t *testing.T) {
 	}
 }
 
+func TestVisitor_VisitsTypeSystemExtensions(t *testing.T) {
+
+	query := `extend scalar S @a extend enum E { V } extend union U = T`
+	astDoc := parse(t, query)
+
+	visited := []interface{}{}
+	expectedVisited := []interface{}{
+		[]interface{}{"enter", "Document", nil},
+		[]interface{}{"enter", "ScalarExtensionDefinition", nil},
+		[]interface{}{"enter", "ScalarDefinition", nil},
+		[]interface{}{"enter", "Name", "S"},
+		[]interface{}{"leave", "Name", "S"},
+		[]interface{}{"enter", "Directive", nil},
+		[]interface{}{"enter", "Name", "a"},
+		[]interface{}{"leave", "Name", "a"},
+		[]interface{}{"leave", "Directive", nil},
+		[]interface{}{"leave", "ScalarDefinition", nil},
+		[]interface{}{"leave", "ScalarExtensionDefinition", nil},
+		[]interface{}{"enter", "EnumExtensionDefinition", nil},
+		[]interface{}{"enter", "EnumDefinition", nil},
+		[]interface{}{"enter", "Name", "E"},
+		[]interface{}{"leave", "Name", "E"},
+		[]interface{}{"enter", "EnumValueDefinition", nil},
+		[]interface{}{"enter", "Name", "V"},
+		[]interface{}{"leave", "Name", "V"},
+		[]interface{}{"leave", "EnumValueDefinition", nil},
+		[]interface{}{"leave", "EnumDefinition", nil},
+		[]interface{}{"leave", "EnumExtensionDefinition", nil},
+		[]interface{}{"enter", "UnionExtensionDefinition", nil},
+		[]interface{}{"enter", "UnionDefinition", nil},
+		[]interface{}{"enter", "Name", "U"},
+		[]interface{}{"leave", "Name", "U"},
+		[]interface{}{"enter", "Named", nil},
+		[]interface{}{"enter", "Name", "T"},
+		[]interface{}{"leave", "Name", "T"},
+		[]interface{}{"leave", "Named", nil},
+		[]interface{}{"leave", "UnionDefinition", nil},
+		[]interface{}{"leave", "UnionExtensionDefinition", nil},
+		[]interface{}{"leave", "Document", nil},
+	}
+
+	v := &visitor.VisitorOptions{
+		Enter: func(p visitor.VisitFuncParams) (string, interface{}) {
+			switch node := p.Node.(type) {
+			case *ast.Name:
+				visited = append(visited, []interface{}{"enter", node.Kind, node.Value})
+			case ast.Node:
+				visited = append(visited, []interface{}{"enter", node.GetKind(), nil})
+			}
+			return visitor.ActionNoChange, nil
+		},
+		Leave: func(p visitor.VisitFuncParams) (string, interface{}) {
+			switch node := p.Node.(type) {
+			case *ast.Name:
+				visited = append(visited, []interface{}{"leave", node.Kind, node.Value})
+			case ast.Node:
+				visited = append(visited, []interface{}{"leave", node.GetKind(), nil})
+			}
+			return visitor.ActionNoChange, nil
+		},
+	}
+
+	_ = visitor.Visit(astDoc, v, nil)
+
+	if !reflect.DeepEqual(visited, expectedVisited) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expectedVisited, visited))
+	}
+}
+
 func TestVisitor_VisitInParallel_AllowsSkippingASubTree(t *testing.T) {
 
 	// Note: nearly identical to the above test of the same test but
@@ -0,0 +1,249 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// Metrics receives the measurements of the engine, for export to a
+// metrics backend such as Prometheus. Set it with SchemaConfig.Metrics or
+// Schema.SetMetrics; schemas without one aren't measured. Implementations
+// must be safe for concurrent use.
+//
+// An operation is a request run with Do, an ExecutePlan call not made by
+// Do (each event of a subscription is one), or a request a PlanCache
+// rejects. Each is reported once, and each error of its result after it.
+type Metrics interface {
+	// OperationCompleted is called when the result of an operation is
+	// ready. operationType is empty for requests rejected before
+	// execution.
+	OperationCompleted(operationName, operationType string, duration time.Duration, errorCount int)
+
+	// ErrorReported is called for every error of an operation's result
+	// with its `extensions.code`; errors without one get
+	// ErrCodeParseFailed or ErrCodeValidationFailed if they rejected the
+	// request, and an empty code otherwise.
+	ErrorReported(code string)
+
+	// FieldResolved is called when a resolver returns, or panics.
+	FieldResolved(parentType, fieldName string, duration time.Duration, err error)
+
+	// PlanCacheLookup is called for every PlanCache lookup that isn't
+	// bypassed.
+	PlanCacheLookup(hit bool)
+
+	// PlanCacheEvicted is called for every entry a PlanCache drops,
+	// whether to stay under MaxEntries or because its schema was rebuilt.
+	PlanCacheEvicted()
+}
+
+// Codes reported to Metrics.ErrorReported for requests rejected by the
+// parser or the validator.
+const (
+	ErrCodeParseFailed      = "GRAPHQL_PARSE_FAILED"
+	ErrCodeValidationFailed = "GRAPHQL_VALIDATION_FAILED"
+)
+
+// operationReport lets Do report the operations it runs itself: Do puts
+// one in the request's context, and the ExecutePlan call it makes claims
+// it rather than reporting the operation again. Resolvers may run
+// ExecutePlan with the request's context concurrently, so the report is
+// claimed atomically and its fields are guarded by mu.
+type operationReport struct {
+	claimed atomic.Bool
+
+	mu            sync.Mutex
+	operationName string
+	operationType string
+	errorCode     string
+}
+
+type operationReportKey struct{}
+
+// claimOperationReport returns the report of the Do call ctx belongs to,
+// or nil if there is none or it was claimed already.
+func claimOperationReport(ctx context.Context) *operationReport {
+	if ctx == nil {
+		return nil
+	}
+	report, _ := ctx.Value(operationReportKey{}).(*operationReport)
+	if report == nil || !report.claimed.CompareAndSwap(false, true) {
+		return nil
+	}
+	return report
+}
+
+// setOperationErrorCode sets the code of the errors of a request Do
+// rejects.
+func setOperationErrorCode(ctx context.Context, code string) {
+	if ctx == nil {
+		return
+	}
+	if report, _ := ctx.Value(operationReportKey{}).(*operationReport); report != nil {
+		report.setErrorCode(code)
+	}
+}
+
+func (r *operationReport) setOperation(operationName, operationType string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.operationName = operationName
+	r.operationType = operationType
+}
+
+func (r *operationReport) setErrorCode(code string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errorCode = code
+}
+
+func (r *operationReport) report(metrics Metrics, start time.Time, errs []gqlerrors.FormattedError) {
+	r.mu.Lock()
+	operationName, operationType, errorCode := r.operationName, r.operationType, r.errorCode
+	r.mu.Unlock()
+	reportOperation(metrics, operationName, operationType, start, errs, errorCode)
+}
+
+func reportOperation(metrics Metrics, operationName, operationType string, start time.Time, errs []gqlerrors.FormattedError, errorCode string) {
+	metrics.OperationCompleted(operationName, operationType, time.Since(start), len(errs))
+	for _, err := range errs {
+		code := errorCode
+		if c, ok := err.Extensions["code"].(string); ok {
+			code = c
+		}
+		metrics.ErrorReported(code)
+	}
+}
+
+func schemaMetrics(schema *Schema) Metrics {
+	if schema == nil {
+		return nil
+	}
+	return schema.metrics
+}
+
+// MetricsRecorder is a Metrics keeping its measurements in memory, for
+// tests.
+type MetricsRecorder struct {
+	mu       sync.Mutex
+	snapshot MetricsSnapshot
+}
+
+// MetricsSnapshot is a copy of the measurements of a MetricsRecorder.
+type MetricsSnapshot struct {
+	// Operations counts the operations by name and type.
+	Operations map[OperationKey]uint64
+	// Errors counts the errors by code.
+	Errors map[string]uint64
+	// Fields holds the resolver measurements by "Type.field".
+	Fields map[string]FieldStats
+
+	PlanCacheHits      uint64
+	PlanCacheMisses    uint64
+	PlanCacheEvictions uint64
+}
+
+// OperationKey identifies the operations counted together.
+type OperationKey struct {
+	Name string
+	Type string
+}
+
+// FieldStats are the measurements of a field's resolver.
+type FieldStats struct {
+	Count  uint64
+	Errors uint64
+	Total  time.Duration
+	Max    time.Duration
+}
+
+// NewMetricsRecorder returns an empty MetricsRecorder.
+func NewMetricsRecorder() *MetricsRecorder {
+	r := &MetricsRecorder{}
+	r.Reset()
+	return r
+}
+
+// OperationCompleted counts the operation.
+func (r *MetricsRecorder) OperationCompleted(operationName, operationType string, duration time.Duration, errorCount int) {
+	r.mu.Lock()
+	r.snapshot.Operations[OperationKey{operationName, operationType}]++
+	r.mu.Unlock()
+}
+
+// ErrorReported counts the error.
+func (r *MetricsRecorder) ErrorReported(code string) {
+	r.mu.Lock()
+	r.snapshot.Errors[code]++
+	r.mu.Unlock()
+}
+
+// FieldResolved adds the resolver call to the stats of its field.
+func (r *MetricsRecorder) FieldResolved(parentType, fieldName string, duration time.Duration, err error) {
+	key := parentType + "." + fieldName
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := r.snapshot.Fields[key]
+	stats.Count++
+	if err != nil {
+		stats.Errors++
+	}
+	stats.Total += duration
+	if duration > stats.Max {
+		stats.Max = duration
+	}
+	r.snapshot.Fields[key] = stats
+}
+
+// PlanCacheLookup counts the hit or the miss.
+func (r *MetricsRecorder) PlanCacheLookup(hit bool) {
+	r.mu.Lock()
+	if hit {
+		r.snapshot.PlanCacheHits++
+	} else {
+		r.snapshot.PlanCacheMisses++
+	}
+	r.mu.Unlock()
+}
+
+// PlanCacheEvicted counts the eviction.
+func (r *MetricsRecorder) PlanCacheEvicted() {
+	r.mu.Lock()
+	r.snapshot.PlanCacheEvictions++
+	r.mu.Unlock()
+}
+
+// Snapshot returns a copy of the measurements so far.
+func (r *MetricsRecorder) Snapshot() MetricsSnapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.snapshot
+	s.Operations = make(map[OperationKey]uint64, len(r.snapshot.Operations))
+	for k, v := range r.snapshot.Operations {
+		s.Operations[k] = v
+	}
+	s.Errors = make(map[string]uint64, len(r.snapshot.Errors))
+	for k, v := range r.snapshot.Errors {
+		s.Errors[k] = v
+	}
+	s.Fields = make(map[string]FieldStats, len(r.snapshot.Fields))
+	for k, v := range r.snapshot.Fields {
+		s.Fields[k] = v
+	}
+	return s
+}
+
+// Reset forgets the measurements.
+func (r *MetricsRecorder) Reset() {
+	r.mu.Lock()
+	r.snapshot = MetricsSnapshot{
+		Operations: map[OperationKey]uint64{},
+		Errors:     map[string]uint64{},
+		Fields:     map[string]FieldStats{},
+	}
+	r.mu.Unlock()
+}
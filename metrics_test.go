@@ -0,0 +1,207 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/testutil"
+)
+
+func measuredSchema(t *testing.T, metrics graphql.Metrics) *graphql.Schema {
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"hello": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return "world", nil
+					},
+				},
+				"fail": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return nil, errors.New("failed")
+					},
+				},
+				"forbidden": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return nil, gqlerrors.NewCodedError("FORBIDDEN", "forbidden")
+					},
+				},
+				"panic": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						panic("boom")
+					},
+				},
+			},
+		}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Mutation",
+			Fields: graphql.Fields{
+				"touch": &graphql.Field{
+					Type: graphql.Boolean,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return true, nil
+					},
+				},
+			},
+		}),
+		Metrics: metrics,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &schema
+}
+
+func TestMetrics_Do(t *testing.T) {
+	recorder := graphql.NewMetricsRecorder()
+	schema := measuredSchema(t, recorder)
+	graphql.Do(graphql.Params{Schema: *schema, RequestString: `query Q { hello fail forbidden panic }`})
+	graphql.Do(graphql.Params{Schema: *schema, RequestString: `query Q { hello }`, OperationName: "Q"})
+	graphql.Do(graphql.Params{Schema: *schema, RequestString: `mutation { touch }`})
+
+	s := recorder.Snapshot()
+	expectedOperations := map[graphql.OperationKey]uint64{
+		{Name: "Q", Type: "query"}:   2,
+		{Name: "", Type: "mutation"}: 1,
+	}
+	if !reflect.DeepEqual(s.Operations, expectedOperations) {
+		t.Fatalf("expected operations %v, got %v", expectedOperations, s.Operations)
+	}
+	expectedErrors := map[string]uint64{"": 2, "FORBIDDEN": 1}
+	if !reflect.DeepEqual(s.Errors, expectedErrors) {
+		t.Fatalf("expected errors %v, got %v", expectedErrors, s.Errors)
+	}
+
+	expectedFields := map[string][2]uint64{
+		"Query.hello":     {2, 0},
+		"Query.fail":      {1, 1},
+		"Query.forbidden": {1, 1},
+		"Query.panic":     {1, 1},
+		"Mutation.touch":  {1, 0},
+	}
+	if len(s.Fields) != len(expectedFields) {
+		t.Fatalf("expected fields %v, got %v", expectedFields, s.Fields)
+	}
+	for key, expected := range expectedFields {
+		stats := s.Fields[key]
+		if stats.Count != expected[0] || stats.Errors != expected[1] || stats.Total < stats.Max {
+			t.Fatalf("unexpected stats for %s: %+v", key, stats)
+		}
+	}
+}
+
+func TestMetrics_ConcurrentExecutePlanInDo(t *testing.T) {
+	recorder := graphql.NewMetricsRecorder()
+	schema := measuredSchema(t, recorder)
+	plan, err := graphql.PlanQuery(schema, testutil.TestParse(t, `query Nested { hello }`), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// plans run with the request's context, while Do runs its own, race
+	// to claim the request's report
+	var wg sync.WaitGroup
+	ext := newtestExt("nested")
+	ext.parseDidStartFn = func(ctx context.Context) (context.Context, graphql.ParseFinishFunc) {
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				graphql.ExecutePlan(plan, graphql.ExecuteParams{Schema: *schema, Context: ctx})
+			}()
+		}
+		return ctx, func(error) {}
+	}
+	graphql.Do(graphql.Params{
+		Schema:        *schema,
+		RequestString: `query Q { hello }`,
+		Extensions:    []graphql.Extension{ext},
+	})
+	wg.Wait()
+
+	var total uint64
+	for _, n := range recorder.Snapshot().Operations {
+		total += n
+	}
+	if total != 5 {
+		t.Fatalf("expected 5 operations, got %v", recorder.Snapshot().Operations)
+	}
+}
+
+func TestMetrics_RejectedRequests(t *testing.T) {
+	recorder := graphql.NewMetricsRecorder()
+	schema := measuredSchema(t, recorder)
+	graphql.Do(graphql.Params{Schema: *schema, RequestString: `{ hello`})
+	graphql.Do(graphql.Params{Schema: *schema, RequestString: `query Bad { nope }`, OperationName: "Bad"})
+
+	s := recorder.Snapshot()
+	expectedOperations := map[graphql.OperationKey]uint64{{Name: "", Type: ""}: 1, {Name: "Bad", Type: ""}: 1}
+	if !reflect.DeepEqual(s.Operations, expectedOperations) {
+		t.Fatalf("expected operations %v, got %v", expectedOperations, s.Operations)
+	}
+	expectedErrors := map[string]uint64{graphql.ErrCodeParseFailed: 1, graphql.ErrCodeValidationFailed: 1}
+	if !reflect.DeepEqual(s.Errors, expectedErrors) {
+		t.Fatalf("expected errors %v, got %v", expectedErrors, s.Errors)
+	}
+}
+
+func TestMetrics_PlanCache(t *testing.T) {
+	recorder := graphql.NewMetricsRecorder()
+	schema := measuredSchema(t, recorder)
+	cache := graphql.NewPlanCache(graphql.PlanCacheOptions{MaxEntries: 1})
+
+	pr := cache.Get(schema, `query A { hello }`, "")
+	graphql.ExecutePlan(pr.Plan, graphql.ExecuteParams{Schema: *schema})
+	pr = cache.Get(schema, `query A { hello }`, "")
+	graphql.ExecutePlan(pr.Plan, graphql.ExecuteParams{Schema: *schema})
+	// evicts query A
+	cache.Get(schema, `{ nope }`, "")
+	cache.Get(schema, `{ nope }`, "")
+
+	s := recorder.Snapshot()
+	if s.PlanCacheHits != 2 || s.PlanCacheMisses != 2 || s.PlanCacheEvictions != 1 {
+		t.Fatalf("unexpected cache counters: %+v", s)
+	}
+	if evictions := cache.Evictions(); evictions != 1 {
+		t.Fatalf("expected 1 eviction, got %d", evictions)
+	}
+	expectedOperations := map[graphql.OperationKey]uint64{{Name: "A", Type: "query"}: 2, {Name: "", Type: ""}: 2}
+	if !reflect.DeepEqual(s.Operations, expectedOperations) {
+		t.Fatalf("expected operations %v, got %v", expectedOperations, s.Operations)
+	}
+	if expectedErrors := map[string]uint64{graphql.ErrCodeValidationFailed: 2}; !reflect.DeepEqual(s.Errors, expectedErrors) {
+		t.Fatalf("expected errors %v, got %v", expectedErrors, s.Errors)
+	}
+
+	// a rebuilt schema evicts the entries planned against the old one
+	rebuilt := measuredSchema(t, recorder)
+	cache.Get(rebuilt, `{ nope }`, "")
+	if s := recorder.Snapshot(); s.PlanCacheEvictions != 2 || cache.Evictions() != 2 {
+		t.Fatalf("expected the stale entry to be evicted, got %+v", s)
+	}
+}
+
+func TestMetrics_SetMetrics(t *testing.T) {
+	schema := measuredSchema(t, nil)
+	graphql.Do(graphql.Params{Schema: *schema, RequestString: `{ hello }`})
+
+	recorder := graphql.NewMetricsRecorder()
+	schema.SetMetrics(recorder)
+	graphql.Do(graphql.Params{Schema: *schema, RequestString: `{ hello }`})
+	if s := recorder.Snapshot(); s.Operations[graphql.OperationKey{Type: "query"}] != 1 {
+		t.Fatalf("expected one operation, got %v", s.Operations)
+	}
+	recorder.Reset()
+	if s := recorder.Snapshot(); len(s.Operations) != 0 || len(s.Fields) != 0 {
+		t.Fatalf("expected Reset to forget the measurements, got %+v", s)
+	}
+}
@@ -0,0 +1,230 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/location"
+	"github.com/graphql-go/graphql/testutil"
+)
+
+func multiErrorTestSchema(t *testing.T) graphql.Schema {
+	itemType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Item",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{Type: graphql.Int},
+		},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"noop": &graphql.Field{Type: graphql.String},
+			},
+		}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Mutation",
+			Fields: graphql.Fields{
+				"createItems": &graphql.Field{
+					Type: graphql.NewList(itemType),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return []interface{}{
+							map[string]interface{}{"id": 1},
+							map[string]interface{}{"id": 3},
+						}, gqlerrors.Errors{
+							errors.New("item 2: duplicate key"),
+							errors.New("item 4: invalid name"),
+						}
+					},
+				},
+				"joined": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return nil, errors.Join(errors.New("first"), errors.New("second"))
+					},
+				},
+				"nonNullJoined": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.String),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return nil, errors.Join(errors.New("first"), errors.New("second"))
+					},
+				},
+				"warned": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return "done", gqlerrors.NewWarning("quota almost exhausted")
+					},
+				},
+				"mixed": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return nil, gqlerrors.Errors{
+							gqlerrors.NewWarning("deprecated input"),
+							errors.New("failed"),
+						}
+					},
+				},
+				"thunk": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return func() (interface{}, error) {
+							return "partial", gqlerrors.Errors{errors.New("thunk failed")}
+						}, nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("Error in schema %v", err.Error())
+	}
+	return schema
+}
+
+func fieldError(message string, line, column int, path ...interface{}) gqlerrors.FormattedError {
+	return gqlerrors.FormattedError{
+		Message:   message,
+		Locations: []location.SourceLocation{{Line: line, Column: column}},
+		Path:      path,
+	}
+}
+
+func TestMultiError_PartialDataWithSeveralErrors(t *testing.T) {
+	result := graphql.Do(graphql.Params{
+		Schema:        multiErrorTestSchema(t),
+		RequestString: `mutation { createItems { id } }`,
+	})
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"createItems": []interface{}{
+				map[string]interface{}{"id": 1},
+				map[string]interface{}{"id": 3},
+			},
+		},
+		Errors: []gqlerrors.FormattedError{
+			fieldError("item 2: duplicate key", 1, 12, "createItems"),
+			fieldError("item 4: invalid name", 1, 12, "createItems"),
+		},
+	}
+	if !testutil.EqualResults(expected, result) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, result))
+	}
+}
+
+func TestMultiError_JoinedErrorsWithoutData(t *testing.T) {
+	result := graphql.Do(graphql.Params{
+		Schema:        multiErrorTestSchema(t),
+		RequestString: `mutation { joined }`,
+	})
+	expected := &graphql.Result{
+		Data: map[string]interface{}{"joined": nil},
+		Errors: []gqlerrors.FormattedError{
+			fieldError("first", 1, 12, "joined"),
+			fieldError("second", 1, 12, "joined"),
+		},
+	}
+	if !testutil.EqualResults(expected, result) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, result))
+	}
+}
+
+func TestMultiError_NonNullPropagates(t *testing.T) {
+	result := graphql.Do(graphql.Params{
+		Schema:        multiErrorTestSchema(t),
+		RequestString: `mutation { nonNullJoined }`,
+	})
+	if result.Data != nil {
+		t.Fatalf("expected null data, got %v", result.Data)
+	}
+	if len(result.Errors) != 2 {
+		t.Fatalf("expected both errors to be reported, got %v", result.Errors)
+	}
+}
+
+func TestMultiError_WarningsGoToExtensions(t *testing.T) {
+	result := graphql.Do(graphql.Params{
+		Schema:        multiErrorTestSchema(t),
+		RequestString: `mutation { warned mixed }`,
+	})
+	expectedData := map[string]interface{}{"warned": "done", "mixed": nil}
+	if !reflect.DeepEqual(expectedData, result.Data) {
+		t.Fatalf("Unexpected data, Diff: %v", testutil.Diff(expectedData, result.Data))
+	}
+	expectedErrors := []gqlerrors.FormattedError{
+		fieldError("failed", 1, 19, "mixed"),
+	}
+	if !testutil.EqualFormattedErrors(expectedErrors, result.Errors) {
+		t.Fatalf("Unexpected errors, Diff: %v", testutil.Diff(expectedErrors, result.Errors))
+	}
+	warnings, ok := result.Extensions["warnings"].([]gqlerrors.FormattedError)
+	if !ok {
+		t.Fatalf("expected warnings in extensions, got %v", result.Extensions)
+	}
+	expectedWarnings := []gqlerrors.FormattedError{
+		fieldError("quota almost exhausted", 1, 12, "warned"),
+		fieldError("deprecated input", 1, 19, "mixed"),
+	}
+	if !testutil.EqualFormattedErrors(expectedWarnings, warnings) {
+		t.Fatalf("Unexpected warnings, Diff: %v", testutil.Diff(expectedWarnings, warnings))
+	}
+}
+
+func TestMultiError_FromThunk(t *testing.T) {
+	result := graphql.Do(graphql.Params{
+		Schema:        multiErrorTestSchema(t),
+		RequestString: `mutation { thunk }`,
+	})
+	expected := &graphql.Result{
+		Data: map[string]interface{}{"thunk": "partial"},
+		Errors: []gqlerrors.FormattedError{
+			fieldError("thunk failed", 1, 12, "thunk"),
+		},
+	}
+	if !testutil.EqualResults(expected, result) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, result))
+	}
+}
+
+func TestMultiError_WarningsArePresented(t *testing.T) {
+	result := graphql.Do(graphql.Params{
+		Schema:        multiErrorTestSchema(t),
+		RequestString: `mutation { warned }`,
+		ErrorPresenter: func(ctx context.Context, err gqlerrors.FormattedError) gqlerrors.FormattedError {
+			err.Message = "masked: " + err.Message
+			return err
+		},
+	})
+	warnings, ok := result.Extensions["warnings"].([]gqlerrors.FormattedError)
+	if !ok {
+		t.Fatalf("expected warnings in extensions, got %v", result.Extensions)
+	}
+	expectedWarnings := []gqlerrors.FormattedError{
+		fieldError("masked: quota almost exhausted", 1, 12, "warned"),
+	}
+	if !testutil.EqualFormattedErrors(expectedWarnings, warnings) {
+		t.Fatalf("Unexpected warnings, Diff: %v", testutil.Diff(expectedWarnings, warnings))
+	}
+}
+
+func TestMultiError_ErrorsBeforeNullDataAreKept(t *testing.T) {
+	result := graphql.Do(graphql.Params{
+		Schema:        multiErrorTestSchema(t),
+		RequestString: `mutation { joined nonNullJoined }`,
+	})
+	if result.Data != nil {
+		t.Fatalf("expected null data, got %v", result.Data)
+	}
+	expected := []gqlerrors.FormattedError{
+		fieldError("first", 1, 12, "joined"),
+		fieldError("second", 1, 12, "joined"),
+		fieldError("first", 1, 19, "nonNullJoined"),
+		fieldError("second", 1, 19, "nonNullJoined"),
+	}
+	if !testutil.EqualFormattedErrors(expected, result.Errors) {
+		t.Fatalf("Unexpected errors, Diff: %v", testutil.Diff(expected, result.Errors))
+	}
+}
@@ -0,0 +1,217 @@
+package graphql
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// Error codes reported in `extensions.code` by PersistedQueries. Clients
+// following the Automatic Persisted Queries protocol retry with the full
+// query text when they see ErrCodePersistedQueryNotFound.
+const (
+	ErrCodePersistedQueryNotFound     = "PERSISTED_QUERY_NOT_FOUND"
+	ErrCodePersistedQueryNotSupported = "PERSISTED_QUERY_NOT_SUPPORTED"
+	ErrCodePersistedQueryHashMismatch = "PERSISTED_QUERY_HASH_MISMATCH"
+)
+
+// PersistedQueryVersion is the only version of the persisted query
+// protocol PersistedQueries understands.
+const PersistedQueryVersion = 1
+
+const defaultPersistedQueryStoreMaxEntries = 4096
+
+// PersistedQueryStore maps SHA-256 hashes (lowercase hex) to query text.
+// Implementations must be safe for concurrent use. Get reports found=false
+// for unknown hashes; a non-nil error means the store itself failed (e.g.
+// a network backend is unreachable).
+type PersistedQueryStore interface {
+	Get(ctx context.Context, hash string) (query string, found bool, err error)
+	Put(ctx context.Context, hash, query string) error
+}
+
+// PersistedQueries implements Automatic Persisted Queries on top of a
+// PlanCache. A client sends only `extensions.persistedQuery.sha256Hash`;
+// on a miss it gets a PersistedQueryNotFound error and retries with the
+// query text and the hash, which registers the query. Registered queries
+// resolve to their text and go through the PlanCache, so a hash and the
+// query text it stands for share one cached plan.
+//
+// PersistedQueries is safe for concurrent use.
+type PersistedQueries struct {
+	store PersistedQueryStore
+	cache *PlanCache
+}
+
+// NewPersistedQueries returns an APQ layer over store and cache. A nil
+// store gets an in-memory store with default bounds; a nil cache plans
+// every request afresh (see PlanCache.Get).
+func NewPersistedQueries(store PersistedQueryStore, cache *PlanCache) *PersistedQueries {
+	if store == nil {
+		store = NewMemoryPersistedQueryStore(0)
+	}
+	return &PersistedQueries{store: store, cache: cache}
+}
+
+// Get resolves a request to a PlanResult. extensions is the request's
+// `extensions` object as decoded from JSON.
+//
+//   - Without a persistedQuery extension, the query is planned through the
+//     PlanCache as usual.
+//   - With a hash and no query text, the query is looked up in the store;
+//     unknown hashes yield a PersistedQueryNotFound error.
+//   - With a hash and query text, the hash is verified against the text
+//     and the query is registered before planning.
+func (pq *PersistedQueries) Get(ctx context.Context, schema *Schema, query, operationName string, extensions map[string]interface{}) PlanResult {
+	hash, ok, err := PersistedQueryHash(extensions)
+	if err != nil {
+		return PlanResult{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if !ok {
+		return pq.cache.GetContext(ctx, schema, query, operationName)
+	}
+	if query == "" {
+		stored, found, err := pq.store.Get(ctx, hash)
+		if err != nil {
+			return PlanResult{Errors: gqlerrors.FormatErrors(err)}
+		}
+		if !found {
+			return PlanResult{Errors: gqlerrors.FormatErrors(
+				gqlerrors.NewCodedError(ErrCodePersistedQueryNotFound, "PersistedQueryNotFound"),
+			)}
+		}
+		return pq.cache.GetContext(ctx, schema, stored, operationName)
+	}
+	if HashQuery(query) != hash {
+		return PlanResult{Errors: gqlerrors.FormatErrors(
+			gqlerrors.NewCodedError(ErrCodePersistedQueryHashMismatch, "provided sha does not match query"),
+		)}
+	}
+	pr := pq.cache.GetContext(ctx, schema, query, operationName)
+	if len(pr.Errors) > 0 {
+		// don't persist queries that can never run
+		return pr
+	}
+	if err := pq.store.Put(ctx, hash, query); err != nil {
+		return PlanResult{Errors: gqlerrors.FormatErrors(err)}
+	}
+	return pr
+}
+
+// PlanRequest implements RequestPlanner.
+func (pq *PersistedQueries) PlanRequest(ctx context.Context, schema *Schema, req Request) PlanResult {
+	if req.Query == "" && req.DocumentID != "" {
+		return pq.cache.PlanRequest(ctx, schema, req)
+	}
+	return pq.Get(ctx, schema, req.Query, req.OperationName, req.Extensions)
+}
+
+// HashQuery returns the lowercase hex SHA-256 of query, as used in
+// `extensions.persistedQuery.sha256Hash`.
+func HashQuery(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// PersistedQueryHash extracts the hash from a request's
+// `extensions.persistedQuery`. ok is false when the request doesn't use
+// persisted queries; err is set when it does but the extension is
+// malformed or of an unsupported version.
+func PersistedQueryHash(extensions map[string]interface{}) (hash string, ok bool, err error) {
+	raw, present := extensions["persistedQuery"]
+	if !present || raw == nil {
+		return "", false, nil
+	}
+	ext, isMap := raw.(map[string]interface{})
+	if !isMap {
+		return "", false, gqlerrors.NewCodedError(ErrCodePersistedQueryNotSupported, "PersistedQueryNotSupported")
+	}
+	if !isPersistedQueryVersion(ext["version"]) {
+		return "", false, gqlerrors.NewCodedError(ErrCodePersistedQueryNotSupported, "PersistedQueryNotSupported")
+	}
+	hash, _ = ext["sha256Hash"].(string)
+	if hash == "" {
+		return "", false, gqlerrors.NewCodedError(ErrCodePersistedQueryNotSupported, "PersistedQueryNotSupported")
+	}
+	return strings.ToLower(hash), true, nil
+}
+
+// isPersistedQueryVersion accepts the version number in any of the shapes
+// a JSON decoder may produce.
+func isPersistedQueryVersion(v interface{}) bool {
+	switch v := v.(type) {
+	case int:
+		return v == PersistedQueryVersion
+	case int64:
+		return v == PersistedQueryVersion
+	case float64:
+		return v == PersistedQueryVersion
+	case json.Number:
+		n, err := v.Int64()
+		return err == nil && n == PersistedQueryVersion
+	}
+	return false
+}
+
+// MemoryPersistedQueryStore is a bounded, in-process PersistedQueryStore.
+// Past MaxEntries the least recently used query is evicted; clients then
+// simply re-register it.
+type MemoryPersistedQueryStore struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type memoryPersistedQuery struct {
+	hash  string
+	query string
+}
+
+// NewMemoryPersistedQueryStore returns an empty store holding at most
+// maxEntries queries; maxEntries <= 0 takes the default.
+func NewMemoryPersistedQueryStore(maxEntries int) *MemoryPersistedQueryStore {
+	if maxEntries <= 0 {
+		maxEntries = defaultPersistedQueryStoreMaxEntries
+	}
+	return &MemoryPersistedQueryStore{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+func (s *MemoryPersistedQueryStore) Get(ctx context.Context, hash string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.entries[hash]
+	if !ok {
+		return "", false, nil
+	}
+	s.order.MoveToFront(el)
+	return el.Value.(*memoryPersistedQuery).query, true, nil
+}
+
+func (s *MemoryPersistedQueryStore) Put(ctx context.Context, hash, query string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.entries[hash]; ok {
+		el.Value.(*memoryPersistedQuery).query = query
+		s.order.MoveToFront(el)
+		return nil
+	}
+	s.entries[hash] = s.order.PushFront(&memoryPersistedQuery{hash: hash, query: query})
+	for s.order.Len() > s.maxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*memoryPersistedQuery).hash)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package graphql_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/benchutil"
+)
+
+func persistedQueryExtensions(hash string) map[string]interface{} {
+	return map[string]interface{}{
+		"persistedQuery": map[string]interface{}{
+			"version":    float64(1),
+			"sha256Hash": hash,
+		},
+	}
+}
+
+func TestPersistedQueries_RegisterThenHashOnly(t *testing.T) {
+	schema := benchutil.WideArgedSchemaWithXFieldsAndYItems(5, 1)
+	cache := graphql.NewPlanCache(graphql.PlanCacheOptions{})
+	apq := graphql.NewPersistedQueries(nil, cache)
+	ctx := context.Background()
+
+	q := `{ wide { a(value: "x") } }`
+	hash := graphql.HashQuery(q)
+
+	miss := apq.Get(ctx, &schema, "", "", persistedQueryExtensions(hash))
+	if miss.Plan != nil || len(miss.Errors) != 1 {
+		t.Fatalf("expected a single not-found error, got %v", miss.Errors)
+	}
+	if miss.Errors[0].Message != "PersistedQueryNotFound" {
+		t.Fatalf("unexpected message %q", miss.Errors[0].Message)
+	}
+	expectedExtensions := map[string]interface{}{"code": graphql.ErrCodePersistedQueryNotFound}
+	if !reflect.DeepEqual(expectedExtensions, miss.Errors[0].Extensions) {
+		t.Fatalf("unexpected extensions %v", miss.Errors[0].Extensions)
+	}
+
+	registered := apq.Get(ctx, &schema, q, "", persistedQueryExtensions(hash))
+	if len(registered.Errors) > 0 || registered.Plan == nil {
+		t.Fatalf("register: %v", registered.Errors)
+	}
+
+	hit := apq.Get(ctx, &schema, "", "", persistedQueryExtensions(hash))
+	if len(hit.Errors) > 0 {
+		t.Fatalf("hash-only: %v", hit.Errors)
+	}
+	if hit.Plan != registered.Plan {
+		t.Fatal("expected the hash lookup to reuse the PlanCache entry")
+	}
+	plain := apq.Get(ctx, &schema, q, "", nil)
+	if plain.Plan != registered.Plan {
+		t.Fatal("expected the plain query to reuse the PlanCache entry")
+	}
+}
+
+func TestPersistedQueries_HashMismatch(t *testing.T) {
+	schema := benchutil.WideArgedSchemaWithXFieldsAndYItems(5, 1)
+	apq := graphql.NewPersistedQueries(nil, nil)
+	ctx := context.Background()
+
+	q := `{ wide { a(value: "x") } }`
+	pr := apq.Get(ctx, &schema, q, "", persistedQueryExtensions(graphql.HashQuery("{ other }")))
+	if len(pr.Errors) != 1 || pr.Errors[0].Extensions["code"] != graphql.ErrCodePersistedQueryHashMismatch {
+		t.Fatalf("expected a hash mismatch error, got %v", pr.Errors)
+	}
+	// the mismatching query must not have been registered under either hash
+	pr = apq.Get(ctx, &schema, "", "", persistedQueryExtensions(graphql.HashQuery(q)))
+	if len(pr.Errors) != 1 || pr.Errors[0].Extensions["code"] != graphql.ErrCodePersistedQueryNotFound {
+		t.Fatalf("expected not found, got %v", pr.Errors)
+	}
+}
+
+func TestPersistedQueries_InvalidQueryIsNotRegistered(t *testing.T) {
+	schema := benchutil.WideArgedSchemaWithXFieldsAndYItems(5, 1)
+	store := graphql.NewMemoryPersistedQueryStore(0)
+	apq := graphql.NewPersistedQueries(store, nil)
+	ctx := context.Background()
+
+	q := `{ nope }`
+	pr := apq.Get(ctx, &schema, q, "", persistedQueryExtensions(graphql.HashQuery(q)))
+	if len(pr.Errors) == 0 {
+		t.Fatal("expected validation errors")
+	}
+	if _, found, _ := store.Get(ctx, graphql.HashQuery(q)); found {
+		t.Fatal("invalid queries must not be persisted")
+	}
+}
+
+func TestPersistedQueries_UnsupportedVersion(t *testing.T) {
+	schema := benchutil.WideArgedSchemaWithXFieldsAndYItems(5, 1)
+	apq := graphql.NewPersistedQueries(nil, nil)
+	pr := apq.Get(context.Background(), &schema, "", "", map[string]interface{}{
+		"persistedQuery": map[string]interface{}{"version": float64(2), "sha256Hash": "abc"},
+	})
+	if len(pr.Errors) != 1 || pr.Errors[0].Extensions["code"] != graphql.ErrCodePersistedQueryNotSupported {
+		t.Fatalf("expected an unsupported error, got %v", pr.Errors)
+	}
+}
+
+func TestMemoryPersistedQueryStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	store := graphql.NewMemoryPersistedQueryStore(2)
+	ctx := context.Background()
+	_ = store.Put(ctx, "a", "{ a }")
+	_ = store.Put(ctx, "b", "{ b }")
+	if _, found, _ := store.Get(ctx, "a"); !found {
+		t.Fatal("expected a to be stored")
+	}
+	_ = store.Put(ctx, "c", "{ c }")
+	if _, found, _ := store.Get(ctx, "b"); found {
+		t.Fatal("expected b to be evicted")
+	}
+	for _, hash := range []string{"a", "c"} {
+		if _, found, _ := store.Get(ctx, hash); !found {
+			t.Fatalf("expected %s to be kept", hash)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"reflect"
 	"sync"
+	"time"
 
 	"github.com/graphql-go/graphql/gqlerrors"
 	"github.com/graphql-go/graphql/language/ast"
@@ -28,6 +29,7 @@ import (
 // changes) and callers should re-plan.
 type Plan struct {
 	schema     *Schema
+	document   *ast.Document
 	operation  *ast.OperationDefinition
 	fragments  map[string]ast.Definition
 	rootType   *Object
@@ -38,6 +40,12 @@ type Plan struct {
 	// which happens at execute time (concurrently across fields) the
 	// first time each concrete type is encountered for an abstract field.
 	abstractMu sync.Mutex
+
+	// introspection holds the NoSchemaIntrospectionRule errors of the
+	// document, computed on first use by a request disallowing
+	// introspection.
+	introspectionOnce sync.Once
+	introspection     []gqlerrors.FormattedError
 }
 
 // selectionPlan is a pre-collected, source-ordered list of fields to
@@ -152,6 +160,7 @@ func PlanQuery(schema *Schema, doc *ast.Document, operationName string) (*Plan,
 
 	plan := &Plan{
 		schema:     schema,
+		document:   doc,
 		operation:  operation,
 		fragments:  fragments,
 		rootType:   rootType,
@@ -161,6 +170,31 @@ func PlanQuery(schema *Schema, doc *ast.Document, operationName string) (*Plan,
 	return plan, nil
 }
 
+// OperationType returns the type of the planned operation: one of
+// ast.OperationTypeQuery, ast.OperationTypeMutation and
+// ast.OperationTypeSubscription.
+func (p *Plan) OperationType() string {
+	return p.operation.GetOperation()
+}
+
+func (p *Plan) operationName() string {
+	if name := p.operation.GetName(); name != nil {
+		return name.Value
+	}
+	return ""
+}
+
+// introspectionErrors returns the errors NoSchemaIntrospectionRule reports
+// for the planned document. Cached plans are shared by requests that allow
+// introspection and requests that don't, so the rule is checked at
+// execution rather than when planning.
+func (p *Plan) introspectionErrors() []gqlerrors.FormattedError {
+	p.introspectionOnce.Do(func() {
+		p.introspection = ValidateDocument(p.schema, p.document, []ValidationRuleFn{NoSchemaIntrospectionRule}).Errors
+	})
+	return p.introspection
+}
+
 // planSelectionSet pre-collects the fields under one selection-set
 // for a known parent type, recursing into sub-selections. Two-phase:
 //
@@ -429,6 +463,27 @@ func planArguments(argDefs []*Argument, argASTs []*ast.Argument) argPlan {
 	return argPlan{static: static}
 }
 
+// values returns the argument map handed to the resolver. Resolvers
+// expect a non-nil Args map (the existing resolveField path always
+// passes the result of getArgumentValues, which is never nil even when
+// empty). Match that contract.
+func (a argPlan) values(variableValues map[string]interface{}) map[string]interface{} {
+	switch {
+	case a.hasVariables:
+		return getArgumentValues(a.fieldDefArgs, a.argASTs, variableValues)
+	case a.static != nil:
+		// Resolvers may mutate the args map; copy to keep the plan's
+		// static map immutable across requests.
+		args := make(map[string]interface{}, len(a.static))
+		for k, v := range a.static {
+			args[k] = v
+		}
+		return args
+	default:
+		return map[string]interface{}{}
+	}
+}
+
 // astHasVariables walks the argument AST tree looking for any
 // ast.Variable node. Returns true on the first hit.
 func astHasVariables(argASTs []*ast.Argument) bool {
@@ -564,6 +619,9 @@ func planFragmentMatches(schema Schema, typeConditionAST *ast.Named, runtime *Ob
 // resolveField → completeValue, but skips collectFields and
 // getFieldDef on the hot path. Per-field arguments come from the
 // argPlan: static (no variables) bypasses getArgumentValues entirely.
+//
+// When an error in a non-null root field nulls the whole response, the
+// field errors recorded before it are still part of the result.
 func ExecutePlan(plan *Plan, p ExecuteParams) (result *Result) {
 	if plan == nil {
 		return &Result{Errors: gqlerrors.FormatErrors(errors.New("graphql: ExecutePlan: plan is nil"))}
@@ -572,24 +630,81 @@ func ExecutePlan(plan *Plan, p ExecuteParams) (result *Result) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	if tracer := plan.schema.tracer; tracer != nil {
+		var span Span
+		ctx, span = tracer.StartSpan(ctx, SpanExecute,
+			SpanAttribute{AttributeOperationName, plan.operationName()},
+			SpanAttribute{AttributeOperationType, plan.OperationType()},
+		)
+		defer func() {
+			span.SetAttributes(SpanAttribute{AttributeErrorCount, len(result.Errors)})
+			span.End()
+		}()
+	}
+	var report *operationReport
+	errorCode := ""
+	if metrics := plan.schema.metrics; metrics != nil {
+		if report = claimOperationReport(ctx); report != nil {
+			// Do reports the operation
+			report.setOperation(plan.operationName(), plan.OperationType())
+		} else {
+			start := time.Now()
+			defer func() {
+				reportOperation(metrics, plan.operationName(), plan.OperationType(), start, result.Errors, errorCode)
+			}()
+		}
+	}
+	if noSchemaIntrospection(&p.Schema, ctx) {
+		if errs := plan.introspectionErrors(); len(errs) > 0 {
+			errorCode = ErrCodeValidationFailed
+			if report != nil {
+				report.setErrorCode(errorCode)
+			}
+			return &Result{Errors: PresentErrors(ctx, p.ErrorPresenter, errs)}
+		}
+	}
 
-	extErrs, executionFinishFn := handleExtensionsExecutionDidStart(&p)
+	p.Context = ctx
+	exts := requestExtensions(&p.Schema, p.Extensions, p.SkipExtensions)
+	extErrs, executionFinishFn := handleExtensionsExecutionDidStart(&p, exts)
 	if len(extErrs) != 0 {
-		return &Result{Errors: extErrs}
+		return &Result{Errors: PresentErrors(ctx, p.ErrorPresenter, extErrs)}
+	}
+	// resolvers see the context the extensions derived
+	if p.Context != nil {
+		ctx = p.Context
+	}
+	var cacheControl *cachePolicyAccumulator
+	if p.CacheControl != nil {
+		cacheControl = newCachePolicyAccumulator(*p.CacheControl)
 	}
 	defer func() {
 		extErrs := executionFinishFn(result)
 		if len(extErrs) != 0 {
 			result.Errors = append(result.Errors, extErrs...)
 		}
-		addExtensionResults(&p, result)
+		if cacheControl != nil {
+			if result.Extensions == nil {
+				result.Extensions = map[string]interface{}{}
+			}
+			result.Extensions[cacheControlExtensionKey] = cacheControl.result()
+		}
+		addExtensionResults(&p, exts, result)
+		result.Errors = PresentErrors(ctx, p.ErrorPresenter, result.Errors)
 	}()
 
 	resultChannel := make(chan *Result, 2)
 	go func() {
 		out := &Result{}
+		var eCtx *executionContext
 		defer func() {
 			if err := recover(); err != nil {
+				reportPanic(eCtx, err)
+				if eCtx != nil {
+					// errors recorded before a non-null root field nulled
+					// the whole response still belong in it
+					out.Errors = append(out.Errors, eCtx.Errors...)
+				}
 				if e, ok := err.(error); ok {
 					out.Errors = append(out.Errors, gqlerrors.FormatError(e))
 				} else {
@@ -612,14 +727,17 @@ func ExecutePlan(plan *Plan, p ExecuteParams) (result *Result) {
 			return
 		}
 
-		eCtx := &executionContext{
+		eCtx = &executionContext{
 			Schema:         execSchema,
 			Fragments:      plan.fragments,
 			Root:           p.Root,
 			Operation:      plan.operation,
 			VariableValues: variableValues,
 			Context:        ctx,
+			PanicHandler:   p.PanicHandler,
+			cacheControl:   cacheControl,
 			plan:           plan,
+			extensions:     exts,
 		}
 
 		data := executePlannedSelection(eCtx, plan.root, p.Root, plan.rootType, nil)
@@ -634,6 +752,13 @@ func ExecutePlan(plan *Plan, p ExecuteParams) (result *Result) {
 		}
 		out.Data = data
 		out.Errors = append(out.Errors, eCtx.Errors...)
+		if len(eCtx.Warnings) != 0 {
+			// warnings reach the client like errors do, so they are
+			// masked by the same presenter
+			out.Extensions = map[string]interface{}{
+				"warnings": PresentErrors(ctx, p.ErrorPresenter, eCtx.Warnings),
+			}
+		}
 	}()
 
 	select {
@@ -688,9 +813,18 @@ func executePlannedSelection(eCtx *executionContext, sp *selectionPlan, source i
 // coercion.
 func resolvePlannedField(eCtx *executionContext, parentType *Object, source interface{}, fp *fieldPlan, path *ResponsePath) (result interface{}, ok bool) {
 	var returnType Output
+	var span Span
+	var resolveStart time.Time
 	defer func() {
 		if r := recover(); r != nil {
-			handleFieldError(r, FieldASTsToNodeASTs(fp.fieldASTs), path, returnType, eCtx)
+			if span != nil {
+				span.RecordError(fmt.Errorf("%v", r))
+				span.End()
+			}
+			if !resolveStart.IsZero() {
+				eCtx.Schema.metrics.FieldResolved(parentType.Name(), fp.fieldName, time.Since(resolveStart), fmt.Errorf("%v", r))
+			}
+			handleFieldPanic(r, FieldASTsToNodeASTs(fp.fieldASTs), path, returnType, eCtx)
 			ok = true
 		}
 	}()
@@ -701,23 +835,7 @@ func resolvePlannedField(eCtx *executionContext, parentType *Object, source inte
 		resolveFn = DefaultResolveFn
 	}
 
-	// Resolvers expect a non-nil Args map (the existing resolveField
-	// path always passes the result of getArgumentValues, which is
-	// never nil even when empty). Match that contract.
-	var args map[string]interface{}
-	switch {
-	case fp.args.hasVariables:
-		args = getArgumentValues(fp.args.fieldDefArgs, fp.args.argASTs, eCtx.VariableValues)
-	case fp.args.static != nil:
-		// Resolvers may mutate the args map; copy to keep the plan's
-		// static map immutable across requests.
-		args = make(map[string]interface{}, len(fp.args.static))
-		for k, v := range fp.args.static {
-			args[k] = v
-		}
-	default:
-		args = map[string]interface{}{}
-	}
+	args := fp.args.values(eCtx.VariableValues)
 
 	info := ResolveInfo{
 		FieldName:      fp.fieldName,
@@ -731,26 +849,55 @@ func resolvePlannedField(eCtx *executionContext, parentType *Object, source inte
 		Operation:      eCtx.Operation,
 		VariableValues: eCtx.VariableValues,
 	}
+	if eCtx.cacheControl != nil {
+		info.CacheControl = &FieldCacheControl{}
+	}
 
 	// Extensions allocate a per-field map + closure even when none are
 	// registered. Skip entirely on the common no-extensions schema —
 	// saves ~22% of allocs per resolved field on hot paths.
 	var resolveFieldFinishFn resolveFieldFinishFuncHandler
-	if len(eCtx.Schema.extensions) > 0 {
+	if len(eCtx.extensions) > 0 {
 		var extErrs []gqlerrors.FormattedError
-		extErrs, resolveFieldFinishFn = handleExtensionsResolveFieldDidStart(eCtx.Schema.extensions, eCtx, &info)
+		extErrs, resolveFieldFinishFn = handleExtensionsResolveFieldDidStart(eCtx.extensions, eCtx, &info)
 		if len(extErrs) != 0 {
 			eCtx.Errors = append(eCtx.Errors, extErrs...)
 		}
 	}
 
+	ctx := eCtx.Context
+	if tracer := eCtx.Schema.tracer; tracer != nil {
+		ctx, span = tracer.StartSpan(ctx, SpanResolve,
+			SpanAttribute{AttributeFieldPath, pathString(path)},
+			SpanAttribute{AttributeFieldName, fp.fieldName},
+			SpanAttribute{AttributeParentType, parentType.Name()},
+		)
+	}
+
+	if eCtx.Schema.metrics != nil {
+		resolveStart = time.Now()
+	}
 	var resolveFnError error
 	result, resolveFnError = resolveFn(ResolveParams{
 		Source:  source,
 		Args:    args,
 		Info:    info,
-		Context: eCtx.Context,
+		Context: ctx,
 	})
+	if !resolveStart.IsZero() {
+		eCtx.Schema.metrics.FieldResolved(parentType.Name(), fp.fieldName, time.Since(resolveStart), resolveFnError)
+		resolveStart = time.Time{}
+	}
+	if span != nil {
+		if resolveFnError != nil {
+			span.RecordError(resolveFnError)
+		}
+		span.End()
+		span = nil
+	}
+	if eCtx.cacheControl != nil {
+		eCtx.cacheControl.restrictField(fieldDef, returnType, path.Prev == nil, info.CacheControl)
+	}
 
 	if resolveFieldFinishFn != nil {
 		extErrs := resolveFieldFinishFn(result, resolveFnError)
@@ -758,8 +905,8 @@ func resolvePlannedField(eCtx *executionContext, parentType *Object, source inte
 			eCtx.Errors = append(eCtx.Errors, extErrs...)
 		}
 	}
-	if resolveFnError != nil {
-		panic(resolveFnError)
+	if resolveFnError != nil && !handleResolverError(resolveFnError, result, FieldASTsToNodeASTs(fp.fieldASTs), path, returnType, eCtx) {
+		return nil, true
 	}
 
 	completed := completePlannedValueCatchingError(eCtx, returnType, fp, info, path, result)
@@ -769,7 +916,7 @@ func resolvePlannedField(eCtx *executionContext, parentType *Object, source inte
 func completePlannedValueCatchingError(eCtx *executionContext, returnType Type, fp *fieldPlan, info ResolveInfo, path *ResponsePath, result interface{}) (completed interface{}) {
 	defer func() {
 		if r := recover(); r != nil {
-			handleFieldError(r, FieldASTsToNodeASTs(fp.fieldASTs), path, returnType, eCtx)
+			handleFieldPanic(r, FieldASTsToNodeASTs(fp.fieldASTs), path, returnType, eCtx)
 		}
 	}()
 	if rt, ok := returnType.(*NonNull); ok {
@@ -828,7 +975,7 @@ func completePlannedValue(eCtx *executionContext, returnType Type, fp *fieldPlan
 func completePlannedThunkValueCatchingError(eCtx *executionContext, returnType Type, fp *fieldPlan, info ResolveInfo, path *ResponsePath, result interface{}) (completed interface{}) {
 	defer func() {
 		if r := recover(); r != nil {
-			handleFieldError(r, FieldASTsToNodeASTs(fp.fieldASTs), path, returnType, eCtx)
+			handleFieldPanic(r, FieldASTsToNodeASTs(fp.fieldASTs), path, returnType, eCtx)
 		}
 	}()
 	propertyFn, ok := result.(func() (interface{}, error))
@@ -837,8 +984,8 @@ func completePlannedThunkValueCatchingError(eCtx *executionContext, returnType T
 		panic(gqlerrors.FormatError(err))
 	}
 	fnResult, err := propertyFn()
-	if err != nil {
-		panic(gqlerrors.FormatError(err))
+	if err != nil && !handleResolverError(err, fnResult, FieldASTsToNodeASTs(fp.fieldASTs), path, returnType, eCtx) {
+		return nil
 	}
 	result = fnResult
 	if rt, ok := returnType.(*NonNull); ok {
@@ -2,14 +2,14 @@ package graphql
 
 import (
 	"container/list"
+	"context"
 	"hash/fnv"
 	"strconv"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/graphql-go/graphql/gqlerrors"
-	"github.com/graphql-go/graphql/language/parser"
-	"github.com/graphql-go/graphql/language/source"
 )
 
 // PlanCache is a bounded, schema-aware LRU of parsed + validated +
@@ -35,8 +35,9 @@ type PlanCache struct {
 	entries map[string]*list.Element
 	order   *list.List
 
-	hits   atomic.Uint64
-	misses atomic.Uint64
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
 }
 
 // PlanCacheOptions tunes the cache. Zero values get sensible
@@ -67,6 +68,9 @@ type PlanResult struct {
 	Plan      *Plan
 	SynthArgs map[string]interface{}
 	Errors    []gqlerrors.FormattedError
+
+	// errorCode is the code Errors are reported to Metrics with.
+	errorCode string
 }
 
 // internal cache entry layout. Stored as the value of a list.Element;
@@ -118,40 +122,87 @@ func NewPlanCache(opts PlanCacheOptions) *PlanCache {
 // THIS call's parse+normalize step are returned in PlanResult.SynthArgs;
 // the cached *Plan itself is shared across all calls.
 func (c *PlanCache) Get(schema *Schema, query, operationName string) PlanResult {
+	return c.GetContext(context.Background(), schema, query, operationName)
+}
+
+// GetContext is Get for a request with context ctx: with a Tracer on the
+// schema, the lookup is traced as a SpanPlan child of the span in ctx,
+// and a miss's parsing and validation as its children. With Metrics on
+// the schema, a request the cache rejects is reported as an operation,
+// since it never reaches ExecutePlan.
+func (c *PlanCache) GetContext(ctx context.Context, schema *Schema, query, operationName string) PlanResult {
+	tracer, metrics := schemaTracer(schema), schemaMetrics(schema)
+	if tracer == nil && metrics == nil {
+		pr, _ := c.get(ctx, schema, query, operationName)
+		return pr
+	}
+	start := time.Now()
+	ctx, span := startSpan(tracer, ctx, SpanPlan, SpanAttribute{AttributeOperationName, operationName})
+	pr, hit := c.get(ctx, schema, query, operationName)
+	if pr.Plan != nil {
+		span.SetAttributes(SpanAttribute{AttributeOperationType, pr.Plan.OperationType()})
+	}
+	span.SetAttributes(
+		SpanAttribute{AttributeCacheHit, hit},
+		SpanAttribute{AttributeErrorCount, len(pr.Errors)},
+	)
+	for _, err := range pr.Errors {
+		span.RecordError(err)
+	}
+	span.End()
+	if metrics != nil && len(pr.Errors) > 0 {
+		reportOperation(metrics, operationName, "", start, pr.Errors, pr.errorCode)
+	}
+	return pr
+}
+
+// PlanRequest implements RequestPlanner. Requests that name their
+// document by a persisted query hash or a document ID instead of sending
+// its text get a PersistedQueryNotSupported error, on which clients
+// retry with the text.
+func (c *PlanCache) PlanRequest(ctx context.Context, schema *Schema, req Request) PlanResult {
+	if req.Query == "" && (req.DocumentID != "" || req.Extensions["persistedQuery"] != nil) {
+		return PlanResult{Errors: gqlerrors.FormatErrors(
+			gqlerrors.NewCodedError(ErrCodePersistedQueryNotSupported, "PersistedQueryNotSupported"),
+		)}
+	}
+	return c.GetContext(ctx, schema, req.Query, req.OperationName)
+}
+
+func (c *PlanCache) get(ctx context.Context, schema *Schema, query, operationName string) (PlanResult, bool) {
 	if c == nil {
 		// Nil-receiver convenience: caller can pass nil and still
 		// get a working (uncached) path. Useful for tests +
 		// "off by default" deployments.
-		return planAndValidate(schema, query, operationName)
+		return planAndValidate(ctx, schema, query, operationName), false
 	}
 	if !c.shouldCache(len(query)) {
 		// Over-cap queries bypass the cache entirely.
-		return planAndValidate(schema, query, operationName)
+		return planAndValidate(ctx, schema, query, operationName), false
 	}
 
 	if !c.opts.Normalize {
 		// Plain cache: raw query string is the key.
 		key := operationName + "\x00" + query
 		if pr, ok := c.lookup(schema, key); ok {
-			return pr
+			return pr, true
 		}
-		pr := planAndValidate(schema, query, operationName)
+		pr := planAndValidate(ctx, schema, query, operationName)
 		c.store(schema, key, pr)
-		return pr
+		return pr, false
 	}
 
 	// Normalized cache: parse first (the AST is the input to
 	// normalization), then key the cache on the printed normalized
 	// document. Each call carries its own synth-args even when the
 	// underlying *Plan is shared.
-	src := source.NewSource(&source.Source{Body: []byte(query), Name: "GraphQL request"})
-	doc, parseErr := parser.Parse(parser.ParseParams{Source: src})
+	doc, parseErr := parseTraced(ctx, schema, query)
 	if parseErr != nil {
-		return PlanResult{Errors: gqlerrors.FormatErrors(parseErr)}
+		return PlanResult{Errors: gqlerrors.FormatErrors(parseErr), errorCode: ErrCodeParseFailed}, false
 	}
 	normDoc, synthArgs, normKey, normErr := normalizeDocument(schema, doc, operationName)
 	if normErr != nil {
-		return PlanResult{Errors: gqlerrors.FormatErrors(normErr)}
+		return PlanResult{Errors: gqlerrors.FormatErrors(normErr)}, false
 	}
 	if normKey == "" {
 		// Normalization isn't applicable (op-not-found, ambiguous
@@ -171,21 +222,21 @@ func (c *PlanCache) Get(schema *Schema, query, operationName string) PlanResult
 		// — those are per-call, derived freshly from the literals
 		// in the incoming query.
 		pr.SynthArgs = synthArgs
-		return pr
+		return pr, true
 	}
-	if vr := ValidateDocument(schema, normDoc, nil); !vr.IsValid {
-		pr := PlanResult{Errors: vr.Errors}
+	if vr := validateTraced(ctx, schema, normDoc, validationRules(schema, nil)); !vr.IsValid {
+		pr := PlanResult{Errors: vr.Errors, errorCode: ErrCodeValidationFailed}
 		c.store(schema, cacheKey, pr)
-		return pr
+		return pr, false
 	}
 	plan, err := PlanQuery(schema, normDoc, operationName)
 	if err != nil {
 		pr := PlanResult{Errors: gqlerrors.FormatErrors(err)}
 		c.store(schema, cacheKey, pr)
-		return pr
+		return pr, false
 	}
 	c.store(schema, cacheKey, PlanResult{Plan: plan})
-	return PlanResult{Plan: plan, SynthArgs: synthArgs}
+	return PlanResult{Plan: plan, SynthArgs: synthArgs}, false
 }
 
 // HitsMisses returns the cumulative hit and miss counts. Useful for
@@ -197,6 +248,16 @@ func (c *PlanCache) HitsMisses() (hits, misses uint64) {
 	return c.hits.Load(), c.misses.Load()
 }
 
+// Evictions returns the cumulative count of entries dropped, whether to
+// stay under MaxEntries or because the schema they were planned against
+// was rebuilt. Entries dropped by Reset aren't counted.
+func (c *PlanCache) Evictions() uint64 {
+	if c == nil {
+		return 0
+	}
+	return c.evictions.Load()
+}
+
 // Reset drops every entry. Operators rebuilding the schema can call
 // this to reclaim memory immediately rather than waiting for the
 // schema-pointer mismatch to evict entries one at a time.
@@ -214,7 +275,15 @@ func (c *PlanCache) shouldCache(querySize int) bool {
 	return c.opts.MaxQueryBytes <= 0 || querySize <= c.opts.MaxQueryBytes
 }
 
-func (c *PlanCache) lookup(schema *Schema, key string) (PlanResult, bool) {
+func (c *PlanCache) lookup(schema *Schema, key string) (pr PlanResult, hit bool) {
+	evicted := 0
+	if metrics := schemaMetrics(schema); metrics != nil {
+		// deferred first, so reported once the lock is released
+		defer func() {
+			reportPlanCacheEvictions(metrics, evicted)
+			metrics.PlanCacheLookup(hit)
+		}()
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	el, ok := c.entries[key]
@@ -226,6 +295,8 @@ func (c *PlanCache) lookup(schema *Schema, key string) (PlanResult, bool) {
 	if item.e.schema != schema {
 		c.order.Remove(el)
 		delete(c.entries, key)
+		c.evictions.Add(1)
+		evicted++
 		c.misses.Add(1)
 		return PlanResult{}, false
 	}
@@ -235,6 +306,10 @@ func (c *PlanCache) lookup(schema *Schema, key string) (PlanResult, bool) {
 }
 
 func (c *PlanCache) store(schema *Schema, key string, pr PlanResult) {
+	evicted := 0
+	if metrics := schemaMetrics(schema); metrics != nil {
+		defer func() { reportPlanCacheEvictions(metrics, evicted) }()
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if el, ok := c.entries[key]; ok {
@@ -255,6 +330,14 @@ func (c *PlanCache) store(schema *Schema, key string, pr PlanResult) {
 		oi := oldest.Value.(*planCacheItem)
 		c.order.Remove(oldest)
 		delete(c.entries, oi.key)
+		c.evictions.Add(1)
+		evicted++
+	}
+}
+
+func reportPlanCacheEvictions(metrics Metrics, evicted int) {
+	for i := 0; i < evicted; i++ {
+		metrics.PlanCacheEvicted()
 	}
 }
 
@@ -262,14 +345,13 @@ func (c *PlanCache) store(schema *Schema, key string, pr PlanResult) {
 // returning a PlanResult ready to store. Exposed as a free function
 // (no receiver) so the nil-receiver Get can fall through to it
 // without re-implementing the pipeline.
-func planAndValidate(schema *Schema, query, operationName string) PlanResult {
-	src := source.NewSource(&source.Source{Body: []byte(query), Name: "GraphQL request"})
-	doc, parseErr := parser.Parse(parser.ParseParams{Source: src})
+func planAndValidate(ctx context.Context, schema *Schema, query, operationName string) PlanResult {
+	doc, parseErr := parseTraced(ctx, schema, query)
 	if parseErr != nil {
-		return PlanResult{Errors: gqlerrors.FormatErrors(parseErr)}
+		return PlanResult{Errors: gqlerrors.FormatErrors(parseErr), errorCode: ErrCodeParseFailed}
 	}
-	if vr := ValidateDocument(schema, doc, nil); !vr.IsValid {
-		return PlanResult{Errors: vr.Errors}
+	if vr := validateTraced(ctx, schema, doc, validationRules(schema, nil)); !vr.IsValid {
+		return PlanResult{Errors: vr.Errors, errorCode: ErrCodeValidationFailed}
 	}
 	plan, err := PlanQuery(schema, doc, operationName)
 	if err != nil {
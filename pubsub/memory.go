@@ -0,0 +1,189 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryBroker is an in-process Broker. Publish delivers to each
+// subscriber's buffer according to that subscriber's OverflowPolicy.
+type MemoryBroker struct {
+	mu     sync.RWMutex
+	topics map[string]map[*memorySubscriber]struct{}
+	closed bool
+}
+
+// NewMemoryBroker returns an empty broker.
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		topics: map[string]map[*memorySubscriber]struct{}{},
+	}
+}
+
+type memorySubscriber struct {
+	broker *MemoryBroker
+	topic  string
+	opts   SubscribeOptions
+	ch     chan interface{}
+
+	// done is closed first when the subscriber goes away, so a Publish
+	// blocked on a full buffer gives up before ch is closed under mu.
+	done      chan struct{}
+	closeOnce sync.Once
+	stop      func() bool
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// Subscribe implements Broker.
+func (b *MemoryBroker) Subscribe(ctx context.Context, topic string, opts SubscribeOptions) (<-chan interface{}, error) {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = defaultBufferSize
+	}
+	sub := &memorySubscriber{
+		broker: b,
+		topic:  topic,
+		opts:   opts,
+		ch:     make(chan interface{}, opts.BufferSize),
+		done:   make(chan struct{}),
+	}
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil, ErrClosed
+	}
+	subs, ok := b.topics[topic]
+	if !ok {
+		subs = map[*memorySubscriber]struct{}{}
+		b.topics[topic] = subs
+	}
+	subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	// Publish and Close may already be closing the subscriber, reading
+	// stop under s.mu
+	sub.mu.Lock()
+	if !sub.closed {
+		sub.stop = context.AfterFunc(ctx, sub.close)
+	}
+	sub.mu.Unlock()
+	return sub.ch, nil
+}
+
+// Publish implements Broker. It returns ctx's error if ctx is done while
+// waiting on a subscriber with the Block policy.
+func (b *MemoryBroker) Publish(ctx context.Context, topic string, payload interface{}) error {
+	b.mu.RLock()
+	subs := make([]*memorySubscriber, 0, len(b.topics[topic]))
+	for sub := range b.topics[topic] {
+		subs = append(subs, sub)
+	}
+	b.mu.RUnlock()
+
+	for _, sub := range subs {
+		if sub.opts.Filter != nil && !sub.opts.Filter(payload) {
+			continue
+		}
+		if err := sub.deliver(ctx, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribers returns the number of current subscribers of topic.
+func (b *MemoryBroker) Subscribers(topic string) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.topics[topic])
+}
+
+// Close removes every subscriber, closing their channels, and makes
+// further Subscribe calls fail with ErrClosed.
+func (b *MemoryBroker) Close() {
+	b.mu.Lock()
+	b.closed = true
+	var subs []*memorySubscriber
+	for _, topicSubs := range b.topics {
+		for sub := range topicSubs {
+			subs = append(subs, sub)
+		}
+	}
+	b.mu.Unlock()
+	for _, sub := range subs {
+		sub.close()
+	}
+}
+
+func (b *MemoryBroker) remove(sub *memorySubscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subs := b.topics[sub.topic]
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.topics, sub.topic)
+	}
+}
+
+func (s *memorySubscriber) deliver(ctx context.Context, payload interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	switch s.opts.Overflow {
+	case Block:
+		select {
+		case s.ch <- payload:
+		case <-s.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	case Disconnect:
+		select {
+		case s.ch <- payload:
+		default:
+			// can't call close: it takes s.mu
+			s.closeLocked()
+		}
+	default:
+		for {
+			select {
+			case s.ch <- payload:
+				return nil
+			default:
+			}
+			// buffer full: drop the oldest payload and retry
+			select {
+			case <-s.ch:
+			default:
+			}
+		}
+	}
+	return nil
+}
+
+func (s *memorySubscriber) close() {
+	s.signalDone()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeLocked()
+}
+
+func (s *memorySubscriber) closeLocked() {
+	s.signalDone()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	if s.stop != nil {
+		s.stop()
+	}
+	s.broker.remove(s)
+	close(s.ch)
+}
+
+func (s *memorySubscriber) signalDone() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
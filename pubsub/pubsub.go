@@ -0,0 +1,118 @@
+// Package pubsub provides topic-based fan-out for GraphQL subscriptions.
+//
+// A Broker delivers published payloads to every subscriber of a topic; each
+// subscriber receives them on its own buffered channel, which plugs straight
+// into graphql.Field's Subscribe function:
+//
+//	broker := pubsub.NewMemoryBroker()
+//	field := &graphql.Field{
+//		Type: messageType,
+//		Subscribe: pubsub.FieldSubscriber(broker, pubsub.FieldSubscriberConfig{
+//			Topic: "messages",
+//		}),
+//		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+//			return p.Source, nil
+//		},
+//	}
+//	...
+//	broker.Publish(ctx, "messages", msg)
+//
+// MemoryBroker works within one process; the Broker interface lets
+// network-backed implementations (Redis, NATS, ...) be swapped in.
+package pubsub
+
+import (
+	"context"
+	"errors"
+
+	"github.com/graphql-go/graphql"
+)
+
+// OverflowPolicy says what happens when a payload is published to a
+// subscriber whose buffer is full.
+type OverflowPolicy int
+
+const (
+	// DropOldest discards the oldest buffered payload to make room.
+	DropOldest OverflowPolicy = iota
+	// Block makes Publish wait until the subscriber has room, the
+	// subscriber goes away, or the publisher's context is done.
+	Block
+	// Disconnect drops the subscriber: its channel is closed, which ends
+	// the GraphQL subscription reading from it.
+	Disconnect
+)
+
+const defaultBufferSize = 16
+
+// ErrClosed is returned when subscribing to a closed broker.
+var ErrClosed = errors.New("pubsub: broker is closed")
+
+// SubscribeOptions tunes one subscription. Zero values get sensible
+// defaults: a buffer of 16 payloads and DropOldest.
+type SubscribeOptions struct {
+	BufferSize int
+	Overflow   OverflowPolicy
+
+	// Filter, when set, is called with every payload published to the
+	// topic; payloads it rejects are not delivered to this subscriber.
+	Filter func(payload interface{}) bool
+}
+
+// Broker is a topic-based publish/subscribe hub. Implementations must be
+// safe for concurrent use.
+type Broker interface {
+	// Publish delivers payload to every current subscriber of topic.
+	Publish(ctx context.Context, topic string, payload interface{}) error
+
+	// Subscribe registers a subscriber to topic. The returned channel
+	// yields the payloads published from then on; it is closed, and the
+	// subscriber removed, when ctx is done.
+	Subscribe(ctx context.Context, topic string, opts SubscribeOptions) (<-chan interface{}, error)
+}
+
+// FilterFn decides whether payload is delivered to the subscription that
+// was set up with p. It typically compares the payload with p.Args, e.g. to
+// only deliver messages for the room the client subscribed to.
+type FilterFn func(payload interface{}, p graphql.ResolveParams) bool
+
+// FieldSubscriberConfig configures FieldSubscriber. TopicFn, when set,
+// computes the topic from the field's arguments and takes precedence over
+// Topic.
+type FieldSubscriberConfig struct {
+	Topic   string
+	TopicFn func(p graphql.ResolveParams) (string, error)
+	Filter  FilterFn
+	Options SubscribeOptions
+}
+
+// FieldSubscriber returns a function for graphql.Field's Subscribe that
+// subscribes to a topic of broker for the lifetime of the GraphQL
+// subscription: the subscriber is removed as soon as the subscription's
+// context is cancelled.
+func FieldSubscriber(broker Broker, config FieldSubscriberConfig) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		topic := config.Topic
+		if config.TopicFn != nil {
+			var err error
+			if topic, err = config.TopicFn(p); err != nil {
+				return nil, err
+			}
+		}
+		opts := config.Options
+		if config.Filter != nil {
+			filter, inner := config.Filter, opts.Filter
+			opts.Filter = func(payload interface{}) bool {
+				if inner != nil && !inner(payload) {
+					return false
+				}
+				return filter(payload, p)
+			}
+		}
+		ctx := p.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		return broker.Subscribe(ctx, topic, opts)
+	}
+}
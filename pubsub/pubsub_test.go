@@ -0,0 +1,248 @@
+package pubsub_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/pubsub"
+)
+
+func receive(t *testing.T, ch <-chan interface{}) (interface{}, bool) {
+	t.Helper()
+	select {
+	case v, ok := <-ch:
+		return v, ok
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a payload")
+	}
+	return nil, false
+}
+
+func drain(ch <-chan interface{}) []interface{} {
+	var got []interface{}
+	for {
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				return got
+			}
+			got = append(got, v)
+		default:
+			return got
+		}
+	}
+}
+
+func TestMemoryBroker_PublishesToTopicSubscribers(t *testing.T) {
+	b := pubsub.NewMemoryBroker()
+	ctx := context.Background()
+	a1, _ := b.Subscribe(ctx, "a", pubsub.SubscribeOptions{})
+	a2, _ := b.Subscribe(ctx, "a", pubsub.SubscribeOptions{})
+	other, _ := b.Subscribe(ctx, "b", pubsub.SubscribeOptions{})
+
+	if err := b.Publish(ctx, "a", 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, ch := range []<-chan interface{}{a1, a2} {
+		if v, _ := receive(t, ch); v != 1 {
+			t.Fatalf("expected 1, got %v", v)
+		}
+	}
+	if got := drain(other); len(got) != 0 {
+		t.Fatalf("expected nothing on another topic, got %v", got)
+	}
+}
+
+func TestMemoryBroker_DropOldest(t *testing.T) {
+	b := pubsub.NewMemoryBroker()
+	ctx := context.Background()
+	ch, _ := b.Subscribe(ctx, "t", pubsub.SubscribeOptions{BufferSize: 2, Overflow: pubsub.DropOldest})
+	for i := 1; i <= 4; i++ {
+		if err := b.Publish(ctx, "t", i); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got, want := drain(ch), []interface{}{3, 4}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestMemoryBroker_Block(t *testing.T) {
+	b := pubsub.NewMemoryBroker()
+	ch, _ := b.Subscribe(context.Background(), "t", pubsub.SubscribeOptions{BufferSize: 1, Overflow: pubsub.Block})
+	if err := b.Publish(context.Background(), "t", 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := b.Publish(ctx, "t", 2); err != context.DeadlineExceeded {
+		t.Fatalf("expected publish to block until the deadline, got %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- b.Publish(context.Background(), "t", 3) }()
+	if v, _ := receive(t, ch); v != 1 {
+		t.Fatalf("expected 1, got %v", v)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, _ := receive(t, ch); v != 3 {
+		t.Fatalf("expected 3, got %v", v)
+	}
+}
+
+func TestMemoryBroker_Disconnect(t *testing.T) {
+	b := pubsub.NewMemoryBroker()
+	ctx := context.Background()
+	ch, _ := b.Subscribe(ctx, "t", pubsub.SubscribeOptions{BufferSize: 1, Overflow: pubsub.Disconnect})
+	b.Publish(ctx, "t", 1)
+	b.Publish(ctx, "t", 2)
+
+	if v, ok := receive(t, ch); !ok || v != 1 {
+		t.Fatalf("expected buffered 1, got %v (open: %v)", v, ok)
+	}
+	if _, ok := receive(t, ch); ok {
+		t.Fatal("expected the channel to be closed")
+	}
+	if n := b.Subscribers("t"); n != 0 {
+		t.Fatalf("expected the subscriber to be removed, have %d", n)
+	}
+}
+
+func TestMemoryBroker_PublishWhileSubscribing(t *testing.T) {
+	b := pubsub.NewMemoryBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			// nobody reads, so each publish past the first disconnects
+			// the subscribers
+			b.Publish(ctx, "t", i)
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		if _, err := b.Subscribe(ctx, "t", pubsub.SubscribeOptions{BufferSize: 1, Overflow: pubsub.Disconnect}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	<-done
+	b.Close()
+	if n := b.Subscribers("t"); n != 0 {
+		t.Fatalf("expected no subscribers, got %d", n)
+	}
+}
+
+func TestMemoryBroker_UnsubscribesOnContextCancel(t *testing.T) {
+	b := pubsub.NewMemoryBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, _ := b.Subscribe(ctx, "t", pubsub.SubscribeOptions{})
+	if n := b.Subscribers("t"); n != 1 {
+		t.Fatalf("expected 1 subscriber, have %d", n)
+	}
+	cancel()
+	if _, ok := receive(t, ch); ok {
+		t.Fatal("expected the channel to be closed")
+	}
+	if n := b.Subscribers("t"); n != 0 {
+		t.Fatalf("expected no subscribers, have %d", n)
+	}
+	if err := b.Publish(context.Background(), "t", 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMemoryBroker_Close(t *testing.T) {
+	b := pubsub.NewMemoryBroker()
+	ch, _ := b.Subscribe(context.Background(), "t", pubsub.SubscribeOptions{})
+	b.Close()
+	if _, ok := receive(t, ch); ok {
+		t.Fatal("expected the channel to be closed")
+	}
+	if _, err := b.Subscribe(context.Background(), "t", pubsub.SubscribeOptions{}); err != pubsub.ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestFieldSubscriber_FiltersOnArgs(t *testing.T) {
+	type message struct {
+		Room string
+		Text string
+	}
+	broker := pubsub.NewMemoryBroker()
+	messageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Message",
+		Fields: graphql.Fields{
+			"text": &graphql.Field{Type: graphql.String},
+		},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name:   "Query",
+			Fields: graphql.Fields{"ok": &graphql.Field{Type: graphql.Boolean}},
+		}),
+		Subscription: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Subscription",
+			Fields: graphql.Fields{
+				"messageAdded": &graphql.Field{
+					Type: messageType,
+					Args: graphql.FieldConfigArgument{
+						"room": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					},
+					Subscribe: pubsub.FieldSubscriber(broker, pubsub.FieldSubscriberConfig{
+						Topic: "messages",
+						Filter: func(payload interface{}, p graphql.ResolveParams) bool {
+							return payload.(message).Room == p.Args["room"]
+						},
+					}),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source, nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := graphql.Subscribe(graphql.Params{
+		Schema:        schema,
+		RequestString: `subscription { messageAdded(room: "go") { text } }`,
+		Context:       ctx,
+	})
+	for broker.Subscribers("messages") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	broker.Publish(context.Background(), "messages", message{Room: "rust", Text: "skipped"})
+	broker.Publish(context.Background(), "messages", message{Room: "go", Text: "hello"})
+
+	res := <-results
+	if len(res.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", res.Errors)
+	}
+	expected := map[string]interface{}{"messageAdded": map[string]interface{}{"text": "hello"}}
+	if !reflect.DeepEqual(res.Data, expected) {
+		t.Fatalf("expected %v, got %v", expected, res.Data)
+	}
+
+	cancel()
+	for range results {
+	}
+	// the broker unsubscribes from its own context callback
+	deadline := time.Now().Add(time.Second)
+	for broker.Subscribers("messages") != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the subscription to unsubscribe")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
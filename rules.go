@@ -1,6 +1,7 @@
 package graphql
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"reflect"
@@ -33,6 +34,7 @@ var SpecifiedRules = []ValidationRuleFn{
 	PossibleFragmentSpreadsRule,
 	ProvidedNonNullArgumentsRule,
 	ScalarLeafsRule,
+	SingleFieldSubscriptionsRule,
 	UniqueArgumentNamesRule,
 	UniqueFragmentNamesRule,
 	UniqueInputFieldNamesRule,
@@ -623,6 +625,9 @@ func getDirectiveLocationForASTPath(ancestors []ast.Node) string {
 	if kind == kinds.FragmentDefinition {
 		return DirectiveLocationFragmentDefinition
 	}
+	if kind == kinds.VariableDefinition {
+		return DirectiveLocationVariableDefinition
+	}
 	if kind == kinds.SchemaDefinition {
 		return DirectiveLocationSchema
 	}
@@ -931,6 +936,71 @@ func UndefinedVarMessage(varName string, opName string) string {
 	return fmt.Sprintf(`Variable "$%v" is not defined.`, varName)
 }
 
+// NoSchemaIntrospectionRule No schema introspection
+//
+// A GraphQL document is only valid if it doesn't select __schema or __type,
+// for servers that don't expose their schema; __typename is allowed. It
+// isn't one of the SpecifiedRules: it applies to schemas built with
+// SchemaConfig.DisableIntrospection and to requests whose context comes
+// from WithNoSchemaIntrospection.
+func NoSchemaIntrospectionRule(context *ValidationContext) *ValidationRuleInstance {
+	visitorOpts := &visitor.VisitorOptions{
+		KindFuncMap: map[string]visitor.NamedVisitFuncs{
+			kinds.Field: {
+				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
+					if node, ok := p.Node.(*ast.Field); ok && node != nil {
+						named := GetNamed(context.Type())
+						if named == SchemaType || named == TypeType {
+							reportError(
+								context,
+								fmt.Sprintf(`GraphQL introspection has been disabled, but the requested query contained the field "%v".`, node.Name.Value),
+								[]ast.Node{node},
+							)
+							// one error per entry point, not per nested field
+							return visitor.ActionSkip, nil
+						}
+					}
+					return visitor.ActionNoChange, nil
+				},
+			},
+		},
+	}
+	return &ValidationRuleInstance{
+		VisitorOpts: visitorOpts,
+	}
+}
+
+type noSchemaIntrospectionKey struct{}
+
+// WithNoSchemaIntrospection returns a copy of ctx under which requests are
+// validated with NoSchemaIntrospectionRule, e.g. the requests of untrusted
+// clients. Do and Subscribe validate with it; ExecutePlan and
+// ExecuteSubscriptionPlan check it too, as plans taken from a PlanCache
+// are shared by all requests.
+func WithNoSchemaIntrospection(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noSchemaIntrospectionKey{}, true)
+}
+
+// noSchemaIntrospection reports whether NoSchemaIntrospectionRule applies to
+// a request against schema with context ctx.
+func noSchemaIntrospection(schema *Schema, ctx context.Context) bool {
+	if schema != nil && schema.disableIntrospection {
+		return true
+	}
+	return ctx != nil && ctx.Value(noSchemaIntrospectionKey{}) != nil
+}
+
+// validationRules returns the rules requests against schema with context
+// ctx are validated with.
+func validationRules(schema *Schema, ctx context.Context) []ValidationRuleFn {
+	if !noSchemaIntrospection(schema, ctx) {
+		return SpecifiedRules
+	}
+	rules := make([]ValidationRuleFn, 0, len(SpecifiedRules)+1)
+	rules = append(rules, SpecifiedRules...)
+	return append(rules, NoSchemaIntrospectionRule)
+}
+
 // NoUndefinedVariablesRule No undefined variables
 //
 // A GraphQL operation is only valid if all variables encountered, both directly
@@ -1381,6 +1451,77 @@ func ScalarLeafsRule(context *ValidationContext) *ValidationRuleInstance {
 	}
 }
 
+// SingleFieldSubscriptionsRule Subscriptions must only include one field.
+//
+// A GraphQL subscription is valid only if it contains a single root field,
+// counting the fields selected through fragments.
+func SingleFieldSubscriptionsRule(context *ValidationContext) *ValidationRuleInstance {
+	visitorOpts := &visitor.VisitorOptions{
+		KindFuncMap: map[string]visitor.NamedVisitFuncs{
+			kinds.OperationDefinition: {
+				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
+					if node, ok := p.Node.(*ast.OperationDefinition); ok && node != nil {
+						if node.Operation != ast.OperationTypeSubscription {
+							return visitor.ActionSkip, nil
+						}
+						fields := collectRootFieldASTs(context, node.SelectionSet, map[string]bool{}, map[string]bool{}, nil)
+						if len(fields) > 1 {
+							message := `Anonymous Subscription must select only one top level field.`
+							if node.Name != nil {
+								message = fmt.Sprintf(`Subscription "%v" must select only one top level field.`, node.Name.Value)
+							}
+							extraFields := []ast.Node{}
+							for _, field := range fields[1:] {
+								extraFields = append(extraFields, field)
+							}
+							reportError(context, message, extraFields)
+						}
+					}
+					return visitor.ActionSkip, nil
+				},
+			},
+			kinds.FragmentDefinition: {
+				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
+					return visitor.ActionSkip, nil
+				},
+			},
+		},
+	}
+	return &ValidationRuleInstance{
+		VisitorOpts: visitorOpts,
+	}
+}
+
+// collectRootFieldASTs returns the first field AST of every distinct
+// response key selected by selectionSet, following fragment spreads and
+// inline fragments.
+func collectRootFieldASTs(context *ValidationContext, selectionSet *ast.SelectionSet, seenKeys, visitedFragments map[string]bool, fields []*ast.Field) []*ast.Field {
+	if selectionSet == nil {
+		return fields
+	}
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			key := getFieldEntryKey(selection)
+			if !seenKeys[key] {
+				seenKeys[key] = true
+				fields = append(fields, selection)
+			}
+		case *ast.InlineFragment:
+			fields = collectRootFieldASTs(context, selection.SelectionSet, seenKeys, visitedFragments, fields)
+		case *ast.FragmentSpread:
+			if selection.Name == nil || visitedFragments[selection.Name.Value] {
+				continue
+			}
+			visitedFragments[selection.Name.Value] = true
+			if fragment := context.Fragment(selection.Name.Value); fragment != nil {
+				fields = collectRootFieldASTs(context, fragment.SelectionSet, seenKeys, visitedFragments, fields)
+			}
+		}
+	}
+	return fields
+}
+
 // UniqueArgumentNamesRule Unique argument names
 //
 // A GraphQL field or directive is only valid if all supplied arguments are
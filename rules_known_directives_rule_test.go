@@ -93,6 +93,32 @@ func TestValidate_KnownDirectives_WithMisplacedDirectives(t *testing.T) {
 		testutil.RuleError(`Directive "onQuery" may not be used on MUTATION.`, 7, 20),
 	})
 }
+func TestValidate_KnownDirectives_WithWellPlacedVariableDefinitionDirective(t *testing.T) {
+	testutil.ExpectPassesRule(t, graphql.KnownDirectivesRule, `
+      query Foo($var: Boolean @onVariableDefinition) {
+        name
+      }
+    `)
+}
+func TestValidate_KnownDirectives_WithMisplacedVariableDefinitionDirective(t *testing.T) {
+	testutil.ExpectFailsRule(t, graphql.KnownDirectivesRule, `
+      query Foo($var: Boolean = true @onQuery) @onVariableDefinition {
+        name
+      }
+    `, []gqlerrors.FormattedError{
+		testutil.RuleError(`Directive "onQuery" may not be used on VARIABLE_DEFINITION.`, 2, 38),
+		testutil.RuleError(`Directive "onVariableDefinition" may not be used on QUERY.`, 2, 48),
+	})
+}
+func TestValidate_KnownDirectives_WithUnknownVariableDefinitionDirective(t *testing.T) {
+	testutil.ExpectFailsRule(t, graphql.KnownDirectivesRule, `
+      query Foo($var: Boolean @unknown) {
+        name
+      }
+    `, []gqlerrors.FormattedError{
+		testutil.RuleError(`Unknown directive "unknown".`, 2, 31),
+	})
+}
 
 func TestValidate_KnownDirectives_WithinSchemaLanguage_WithWellPlacedDirectives(t *testing.T) {
 	testutil.ExpectPassesRule(t, graphql.KnownDirectivesRule, `
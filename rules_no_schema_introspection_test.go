@@ -0,0 +1,150 @@
+package graphql_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/testutil"
+)
+
+func TestValidate_NoSchemaIntrospection_AllowsTypename(t *testing.T) {
+	testutil.ExpectPassesRule(t, graphql.NoSchemaIntrospectionRule, `
+      {
+        __typename
+        dog { __typename name }
+      }
+    `)
+}
+func TestValidate_NoSchemaIntrospection_AllowsRegularFields(t *testing.T) {
+	testutil.ExpectPassesRule(t, graphql.NoSchemaIntrospectionRule, `
+      {
+        human { name pets { name } }
+      }
+    `)
+}
+func TestValidate_NoSchemaIntrospection_RejectsSchema(t *testing.T) {
+	testutil.ExpectFailsRule(t, graphql.NoSchemaIntrospectionRule, `
+      {
+        __schema {
+          queryType { name }
+          types { name fields { type { name } } }
+        }
+      }
+    `, []gqlerrors.FormattedError{
+		testutil.RuleError(`GraphQL introspection has been disabled, but the requested query contained the field "__schema".`, 3, 9),
+	})
+}
+func TestValidate_NoSchemaIntrospection_RejectsType(t *testing.T) {
+	testutil.ExpectFailsRule(t, graphql.NoSchemaIntrospectionRule, `
+      {
+        dog { name }
+        t: __type(name: "Dog") { name }
+      }
+    `, []gqlerrors.FormattedError{
+		testutil.RuleError(`GraphQL introspection has been disabled, but the requested query contained the field "__type".`, 4, 9),
+	})
+}
+func TestValidate_NoSchemaIntrospection_RejectsIntrospectionInFragments(t *testing.T) {
+	testutil.ExpectFailsRule(t, graphql.NoSchemaIntrospectionRule, `
+      { ...Q }
+      fragment Q on QueryRoot {
+        __schema { types { name } }
+      }
+    `, []gqlerrors.FormattedError{
+		testutil.RuleError(`GraphQL introspection has been disabled, but the requested query contained the field "__schema".`, 4, 9),
+	})
+}
+
+func noIntrospectionSchema(t *testing.T, disabled bool) *graphql.Schema {
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"hello": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return "world", nil
+					},
+				},
+			},
+		}),
+		DisableIntrospection: disabled,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &schema
+}
+
+const introspectionDisabledMessage = `GraphQL introspection has been disabled, but the requested query contained the field "__schema".`
+
+func expectIntrospectionDisabled(t *testing.T, result *graphql.Result) {
+	t.Helper()
+	if result.Data != nil || len(result.Errors) != 1 || result.Errors[0].Message != introspectionDisabledMessage {
+		t.Fatalf("expected introspection to be rejected, got %+v", result)
+	}
+	if len(result.Errors[0].Locations) != 1 {
+		t.Fatalf("expected a located error, got %+v", result.Errors[0])
+	}
+}
+
+func TestNoSchemaIntrospection_SchemaToggle(t *testing.T) {
+	schema := noIntrospectionSchema(t, true)
+	expectIntrospectionDisabled(t, graphql.Do(graphql.Params{
+		Schema:        *schema,
+		RequestString: `{ __schema { queryType { name } } }`,
+	}))
+
+	result := graphql.Do(graphql.Params{
+		Schema:        *schema,
+		RequestString: `{ __typename hello }`,
+	})
+	expected := map[string]interface{}{"__typename": "Query", "hello": "world"}
+	if len(result.Errors) > 0 || !reflect.DeepEqual(result.Data, expected) {
+		t.Fatalf("expected %v, got %+v", expected, result)
+	}
+
+	pr := graphql.NewPlanCache(graphql.PlanCacheOptions{}).Get(schema, `{ __schema { queryType { name } } }`, "")
+	if len(pr.Errors) != 1 || pr.Errors[0].Message != introspectionDisabledMessage {
+		t.Fatalf("expected the cache to report the validation error, got %+v", pr)
+	}
+}
+
+func TestNoSchemaIntrospection_PerRequest(t *testing.T) {
+	schema := noIntrospectionSchema(t, false)
+	query := `{ __schema { queryType { name } } }`
+	expected := map[string]interface{}{
+		"__schema": map[string]interface{}{"queryType": map[string]interface{}{"name": "Query"}},
+	}
+
+	expectIntrospectionDisabled(t, graphql.Do(graphql.Params{
+		Schema:        *schema,
+		RequestString: query,
+		Context:       graphql.WithNoSchemaIntrospection(context.Background()),
+	}))
+	result := graphql.Do(graphql.Params{Schema: *schema, RequestString: query, Context: context.Background()})
+	if len(result.Errors) > 0 || !reflect.DeepEqual(result.Data, expected) {
+		t.Fatalf("expected %v, got %+v", expected, result)
+	}
+
+	// a cached plan is shared by requests that allow introspection and
+	// requests that don't
+	cache := graphql.NewPlanCache(graphql.PlanCacheOptions{})
+	for i := 0; i < 2; i++ {
+		pr := cache.Get(schema, query, "")
+		if len(pr.Errors) > 0 {
+			t.Fatalf("unexpected errors: %v", pr.Errors)
+		}
+		expectIntrospectionDisabled(t, graphql.ExecutePlan(pr.Plan, graphql.ExecuteParams{
+			Schema:  *schema,
+			Context: graphql.WithNoSchemaIntrospection(context.Background()),
+		}))
+		result = graphql.ExecutePlan(pr.Plan, graphql.ExecuteParams{Schema: *schema, Context: context.Background()})
+		if len(result.Errors) > 0 || !reflect.DeepEqual(result.Data, expected) {
+			t.Fatalf("expected %v, got %+v", expected, result)
+		}
+	}
+}
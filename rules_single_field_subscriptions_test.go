@@ -0,0 +1,89 @@
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/testutil"
+)
+
+func TestValidate_SubscriptionsWithSingleField_ValidSubscription(t *testing.T) {
+	testutil.ExpectPassesRule(t, graphql.SingleFieldSubscriptionsRule, `
+      subscription ImportantEmails {
+        importantEmails
+      }
+    `)
+}
+func TestValidate_SubscriptionsWithSingleField_ValidSubscriptionWithFragment(t *testing.T) {
+	testutil.ExpectPassesRule(t, graphql.SingleFieldSubscriptionsRule, `
+      subscription sub {
+        ...newMessageFields
+      }
+      fragment newMessageFields on SubscriptionRoot {
+        newMessage {
+          body
+        }
+      }
+    `)
+}
+func TestValidate_SubscriptionsWithSingleField_SameResponseKeyTwice(t *testing.T) {
+	testutil.ExpectPassesRule(t, graphql.SingleFieldSubscriptionsRule, `
+      subscription ImportantEmails {
+        importantEmails
+        ... { importantEmails }
+      }
+    `)
+}
+func TestValidate_SubscriptionsWithSingleField_QueriesAreNotAffected(t *testing.T) {
+	testutil.ExpectPassesRule(t, graphql.SingleFieldSubscriptionsRule, `
+      query {
+        importantEmails
+        notImportantEmails
+      }
+    `)
+}
+func TestValidate_SubscriptionsWithSingleField_FailsWithMoreThanOneRootField(t *testing.T) {
+	testutil.ExpectFailsRule(t, graphql.SingleFieldSubscriptionsRule, `
+      subscription ImportantEmails {
+        importantEmails
+        notImportantEmails
+      }
+    `, []gqlerrors.FormattedError{
+		testutil.RuleError(`Subscription "ImportantEmails" must select only one top level field.`, 4, 9),
+	})
+}
+func TestValidate_SubscriptionsWithSingleField_FailsWithManyMoreThanOneRootField(t *testing.T) {
+	testutil.ExpectFailsRule(t, graphql.SingleFieldSubscriptionsRule, `
+      subscription ImportantEmails {
+        importantEmails
+        notImportantEmails
+        spamEmails
+      }
+    `, []gqlerrors.FormattedError{
+		testutil.RuleError(`Subscription "ImportantEmails" must select only one top level field.`, 4, 9, 5, 9),
+	})
+}
+func TestValidate_SubscriptionsWithSingleField_FailsWithMoreThanOneRootFieldInFragment(t *testing.T) {
+	testutil.ExpectFailsRule(t, graphql.SingleFieldSubscriptionsRule, `
+      subscription ImportantEmails {
+        importantEmails
+        ...moreFields
+      }
+      fragment moreFields on SubscriptionRoot {
+        notImportantEmails
+      }
+    `, []gqlerrors.FormattedError{
+		testutil.RuleError(`Subscription "ImportantEmails" must select only one top level field.`, 7, 9),
+	})
+}
+func TestValidate_SubscriptionsWithSingleField_FailsWithMoreThanOneRootFieldInAnonymousSubscription(t *testing.T) {
+	testutil.ExpectFailsRule(t, graphql.SingleFieldSubscriptionsRule, `
+      subscription {
+        importantEmails
+        notImportantEmails
+      }
+    `, []gqlerrors.FormattedError{
+		testutil.RuleError(`Anonymous Subscription must select only one top level field.`, 4, 9),
+	})
+}
@@ -7,6 +7,17 @@ type SchemaConfig struct {
 	Types        []Type
 	Directives   []*Directive
 	Extensions   []Extension
+
+	// DisableIntrospection validates every request with
+	// NoSchemaIntrospectionRule.
+	DisableIntrospection bool
+
+	// Tracer, when set, traces the requests against the schema.
+	Tracer Tracer
+
+	// Metrics, when set, receives the measurements of the requests against
+	// the schema.
+	Metrics Metrics
 }
 
 type TypeMap map[string]Type
@@ -41,6 +52,10 @@ type Schema struct {
 	implementations  map[string][]*Object
 	possibleTypeMap  map[string]map[string]bool
 	extensions       []Extension
+
+	disableIntrospection bool
+	tracer               Tracer
+	metrics              Metrics
 }
 
 func NewSchema(config SchemaConfig) (Schema, error) {
@@ -63,6 +78,9 @@ func NewSchema(config SchemaConfig) (Schema, error) {
 	schema.queryType = config.Query
 	schema.mutationType = config.Mutation
 	schema.subscriptionType = config.Subscription
+	schema.disableIntrospection = config.DisableIntrospection
+	schema.tracer = config.Tracer
+	schema.metrics = config.Metrics
 
 	// Provide specified directives (e.g. @include and @skip) by default.
 	schema.directives = config.Directives
@@ -260,9 +278,25 @@ func (gq *Schema) IsPossibleType(abstractType Abstract, possibleType *Object) bo
 	return false
 }
 
-// AddExtensions can be used to add additional extensions to the schema
+// AddExtensions can be used to add additional extensions to the schema.
+// The extensions are copied, so that copies of the schema taken earlier,
+// such as the Schema of a running request's Params, keep theirs; use
+// Params.Extensions or ExecuteParams.Extensions for the extensions of a
+// single request.
 func (gq *Schema) AddExtensions(e ...Extension) {
-	gq.extensions = append(gq.extensions, e...)
+	gq.extensions = append(gq.extensions[:len(gq.extensions):len(gq.extensions)], e...)
+}
+
+// SetTracer sets the Tracer of the requests against the schema; nil stops
+// tracing.
+func (gq *Schema) SetTracer(tracer Tracer) {
+	gq.tracer = tracer
+}
+
+// SetMetrics sets the Metrics of the requests against the schema; nil
+// stops measuring.
+func (gq *Schema) SetMetrics(metrics Metrics) {
+	gq.metrics = metrics
 }
 
 // map-reduce
@@ -2,11 +2,12 @@ package graphql
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
 
 	"github.com/graphql-go/graphql/gqlerrors"
-	"github.com/graphql-go/graphql/language/parser"
-	"github.com/graphql-go/graphql/language/source"
+	"github.com/graphql-go/graphql/language/ast"
 )
 
 // SubscribeParams parameters for subscribing
@@ -23,43 +24,87 @@ type SubscribeParams struct {
 
 // Subscribe performs a subscribe operation on the given query and schema
 // To finish a subscription you can simply close the channel from inside the `Subscribe` function
-// currently does not support extensions hooks
+// The extensions' Init, ParseDidStart and ValidationDidStart hooks run for
+// the subscription setup; see ExecuteSubscription for the per-event hooks.
 func Subscribe(p Params) chan *Result {
+	// run init on the extensions
+	exts := requestExtensions(&p.Schema, p.Extensions, p.SkipExtensions)
+	extErrs := handleExtensionsInits(&p, exts)
+	if len(extErrs) != 0 {
+		return sendOneResultAndClose(&Result{
+			Errors: PresentErrors(p.Context, p.ErrorPresenter, extErrs),
+		})
+	}
 
-	source := source.NewSource(&source.Source{
-		Body: []byte(p.RequestString),
-		Name: "GraphQL request",
-	})
-
-	// TODO run extensions hooks
+	extErrs, parseFinishFn := handleExtensionsParseDidStart(&p, exts)
+	if len(extErrs) != 0 {
+		return sendOneResultAndClose(&Result{
+			Errors: PresentErrors(p.Context, p.ErrorPresenter, extErrs),
+		})
+	}
 
 	// parse the source
-	AST, err := parser.Parse(parser.ParseParams{Source: source})
+	AST, err := parseTraced(p.Context, &p.Schema, p.RequestString)
 	if err != nil {
+		// run parseFinishFuncs for extensions
+		extErrs = parseFinishFn(err)
 
 		// merge the errors from extensions and the original error from parser
+		extErrs = append(extErrs, gqlerrors.FormatErrors(err)...)
+		return sendOneResultAndClose(&Result{
+			Errors: PresentErrors(p.Context, p.ErrorPresenter, extErrs),
+		})
+	}
+
+	// run parseFinish functions for extensions
+	extErrs = parseFinishFn(err)
+	if len(extErrs) != 0 {
 		return sendOneResultAndClose(&Result{
-			Errors: gqlerrors.FormatErrors(err),
+			Errors: PresentErrors(p.Context, p.ErrorPresenter, extErrs),
+		})
+	}
+
+	// notify extensions about the start of the validation
+	extErrs, validationFinishFn := handleExtensionsValidationDidStart(&p, exts)
+	if len(extErrs) != 0 {
+		return sendOneResultAndClose(&Result{
+			Errors: PresentErrors(p.Context, p.ErrorPresenter, extErrs),
 		})
 	}
 
 	// validate document
-	validationResult := ValidateDocument(&p.Schema, AST, nil)
+	validationResult := validateTraced(p.Context, &p.Schema, AST, validationRules(&p.Schema, p.Context))
 
 	if !validationResult.IsValid {
 		// run validation finish functions for extensions
+		extErrs = validationFinishFn(validationResult.Errors)
+
+		// merge the errors from extensions and the original error from validation
+		extErrs = append(extErrs, validationResult.Errors...)
 		return sendOneResultAndClose(&Result{
-			Errors: validationResult.Errors,
+			Errors: PresentErrors(p.Context, p.ErrorPresenter, extErrs),
 		})
+	}
 
+	// run the validationFinishFuncs for extensions
+	extErrs = validationFinishFn(validationResult.Errors)
+	if len(extErrs) != 0 {
+		return sendOneResultAndClose(&Result{
+			Errors: PresentErrors(p.Context, p.ErrorPresenter, extErrs),
+		})
 	}
+
 	return ExecuteSubscription(ExecuteParams{
-		Schema:        p.Schema,
-		Root:          p.RootObject,
-		AST:           AST,
-		OperationName: p.OperationName,
-		Args:          p.VariableValues,
-		Context:       p.Context,
+		Schema:         p.Schema,
+		Root:           p.RootObject,
+		AST:            AST,
+		OperationName:  p.OperationName,
+		Args:           p.VariableValues,
+		Context:        p.Context,
+		ErrorPresenter: p.ErrorPresenter,
+		PanicHandler:   p.PanicHandler,
+		Extensions:     p.Extensions,
+		SkipExtensions: p.SkipExtensions,
 	})
 }
 
@@ -70,159 +115,265 @@ func sendOneResultAndClose(res *Result) chan *Result {
 	return resultChannel
 }
 
+// SubscriptionIterator is a pull-based source stream. A field's Subscribe
+// function may return one instead of a channel.
+type SubscriptionIterator interface {
+	// Next blocks until the next event is available or ctx is done. ok is
+	// false once the stream is exhausted.
+	Next(ctx context.Context) (event interface{}, ok bool, err error)
+
+	// Close releases the stream. It is called once the subscription
+	// terminates, however it terminates.
+	Close() error
+}
+
 // ExecuteSubscription is similar to graphql.Execute but returns a channel instead of a Result
-// currently does not support extensions
+// It plans the operation once and executes every event against that plan;
+// see ExecuteSubscriptionPlan.
 func ExecuteSubscription(p ExecuteParams) chan *Result {
+	plan, err := PlanQuery(&p.Schema, p.AST, p.OperationName)
+	if err != nil {
+		return sendOneResultAndClose(&Result{
+			Errors: PresentErrors(p.Context, p.ErrorPresenter, gqlerrors.FormatErrors(err)),
+		})
+	}
+	return ExecuteSubscriptionPlan(plan, p)
+}
 
+// ExecuteSubscriptionPlan runs a planned subscription operation. The root
+// field's Subscribe function is called once to set up the source stream;
+// every event it yields is then executed with ExecutePlan, the event being
+// the root value.
+//
+// The source stream may be any receive-capable channel (chan T or <-chan T
+// for any T), a SubscriptionIterator, or a single value, which produces one
+// event. The subscription ends when the stream is exhausted or p.Context is
+// done; the returned channel is closed then.
+//
+// Once the source stream is set up, the SubscriptionDidStart hook of every
+// SubscriptionExtension runs; each event is then executed like a query, running
+// the ExecutionDidStart and ResolveFieldDidStart hooks, and reported to the
+// SubscriptionEventFuncs. The SubscriptionFinishFuncs run when the stream ends.
+func ExecuteSubscriptionPlan(plan *Plan, p ExecuteParams) chan *Result {
 	if p.Context == nil {
 		p.Context = context.Background()
 	}
+	if plan == nil {
+		return sendOneResultAndClose(&Result{
+			Errors: PresentErrors(p.Context, p.ErrorPresenter, gqlerrors.FormatErrors(errors.New("graphql: ExecuteSubscriptionPlan: plan is nil"))),
+		})
+	}
+	if plan.operation.GetOperation() != ast.OperationTypeSubscription {
+		return sendOneResultAndClose(&Result{
+			Errors: PresentErrors(p.Context, p.ErrorPresenter, gqlerrors.FormatErrors(errors.New("graphql: ExecuteSubscriptionPlan: operation is not a subscription"))),
+		})
+	}
+	if noSchemaIntrospection(&p.Schema, p.Context) {
+		if errs := plan.introspectionErrors(); len(errs) > 0 {
+			return sendOneResultAndClose(&Result{Errors: PresentErrors(p.Context, p.ErrorPresenter, errs)})
+		}
+	}
 
 	var mapSourceToResponse = func(payload interface{}) *Result {
-		return Execute(ExecuteParams{
-			Schema:        p.Schema,
-			Root:          payload,
-			AST:           p.AST,
-			OperationName: p.OperationName,
-			Args:          p.Args,
-			Context:       p.Context,
+		return ExecutePlan(plan, ExecuteParams{
+			Schema:         p.Schema,
+			Root:           payload,
+			Args:           p.Args,
+			Context:        p.Context,
+			ErrorPresenter: p.ErrorPresenter,
+			PanicHandler:   p.PanicHandler,
+			Extensions:     p.Extensions,
+			SkipExtensions: p.SkipExtensions,
 		})
 	}
 	var resultChannel = make(chan *Result)
+	// send delivers a result unless the subscriber has gone away; it
+	// reports whether the result was delivered.
+	var send = func(result *Result) bool {
+		select {
+		case resultChannel <- result:
+			return true
+		case <-p.Context.Done():
+			return false
+		}
+	}
+	var sendError = func(err error) {
+		send(&Result{
+			Errors: PresentErrors(p.Context, p.ErrorPresenter, gqlerrors.FormatErrors(err)),
+		})
+	}
 	go func() {
 		defer close(resultChannel)
 		defer func() {
-			if err := recover(); err != nil {
-				e, ok := err.(error)
+			if r := recover(); r != nil {
+				err, ok := r.(error)
 				if !ok {
-					return
-				}
-				resultChannel <- &Result{
-					Errors: gqlerrors.FormatErrors(e),
+					err = fmt.Errorf("%v", r)
 				}
+				sendError(err)
 			}
-			return
 		}()
 
-		exeContext, err := buildExecutionContext(buildExecutionCtxParams{
-			Schema:        p.Schema,
-			Root:          p.Root,
-			AST:           p.AST,
-			OperationName: p.OperationName,
-			Args:          p.Args,
-			Context:       p.Context,
-		})
-
+		variableValues, err := getVariableValues(*plan.schema, plan.operation.GetVariableDefinitions(), p.Args)
 		if err != nil {
-			resultChannel <- &Result{
-				Errors: gqlerrors.FormatErrors(err),
-			}
-
+			sendError(err)
 			return
 		}
 
-		operationType, err := getOperationRootType(p.Schema, exeContext.Operation)
+		fp, err := subscriptionRootField(plan, variableValues)
 		if err != nil {
-			resultChannel <- &Result{
-				Errors: gqlerrors.FormatErrors(err),
-			}
-
+			sendError(err)
 			return
 		}
 
-		fields := collectFields(collectFieldsParams{
-			ExeContext:   exeContext,
-			RuntimeType:  operationType,
-			SelectionSet: exeContext.Operation.GetSelectionSet(),
-		})
-
-		responseNames := []string{}
-		for name := range fields {
-			responseNames = append(responseNames, name)
-		}
-		responseName := responseNames[0]
-		fieldNodes := fields[responseName]
-		fieldNode := fieldNodes[0]
-		fieldName := fieldNode.Name.Value
-		fieldDef := getFieldDef(p.Schema, operationType, fieldName)
-
-		if fieldDef == nil {
-			resultChannel <- &Result{
-				Errors: gqlerrors.FormatErrors(fmt.Errorf("the subscription field %q is not defined", fieldName)),
-			}
-
-			return
-		}
-
-		resolveFn := fieldDef.Subscribe
-
+		resolveFn := fp.fieldDef.Subscribe
 		if resolveFn == nil {
-			resultChannel <- &Result{
-				Errors: gqlerrors.FormatErrors(fmt.Errorf("the subscription function %q is not defined", fieldName)),
-			}
+			sendError(fmt.Errorf("the subscription function %q is not defined", fp.fieldName))
 			return
 		}
-		fieldPath := &ResponsePath{
-			Key: responseName,
-		}
 
-		args := getArgumentValues(fieldDef.Args, fieldNode.Arguments, exeContext.VariableValues)
 		info := ResolveInfo{
-			FieldName:      fieldName,
-			FieldASTs:      fieldNodes,
-			Path:           fieldPath,
-			ReturnType:     fieldDef.Type,
-			ParentType:     operationType,
-			Schema:         p.Schema,
-			Fragments:      exeContext.Fragments,
-			RootValue:      exeContext.Root,
-			Operation:      exeContext.Operation,
-			VariableValues: exeContext.VariableValues,
+			FieldName:      fp.fieldName,
+			FieldASTs:      fp.fieldASTs,
+			Path:           &ResponsePath{Key: fp.responseKey},
+			ReturnType:     fp.returnType,
+			ParentType:     plan.rootType,
+			Schema:         *plan.schema,
+			Fragments:      plan.fragments,
+			RootValue:      p.Root,
+			Operation:      plan.operation,
+			VariableValues: variableValues,
 		}
 
 		fieldResult, err := resolveFn(ResolveParams{
 			Source:  p.Root,
-			Args:    args,
+			Args:    fp.args.values(variableValues),
 			Info:    info,
 			Context: p.Context,
 		})
 		if err != nil {
-			resultChannel <- &Result{
-				Errors: gqlerrors.FormatErrors(err),
-			}
-
+			sendError(err)
 			return
 		}
 
 		if fieldResult == nil {
-			resultChannel <- &Result{
-				Errors: gqlerrors.FormatErrors(fmt.Errorf("no field result")),
-			}
+			sendError(fmt.Errorf("no field result"))
+			return
+		}
 
+		extErrs, subscriptionEventFn, subscriptionFinishFn := handleExtensionsSubscriptionDidStart(&p, requestExtensions(&p.Schema, p.Extensions, p.SkipExtensions))
+		if len(extErrs) != 0 {
+			send(&Result{Errors: PresentErrors(p.Context, p.ErrorPresenter, extErrs)})
 			return
 		}
+		var streamErr error
+		defer func() {
+			extErrs := subscriptionFinishFn(streamErr)
+			if len(extErrs) != 0 {
+				send(&Result{Errors: PresentErrors(p.Context, p.ErrorPresenter, extErrs)})
+			}
+		}()
+		// sendEvent executes the operation for an event and sends the
+		// result; it reports false once the subscriber has gone away.
+		sendEvent := func(payload interface{}) bool {
+			result := mapSourceToResponse(payload)
+			if extErrs := subscriptionEventFn(result); len(extErrs) != 0 {
+				result.Errors = append(result.Errors, PresentErrors(p.Context, p.ErrorPresenter, extErrs)...)
+			}
+			if !send(result) {
+				streamErr = p.Context.Err()
+				return false
+			}
+			return true
+		}
 
-		switch fieldResult.(type) {
+		switch source := fieldResult.(type) {
 		case chan interface{}:
-			sub := fieldResult.(chan interface{})
+			// fast path for the common channel type, without reflection
 			for {
 				select {
 				case <-p.Context.Done():
+					streamErr = p.Context.Err()
 					return
 
-				case res, more := <-sub:
-					if !more {
+				case res, more := <-source:
+					if !more || !sendEvent(res) {
 						return
 					}
-					resultChannel <- mapSourceToResponse(res)
 				}
 			}
-		default:
-			resultChannel <- mapSourceToResponse(fieldResult)
+		case SubscriptionIterator:
+			defer source.Close()
+			for {
+				res, more, err := source.Next(p.Context)
+				if err != nil {
+					streamErr = err
+					sendError(err)
+					return
+				}
+				if !more {
+					return
+				}
+				if p.Context.Err() != nil {
+					streamErr = p.Context.Err()
+					return
+				}
+				if !sendEvent(res) {
+					return
+				}
+			}
+		}
+
+		sourceVal := reflect.ValueOf(fieldResult)
+		if sourceVal.Kind() != reflect.Chan {
+			sendEvent(fieldResult)
 			return
 		}
+		if sourceVal.Type().ChanDir()&reflect.RecvDir == 0 {
+			streamErr = fmt.Errorf("the subscription source of %q is a send-only channel", fp.fieldName)
+			sendError(streamErr)
+			return
+		}
+		cases := []reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(p.Context.Done())},
+			{Dir: reflect.SelectRecv, Chan: sourceVal},
+		}
+		for {
+			chosen, res, more := reflect.Select(cases)
+			if chosen == 0 {
+				streamErr = p.Context.Err()
+				return
+			}
+			if !more || !sendEvent(res.Interface()) {
+				return
+			}
+		}
 	}()
 
 	// return a result channel
 	return resultChannel
 }
+
+// subscriptionRootField returns the single root field a subscription
+// selects, once @skip / @include are applied. SingleFieldSubscriptionsRule
+// rejects documents selecting more, but plans may be built from documents
+// that were never validated.
+func subscriptionRootField(plan *Plan, variableValues map[string]interface{}) (*fieldPlan, error) {
+	var selected []*fieldPlan
+	if plan.root != nil {
+		for _, fp := range plan.root.fields {
+			if fp.skipPredicate != nil && !fp.skipPredicate(variableValues) {
+				continue
+			}
+			selected = append(selected, fp)
+		}
+	}
+	if len(selected) != 1 {
+		return nil, errors.New("a subscription must select exactly one top level field")
+	}
+	fp := selected[0]
+	if fp.fieldDef == nil {
+		return nil, fmt.Errorf("the subscription field %q is not defined", fp.fieldName)
+	}
+	return fp, nil
+}
@@ -1,9 +1,12 @@
 package graphql_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/graphql-go/graphql"
 	"github.com/graphql-go/graphql/testutil"
@@ -87,7 +90,10 @@ func TestSchemaSubscribe(t *testing.T) {
 				}
 			`,
 			ExpectedResults: []testutil.TestResponse{
-				{Errors: []string{"Cannot query field \"xxx\" on type \"Subscription\"."}},
+				{Errors: []string{
+					"Anonymous Subscription must select only one top level field.",
+					"Cannot query field \"xxx\" on type \"Subscription\".",
+				}},
 			},
 		},
 		{
@@ -228,9 +234,279 @@ func TestSchemaSubscribe(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name: "subscribe to a typed channel",
+			Schema: makeSubscriptionSchema(t, graphql.ObjectConfig{
+				Name: "Subscription",
+				Fields: graphql.Fields{
+					"counter": &graphql.Field{
+						Type: graphql.Int,
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return p.Source, nil
+						},
+						Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+							c := make(chan int, 3)
+							c <- 1
+							c <- 2
+							c <- 3
+							close(c)
+							var recvOnly <-chan int = c
+							return recvOnly, nil
+						},
+					},
+				},
+			}),
+			Query: `
+				subscription {
+					counter
+				}
+			`,
+			ExpectedResults: []testutil.TestResponse{
+				{Data: `{ "counter": 1 }`},
+				{Data: `{ "counter": 2 }`},
+				{Data: `{ "counter": 3 }`},
+			},
+		},
+		{
+			Name: "subscribe to an iterator",
+			Schema: makeSubscriptionSchema(t, graphql.ObjectConfig{
+				Name: "Subscription",
+				Fields: graphql.Fields{
+					"letters": &graphql.Field{
+						Type: graphql.String,
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return p.Source, nil
+						},
+						Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+							return &sliceIterator{events: []interface{}{"x", "y"}}, nil
+						},
+					},
+				},
+			}),
+			Query: `
+				subscription {
+					letters
+				}
+			`,
+			ExpectedResults: []testutil.TestResponse{
+				{Data: `{ "letters": "x" }`},
+				{Data: `{ "letters": "y" }`},
+			},
+		},
+		{
+			Name: "subscribe with variable arguments",
+			Schema: makeSubscriptionSchema(t, graphql.ObjectConfig{
+				Name: "Subscription",
+				Fields: graphql.Fields{
+					"sub_with_args": &graphql.Field{
+						Type: graphql.String,
+						Args: graphql.FieldConfigArgument{
+							"prefix": &graphql.ArgumentConfig{Type: graphql.String},
+						},
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return fmt.Sprintf("%v%v", p.Args["prefix"], p.Source), nil
+						},
+						Subscribe: makeSubscribeToStringFunction([]string{"a"}),
+					},
+				},
+			}),
+			Query: `
+				subscription ($prefix: String) {
+					sub_with_args(prefix: $prefix)
+				}
+			`,
+			Variables: map[string]interface{}{"prefix": "p-"},
+			ExpectedResults: []testutil.TestResponse{
+				{Data: `{ "sub_with_args": "p-a" }`},
+			},
+		},
 	})
 }
 
+type sliceIterator struct {
+	events []interface{}
+	closed bool
+}
+
+func (it *sliceIterator) Next(ctx context.Context) (interface{}, bool, error) {
+	if len(it.events) == 0 {
+		return nil, false, nil
+	}
+	event := it.events[0]
+	it.events = it.events[1:]
+	return event, true, nil
+}
+
+func (it *sliceIterator) Close() error {
+	it.closed = true
+	return nil
+}
+
+func TestExecuteSubscriptionPlan_ReusesPlan(t *testing.T) {
+	iterator := &sliceIterator{events: []interface{}{"a", "b"}}
+	schema := makeSubscriptionSchema(t, graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"letters": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+				Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+					return iterator, nil
+				},
+			},
+		},
+	})
+	cache := graphql.NewPlanCache(graphql.PlanCacheOptions{})
+	pr := cache.Get(&schema, `subscription { letters }`, "")
+	if len(pr.Errors) > 0 {
+		t.Fatal(pr.Errors)
+	}
+
+	var data []interface{}
+	for result := range graphql.ExecuteSubscriptionPlan(pr.Plan, graphql.ExecuteParams{Schema: schema}) {
+		if len(result.Errors) > 0 {
+			t.Fatal(result.Errors)
+		}
+		data = append(data, result.Data)
+	}
+	expected := []interface{}{
+		map[string]interface{}{"letters": "a"},
+		map[string]interface{}{"letters": "b"},
+	}
+	if !reflect.DeepEqual(expected, data) {
+		t.Fatalf("Unexpected data, Diff: %v", testutil.Diff(expected, data))
+	}
+	if !iterator.closed {
+		t.Fatal("expected the iterator to be closed")
+	}
+}
+
+func TestExecuteSubscription_RejectsSeveralRootFields(t *testing.T) {
+	schema := makeSubscriptionSchema(t, graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"a": &graphql.Field{Type: graphql.String, Subscribe: makeSubscribeToStringFunction([]string{"a"})},
+			"b": &graphql.Field{Type: graphql.String, Subscribe: makeSubscribeToStringFunction([]string{"b"})},
+		},
+	})
+	// the document is executed without validation
+	doc := testutil.TestParse(t, `subscription { a b }`)
+	var results []*graphql.Result
+	for result := range graphql.ExecuteSubscription(graphql.ExecuteParams{Schema: schema, AST: doc}) {
+		results = append(results, result)
+	}
+	if len(results) != 1 || len(results[0].Errors) != 1 {
+		t.Fatalf("expected a single error result, got %v", results)
+	}
+	if msg := results[0].Errors[0].Message; msg != "a subscription must select exactly one top level field" {
+		t.Fatalf("unexpected error %q", msg)
+	}
+}
+
+func TestExecuteSubscription_StopsOnContextCancel(t *testing.T) {
+	source := make(chan string)
+	schema := makeSubscriptionSchema(t, graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"letters": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+				Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+					return source, nil
+				},
+			},
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	results := graphql.Subscribe(graphql.Params{
+		Schema:        schema,
+		RequestString: `subscription { letters }`,
+		Context:       ctx,
+	})
+	source <- "a"
+	if result := <-results; !reflect.DeepEqual(result.Data, map[string]interface{}{"letters": "a"}) {
+		t.Fatalf("unexpected result %v", result)
+	}
+	cancel()
+	if _, more := <-results; more {
+		t.Fatal("expected the result channel to be closed")
+	}
+}
+
+func TestExecuteSubscription_ReportsNonErrorPanics(t *testing.T) {
+	schema := makeSubscriptionSchema(t, graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"letters": &graphql.Field{
+				Type: graphql.String,
+				Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+					panic("no letters today")
+				},
+			},
+		},
+	})
+	var results []*graphql.Result
+	for result := range graphql.Subscribe(graphql.Params{
+		Schema:        schema,
+		RequestString: `subscription { letters }`,
+	}) {
+		results = append(results, result)
+	}
+	if len(results) != 1 || len(results[0].Errors) != 1 {
+		t.Fatalf("expected a single error result, got %v", results)
+	}
+	if msg := results[0].Errors[0].Message; msg != "no letters today" {
+		t.Fatalf("unexpected error %q", msg)
+	}
+}
+
+func TestExecuteSubscription_StopsWhenSubscriberGoesAway(t *testing.T) {
+	source := make(chan string)
+	finished := make(chan error, 1)
+	ext := newtestSubscriptionExt("testExt")
+	ext.subscriptionDidStartFn = func(ctx context.Context) (context.Context, graphql.SubscriptionEventFunc, graphql.SubscriptionFinishFunc) {
+		return ctx, func(*graphql.Result) {}, func(err error) {
+			finished <- err
+		}
+	}
+	schema := makeSubscriptionSchema(t, graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"letters": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+				Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+					return source, nil
+				},
+			},
+		},
+	})
+	schema.AddExtensions(ext)
+	ctx, cancel := context.WithCancel(context.Background())
+	graphql.Subscribe(graphql.Params{
+		Schema:        schema,
+		RequestString: `subscription { letters }`,
+		Context:       ctx,
+	})
+	// nobody reads the result of this event
+	source <- "a"
+	cancel()
+	select {
+	case err := <-finished:
+		if err != context.Canceled {
+			t.Fatalf("expected the subscription to finish with %v, got %v", context.Canceled, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the subscription to finish once its context is done")
+	}
+}
+
 func makeSubscribeToStringFunction(elements []string) func(p graphql.ResolveParams) (interface{}, error) {
 	return func(p graphql.ResolveParams) (interface{}, error) {
 		c := make(chan interface{})
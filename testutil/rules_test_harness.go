@@ -487,6 +487,10 @@ func init() {
 				Name:      "onInlineFragment",
 				Locations: []string{graphql.DirectiveLocationInlineFragment},
 			}),
+			graphql.NewDirective(graphql.DirectiveConfig{
+				Name:      "onVariableDefinition",
+				Locations: []string{graphql.DirectiveLocationVariableDefinition},
+			}),
 			graphql.NewDirective(graphql.DirectiveConfig{
 				Name:      "onSchema",
 				Locations: []string{graphql.DirectiveLocationSchema},
@@ -0,0 +1,241 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Tracer starts the spans of a request, for export to a tracing backend:
+// an adapter for OpenTelemetry, or any other, implements it in a few
+// lines. Set it with SchemaConfig.Tracer or Schema.SetTracer; schemas
+// without one don't trace.
+//
+// Spans nest through the context: StartSpan returns the context children
+// are started with. Do starts a SpanRequest; parsing, validation and
+// planning, whether by Do or by a PlanCache miss, each get a span, as do
+// the execution and every resolver.
+type Tracer interface {
+	StartSpan(ctx context.Context, name string, attributes ...SpanAttribute) (context.Context, Span)
+}
+
+// Span is a unit of work started by a Tracer.
+type Span interface {
+	SetAttributes(attributes ...SpanAttribute)
+
+	// RecordError records an error the work ended with.
+	RecordError(err error)
+
+	End()
+}
+
+// SpanAttribute is a key-value pair describing a span.
+type SpanAttribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Names of the spans started by the engine.
+const (
+	SpanRequest  = "graphql.request"
+	SpanParse    = "graphql.parse"
+	SpanValidate = "graphql.validate"
+	SpanPlan     = "graphql.plan"
+	SpanExecute  = "graphql.execute"
+	SpanResolve  = "graphql.resolve"
+)
+
+// Keys of the span attributes set by the engine.
+const (
+	// AttributeOperationName is the requested operation name, if any.
+	AttributeOperationName = "graphql.operation.name"
+	// AttributeOperationType is query, mutation or subscription.
+	AttributeOperationType = "graphql.operation.type"
+	// AttributeFieldPath is the response path of a resolved field, such
+	// as "user.friends.0.name".
+	AttributeFieldPath = "graphql.field.path"
+	// AttributeFieldName is the name of a resolved field.
+	AttributeFieldName = "graphql.field.name"
+	// AttributeParentType is the type a resolved field belongs to.
+	AttributeParentType = "graphql.field.parent_type"
+	// AttributeErrorCount is the number of errors in a result.
+	AttributeErrorCount = "graphql.error.count"
+	// AttributeCacheHit tells whether a PlanCache lookup hit.
+	AttributeCacheHit = "graphql.cache.hit"
+)
+
+// NoopTracer is a Tracer whose spans do nothing.
+var NoopTracer Tracer = noopTracer{}
+
+type noopTracer struct{}
+
+func (noopTracer) StartSpan(ctx context.Context, name string, attributes ...SpanAttribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...SpanAttribute) {}
+func (noopSpan) RecordError(error)              {}
+func (noopSpan) End()                           {}
+
+// startSpan starts a span with tracer, or a no-op span if tracer is nil.
+func startSpan(tracer Tracer, ctx context.Context, name string, attributes ...SpanAttribute) (context.Context, Span) {
+	if tracer == nil {
+		return ctx, noopSpan{}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return tracer.StartSpan(ctx, name, attributes...)
+}
+
+func schemaTracer(schema *Schema) Tracer {
+	if schema == nil {
+		return nil
+	}
+	return schema.tracer
+}
+
+// pathString formats path for AttributeFieldPath.
+func pathString(path *ResponsePath) string {
+	var b strings.Builder
+	for i, key := range path.AsArray() {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		switch key := key.(type) {
+		case string:
+			b.WriteString(key)
+		case int:
+			b.WriteString(strconv.Itoa(key))
+		default:
+			fmt.Fprint(&b, key)
+		}
+	}
+	return b.String()
+}
+
+// parseTraced parses the request query in a SpanParse.
+func parseTraced(ctx context.Context, schema *Schema, query string) (*ast.Document, error) {
+	src := source.NewSource(&source.Source{Body: []byte(query), Name: "GraphQL request"})
+	tracer := schemaTracer(schema)
+	if tracer == nil {
+		return parser.Parse(parser.ParseParams{Source: src})
+	}
+	_, span := startSpan(tracer, ctx, SpanParse)
+	doc, err := parser.Parse(parser.ParseParams{Source: src})
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+	return doc, err
+}
+
+// validateTraced validates doc in a SpanValidate.
+func validateTraced(ctx context.Context, schema *Schema, doc *ast.Document, rules []ValidationRuleFn) ValidationResult {
+	tracer := schemaTracer(schema)
+	if tracer == nil {
+		return ValidateDocument(schema, doc, rules)
+	}
+	_, span := startSpan(tracer, ctx, SpanValidate)
+	vr := ValidateDocument(schema, doc, rules)
+	span.SetAttributes(SpanAttribute{AttributeErrorCount, len(vr.Errors)})
+	for _, err := range vr.Errors {
+		span.RecordError(err)
+	}
+	span.End()
+	return vr
+}
+
+// SpanRecorder is a Tracer keeping its spans in memory, for tests.
+type SpanRecorder struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+// NewSpanRecorder returns an empty SpanRecorder.
+func NewSpanRecorder() *SpanRecorder {
+	return &SpanRecorder{}
+}
+
+// RecordedSpan is a span started by a SpanRecorder. Its fields must only
+// be read once it has ended.
+type RecordedSpan struct {
+	Name       string
+	Parent     *RecordedSpan
+	Attributes map[string]interface{}
+	Errors     []error
+	Start      time.Time
+	End        time.Time
+
+	recorder *SpanRecorder
+}
+
+type recordedSpanKey struct{}
+
+// StartSpan records a new span, child of the span in ctx if any.
+func (r *SpanRecorder) StartSpan(ctx context.Context, name string, attributes ...SpanAttribute) (context.Context, Span) {
+	parent, _ := ctx.Value(recordedSpanKey{}).(*RecordedSpan)
+	span := &RecordedSpan{
+		Name:       name,
+		Parent:     parent,
+		Attributes: make(map[string]interface{}, len(attributes)),
+		Start:      time.Now(),
+		recorder:   r,
+	}
+	for _, a := range attributes {
+		span.Attributes[a.Key] = a.Value
+	}
+	r.mu.Lock()
+	r.spans = append(r.spans, span)
+	r.mu.Unlock()
+	return context.WithValue(ctx, recordedSpanKey{}, span), recordingSpan{span}
+}
+
+// Spans returns the spans recorded so far, ended or not, in the order
+// they started.
+func (r *SpanRecorder) Spans() []*RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*RecordedSpan{}, r.spans...)
+}
+
+// Reset forgets the recorded spans.
+func (r *SpanRecorder) Reset() {
+	r.mu.Lock()
+	r.spans = nil
+	r.mu.Unlock()
+}
+
+// recordingSpan updates a RecordedSpan under the lock of its recorder.
+type recordingSpan struct {
+	span *RecordedSpan
+}
+
+func (s recordingSpan) SetAttributes(attributes ...SpanAttribute) {
+	s.span.recorder.mu.Lock()
+	defer s.span.recorder.mu.Unlock()
+	for _, a := range attributes {
+		s.span.Attributes[a.Key] = a.Value
+	}
+}
+
+func (s recordingSpan) RecordError(err error) {
+	s.span.recorder.mu.Lock()
+	defer s.span.recorder.mu.Unlock()
+	s.span.Errors = append(s.span.Errors, err)
+}
+
+func (s recordingSpan) End() {
+	s.span.recorder.mu.Lock()
+	defer s.span.recorder.mu.Unlock()
+	s.span.End = time.Now()
+}
@@ -0,0 +1,193 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+func tracedSchema(t *testing.T, tracer graphql.Tracer) *graphql.Schema {
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"hello": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return "world", nil
+					},
+				},
+				"fail": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return nil, errors.New("failed")
+					},
+				},
+				"panic": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						panic("boom")
+					},
+				},
+			},
+		}),
+		Tracer: tracer,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &schema
+}
+
+// spanSummary describes a span by its name and its parent's name.
+func spanSummary(spans []*graphql.RecordedSpan) [][2]string {
+	var out [][2]string
+	for _, span := range spans {
+		parent := ""
+		if span.Parent != nil {
+			parent = span.Parent.Name
+		}
+		out = append(out, [2]string{span.Name, parent})
+	}
+	return out
+}
+
+func expectEnded(t *testing.T, spans []*graphql.RecordedSpan) {
+	t.Helper()
+	for _, span := range spans {
+		if span.End.IsZero() || span.End.Before(span.Start) {
+			t.Fatalf("span %s was not ended", span.Name)
+		}
+	}
+}
+
+func TestTracer_Do(t *testing.T) {
+	recorder := graphql.NewSpanRecorder()
+	schema := tracedSchema(t, recorder)
+	result := graphql.Do(graphql.Params{
+		Schema:        *schema,
+		RequestString: `query Q { hello fail }`,
+		OperationName: "Q",
+	})
+	if len(result.Errors) != 1 {
+		t.Fatalf("expected one error, got %v", result.Errors)
+	}
+
+	spans := recorder.Spans()
+	expectEnded(t, spans)
+	expected := [][2]string{
+		{graphql.SpanRequest, ""},
+		{graphql.SpanParse, graphql.SpanRequest},
+		{graphql.SpanValidate, graphql.SpanRequest},
+		{graphql.SpanPlan, graphql.SpanRequest},
+		{graphql.SpanExecute, graphql.SpanRequest},
+		{graphql.SpanResolve, graphql.SpanExecute},
+		{graphql.SpanResolve, graphql.SpanExecute},
+	}
+	if got := spanSummary(spans); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected spans %v, got %v", expected, got)
+	}
+
+	request, execute, hello, fail := spans[0], spans[4], spans[5], spans[6]
+	if request.Attributes[graphql.AttributeOperationName] != "Q" || request.Attributes[graphql.AttributeErrorCount] != 1 {
+		t.Fatalf("unexpected request attributes: %v", request.Attributes)
+	}
+	if execute.Attributes[graphql.AttributeOperationType] != "query" || execute.Attributes[graphql.AttributeOperationName] != "Q" {
+		t.Fatalf("unexpected execute attributes: %v", execute.Attributes)
+	}
+	expectedAttributes := map[string]interface{}{
+		graphql.AttributeFieldPath:  "hello",
+		graphql.AttributeFieldName:  "hello",
+		graphql.AttributeParentType: "Query",
+	}
+	if !reflect.DeepEqual(hello.Attributes, expectedAttributes) || len(hello.Errors) != 0 {
+		t.Fatalf("unexpected resolver span: %+v", hello)
+	}
+	if len(fail.Errors) != 1 || fail.Errors[0].Error() != "failed" {
+		t.Fatalf("expected the resolver error to be recorded, got %v", fail.Errors)
+	}
+}
+
+func TestTracer_ValidationErrors(t *testing.T) {
+	recorder := graphql.NewSpanRecorder()
+	graphql.Do(graphql.Params{Schema: *tracedSchema(t, recorder), RequestString: `{ nope }`})
+	spans := recorder.Spans()
+	expectEnded(t, spans)
+	if got := spanSummary(spans); len(got) != 3 || got[2][0] != graphql.SpanValidate {
+		t.Fatalf("expected request, parse and validate spans, got %v", got)
+	}
+	if validate := spans[2]; len(validate.Errors) != 1 || validate.Attributes[graphql.AttributeErrorCount] != 1 {
+		t.Fatalf("expected the validation error to be recorded, got %+v", validate)
+	}
+}
+
+func TestTracer_ResolverPanic(t *testing.T) {
+	recorder := graphql.NewSpanRecorder()
+	result := graphql.Do(graphql.Params{Schema: *tracedSchema(t, recorder), RequestString: `{ panic }`})
+	if len(result.Errors) != 1 {
+		t.Fatalf("expected one error, got %v", result.Errors)
+	}
+	spans := recorder.Spans()
+	expectEnded(t, spans)
+	resolve := spans[len(spans)-1]
+	if resolve.Name != graphql.SpanResolve || len(resolve.Errors) != 1 || resolve.Errors[0].Error() != "boom" {
+		t.Fatalf("expected the panic to be recorded, got %+v", resolve)
+	}
+}
+
+func TestTracer_PlanCache(t *testing.T) {
+	recorder := graphql.NewSpanRecorder()
+	schema := tracedSchema(t, recorder)
+	cache := graphql.NewPlanCache(graphql.PlanCacheOptions{})
+
+	ctx, root := recorder.StartSpan(context.Background(), "http")
+	pr := cache.GetContext(ctx, schema, `{ hello }`, "")
+	graphql.ExecutePlan(pr.Plan, graphql.ExecuteParams{Schema: *schema, Context: ctx})
+	cache.GetContext(ctx, schema, `{ hello }`, "")
+	root.End()
+
+	spans := recorder.Spans()
+	expectEnded(t, spans)
+	expected := [][2]string{
+		{"http", ""},
+		{graphql.SpanPlan, "http"},
+		{graphql.SpanParse, graphql.SpanPlan},
+		{graphql.SpanValidate, graphql.SpanPlan},
+		{graphql.SpanExecute, "http"},
+		{graphql.SpanResolve, graphql.SpanExecute},
+		{graphql.SpanPlan, "http"},
+	}
+	if got := spanSummary(spans); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected spans %v, got %v", expected, got)
+	}
+	if miss, hit := spans[1], spans[6]; miss.Attributes[graphql.AttributeCacheHit] != false || hit.Attributes[graphql.AttributeCacheHit] != true {
+		t.Fatalf("unexpected cache attributes: %v, %v", miss.Attributes, hit.Attributes)
+	}
+	if spans[6].Attributes[graphql.AttributeOperationType] != "query" {
+		t.Fatalf("expected the operation type, got %v", spans[6].Attributes)
+	}
+}
+
+func TestTracer_Noop(t *testing.T) {
+	for _, tracer := range []graphql.Tracer{nil, graphql.NoopTracer} {
+		result := graphql.Do(graphql.Params{Schema: *tracedSchema(t, tracer), RequestString: `{ hello }`})
+		if expected := map[string]interface{}{"hello": "world"}; !reflect.DeepEqual(result.Data, expected) {
+			t.Fatalf("expected %v, got %+v", expected, result)
+		}
+	}
+
+	recorder := graphql.NewSpanRecorder()
+	schema := tracedSchema(t, nil)
+	schema.SetTracer(recorder)
+	graphql.Do(graphql.Params{Schema: *schema, RequestString: `{ hello }`})
+	if len(recorder.Spans()) == 0 {
+		t.Fatalf("expected SetTracer to enable tracing")
+	}
+	recorder.Reset()
+	if len(recorder.Spans()) != 0 {
+		t.Fatalf("expected Reset to forget the spans")
+	}
+}
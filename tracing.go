@@ -0,0 +1,253 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// TracingExtension is an Extension reporting the timings of each request
+// in the Apollo tracing format, under the "tracing" key of
+// Result.Extensions:
+//
+//	schema.AddExtensions(graphql.NewTracingExtension(graphql.TracingOptions{}))
+//
+// Parsing and validation are only timed by Do; requests executed from a
+// cached plan report them as zero. Resolver timings cover the resolve
+// function, not the completion of the value it returns.
+//
+// The timings are kept in the request's context, so one extension serves
+// any number of concurrent requests. To trace some requests only, pass it
+// in the Extensions of their Params or ExecuteParams rather than adding it
+// to the schema, or set TracingOptions.Enabled.
+type TracingExtension struct {
+	enabled func(context.Context) bool
+}
+
+// TracingOptions configures a TracingExtension.
+type TracingOptions struct {
+	// Enabled, when set, decides per request whether it is traced, e.g.
+	// to sample requests. Requests that aren't traced cost a context
+	// lookup per resolver and get no "tracing" entry.
+	Enabled func(context.Context) bool
+}
+
+// NewTracingExtension returns a TracingExtension.
+func NewTracingExtension(opts TracingOptions) *TracingExtension {
+	return &TracingExtension{enabled: opts.Enabled}
+}
+
+// TracingResult is the Apollo tracing format, version 1. Offsets and
+// durations are in nanoseconds; offsets are relative to StartTime.
+type TracingResult struct {
+	Version    int                    `json:"version"`
+	StartTime  time.Time              `json:"startTime"`
+	EndTime    time.Time              `json:"endTime"`
+	Duration   int64                  `json:"duration"`
+	Parsing    TracingPhase           `json:"parsing"`
+	Validation TracingPhase           `json:"validation"`
+	Execution  TracingExecutionResult `json:"execution"`
+}
+
+// TracingPhase is the timing of a request phase.
+type TracingPhase struct {
+	StartOffset int64 `json:"startOffset"`
+	Duration    int64 `json:"duration"`
+}
+
+// TracingExecutionResult holds the timings of the resolvers, in the order
+// they started.
+type TracingExecutionResult struct {
+	Resolvers []TracingResolver `json:"resolvers"`
+}
+
+// TracingResolver is the timing of a resolver.
+type TracingResolver struct {
+	Path        []interface{} `json:"path"`
+	ParentType  string        `json:"parentType"`
+	FieldName   string        `json:"fieldName"`
+	ReturnType  string        `json:"returnType"`
+	StartOffset int64         `json:"startOffset"`
+	Duration    int64         `json:"duration"`
+}
+
+type tracingKey struct{}
+
+// trace accumulates the timings of one request. Resolvers may finish
+// concurrently, hence mu.
+type trace struct {
+	mu        sync.Mutex
+	start     time.Time
+	end       time.Time
+	executing bool
+	result    TracingResult
+}
+
+func (t *trace) since(now time.Time) int64 {
+	return now.Sub(t.start).Nanoseconds()
+}
+
+// untraced marks the context of a request Enabled chose not to trace, so
+// that the choice is made once per request.
+var untraced = &trace{}
+
+func traceFrom(ctx context.Context) *trace {
+	if ctx == nil {
+		return nil
+	}
+	t, _ := ctx.Value(tracingKey{}).(*trace)
+	if t == untraced {
+		return nil
+	}
+	return t
+}
+
+func (ext *TracingExtension) newTrace(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if ext.enabled != nil && !ext.enabled(ctx) {
+		return context.WithValue(ctx, tracingKey{}, untraced)
+	}
+	return context.WithValue(ctx, tracingKey{}, &trace{start: time.Now()})
+}
+
+// Init starts the trace of a request run with Do.
+func (ext *TracingExtension) Init(ctx context.Context, p *Params) context.Context {
+	return ext.newTrace(ctx)
+}
+
+// Name returns "tracing".
+func (ext *TracingExtension) Name() string {
+	return "tracing"
+}
+
+// ParseDidStart times the parsing.
+func (ext *TracingExtension) ParseDidStart(ctx context.Context) (context.Context, ParseFinishFunc) {
+	t := traceFrom(ctx)
+	if t == nil {
+		return ctx, func(error) {}
+	}
+	start := time.Now()
+	return ctx, func(error) {
+		end := time.Now()
+		t.mu.Lock()
+		t.result.Parsing = TracingPhase{StartOffset: t.since(start), Duration: end.Sub(start).Nanoseconds()}
+		t.mu.Unlock()
+	}
+}
+
+// ValidationDidStart times the validation.
+func (ext *TracingExtension) ValidationDidStart(ctx context.Context) (context.Context, ValidationFinishFunc) {
+	t := traceFrom(ctx)
+	if t == nil {
+		return ctx, func([]gqlerrors.FormattedError) {}
+	}
+	start := time.Now()
+	return ctx, func([]gqlerrors.FormattedError) {
+		end := time.Now()
+		t.mu.Lock()
+		t.result.Validation = TracingPhase{StartOffset: t.since(start), Duration: end.Sub(start).Nanoseconds()}
+		t.mu.Unlock()
+	}
+}
+
+// ExecutionDidStart starts the trace of requests executed without Do, and
+// of each event of a subscription.
+func (ext *TracingExtension) ExecutionDidStart(ctx context.Context) (context.Context, ExecutionFinishFunc) {
+	var t *trace
+	if ctx != nil {
+		t, _ = ctx.Value(tracingKey{}).(*trace)
+	}
+	switch {
+	case t == untraced:
+		return ctx, func(*Result) {}
+	case t == nil:
+		// executed without Do
+		ctx = ext.newTrace(ctx)
+		if t = traceFrom(ctx); t == nil {
+			return ctx, func(*Result) {}
+		}
+		t.executing = true
+	default:
+		t.mu.Lock()
+		executed := t.executing
+		t.executing = true
+		t.mu.Unlock()
+		if executed {
+			// a later event of a traced subscription
+			t = &trace{start: time.Now(), executing: true}
+			ctx = context.WithValue(ctx, tracingKey{}, t)
+		}
+	}
+	return ctx, func(*Result) {
+		end := time.Now()
+		t.mu.Lock()
+		t.end = end
+		t.mu.Unlock()
+	}
+}
+
+// ResolveFieldDidStart times a resolver.
+func (ext *TracingExtension) ResolveFieldDidStart(ctx context.Context, info *ResolveInfo) (context.Context, ResolveFieldFinishFunc) {
+	t := traceFrom(ctx)
+	if t == nil {
+		return ctx, noopResolveFieldFinish
+	}
+	start := time.Now()
+	return ctx, func(interface{}, error) {
+		end := time.Now()
+		resolver := TracingResolver{
+			Path:        info.Path.AsArray(),
+			FieldName:   info.FieldName,
+			StartOffset: t.since(start),
+			Duration:    end.Sub(start).Nanoseconds(),
+		}
+		if info.ParentType != nil {
+			resolver.ParentType = info.ParentType.Name()
+		}
+		if info.ReturnType != nil {
+			resolver.ReturnType = info.ReturnType.String()
+		}
+		t.mu.Lock()
+		t.result.Execution.Resolvers = append(t.result.Execution.Resolvers, resolver)
+		t.mu.Unlock()
+	}
+}
+
+func noopResolveFieldFinish(interface{}, error) {}
+
+// HasResult returns true: traced requests get a "tracing" entry.
+func (ext *TracingExtension) HasResult() bool {
+	return true
+}
+
+// traced reports whether the request of ctx is traced; untraced requests
+// get no "tracing" entry.
+func (ext *TracingExtension) traced(ctx context.Context) bool {
+	return traceFrom(ctx) != nil
+}
+
+// GetResult returns the *TracingResult of the request, or nil if it isn't
+// traced.
+func (ext *TracingExtension) GetResult(ctx context.Context) interface{} {
+	t := traceFrom(ctx)
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	end := t.end
+	if end.IsZero() {
+		end = time.Now()
+	}
+	result := t.result
+	result.Version = 1
+	result.StartTime = t.start.UTC()
+	result.EndTime = end.UTC()
+	result.Duration = end.Sub(t.start).Nanoseconds()
+	result.Execution.Resolvers = append([]TracingResolver{}, t.result.Execution.Resolvers...)
+	return &result
+}
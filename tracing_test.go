@@ -0,0 +1,186 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+func tracingSchema(t *testing.T, opts graphql.TracingOptions) *graphql.Schema {
+	item := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Item",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{Type: graphql.String},
+		},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"items": &graphql.Field{
+					Type: graphql.NewList(item),
+					Args: graphql.FieldConfigArgument{
+						"n": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 2},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						var items []interface{}
+						for i := 0; i < p.Args["n"].(int); i++ {
+							items = append(items, map[string]interface{}{"name": "item"})
+						}
+						return items, nil
+					},
+				},
+			},
+		}),
+		Extensions: []graphql.Extension{graphql.NewTracingExtension(opts)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &schema
+}
+
+func tracingResult(t *testing.T, result *graphql.Result) *graphql.TracingResult {
+	t.Helper()
+	if len(result.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+	tr, ok := result.Extensions["tracing"].(*graphql.TracingResult)
+	if !ok {
+		t.Fatalf("expected a tracing result, got %#v", result.Extensions)
+	}
+	if tr.Version != 1 || tr.Duration <= 0 || !tr.EndTime.After(tr.StartTime) {
+		t.Fatalf("unexpected timings: %+v", tr)
+	}
+	return tr
+}
+
+func TestTracingExtension(t *testing.T) {
+	schema := tracingSchema(t, graphql.TracingOptions{})
+	result := graphql.Do(graphql.Params{Schema: *schema, RequestString: `{ items { name } }`})
+	tr := tracingResult(t, result)
+	if tr.Parsing.Duration <= 0 || tr.Validation.Duration <= 0 {
+		t.Fatalf("expected parsing and validation to be timed, got %+v %+v", tr.Parsing, tr.Validation)
+	}
+	if tr.Validation.StartOffset < tr.Parsing.StartOffset+tr.Parsing.Duration {
+		t.Fatalf("expected validation to start after parsing, got %+v %+v", tr.Parsing, tr.Validation)
+	}
+
+	type resolver struct {
+		path                              []interface{}
+		parentType, fieldName, returnType string
+	}
+	var got []resolver
+	for _, r := range tr.Execution.Resolvers {
+		if r.Duration < 0 || r.StartOffset < tr.Validation.StartOffset || r.StartOffset > tr.Duration {
+			t.Fatalf("unexpected resolver timing: %+v", r)
+		}
+		got = append(got, resolver{r.Path, r.ParentType, r.FieldName, r.ReturnType})
+	}
+	expected := []resolver{
+		{[]interface{}{"items"}, "Query", "items", "[Item]"},
+		{[]interface{}{"items", 0, "name"}, "Item", "name", "String"},
+		{[]interface{}{"items", 1, "name"}, "Item", "name", "String"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+
+	// the JSON is in the Apollo tracing format
+	b, err := json.Marshal(result.Extensions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded struct {
+		Tracing map[string]interface{} `json:"tracing"`
+	}
+	json.Unmarshal(b, &decoded)
+	for _, key := range []string{"version", "startTime", "endTime", "duration", "parsing", "validation", "execution"} {
+		if _, ok := decoded.Tracing[key]; !ok {
+			t.Fatalf("expected %q in %s", key, b)
+		}
+	}
+	first := decoded.Tracing["execution"].(map[string]interface{})["resolvers"].([]interface{})[0].(map[string]interface{})
+	for _, key := range []string{"path", "parentType", "fieldName", "returnType", "startOffset", "duration"} {
+		if _, ok := first[key]; !ok {
+			t.Fatalf("expected %q in %v", key, first)
+		}
+	}
+}
+
+func TestTracingExtension_ExecutePlan(t *testing.T) {
+	schema := tracingSchema(t, graphql.TracingOptions{})
+	pr := graphql.NewPlanCache(graphql.PlanCacheOptions{}).Get(schema, `{ items(n: 1) { name } }`, "")
+	if len(pr.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", pr.Errors)
+	}
+	tr := tracingResult(t, graphql.ExecutePlan(pr.Plan, graphql.ExecuteParams{Schema: *schema}))
+	if tr.Parsing != (graphql.TracingPhase{}) || tr.Validation != (graphql.TracingPhase{}) {
+		t.Fatalf("expected no parsing and validation timings, got %+v %+v", tr.Parsing, tr.Validation)
+	}
+	if len(tr.Execution.Resolvers) != 2 {
+		t.Fatalf("expected 2 resolvers, got %+v", tr.Execution.Resolvers)
+	}
+}
+
+type sampledKey struct{}
+
+func TestTracingExtension_Enabled(t *testing.T) {
+	var calls int
+	var mu sync.Mutex
+	schema := tracingSchema(t, graphql.TracingOptions{
+		Enabled: func(ctx context.Context) bool {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+			return ctx.Value(sampledKey{}) != nil
+		},
+	})
+	result := graphql.Do(graphql.Params{Schema: *schema, RequestString: `{ items { name } }`, Context: context.Background()})
+	if len(result.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+	if _, ok := result.Extensions["tracing"]; ok {
+		t.Fatalf("expected no tracing entry, got %v", result.Extensions)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the sampling decision to be made once, got %d calls", calls)
+	}
+
+	ctx := context.WithValue(context.Background(), sampledKey{}, true)
+	tracingResult(t, graphql.Do(graphql.Params{Schema: *schema, RequestString: `{ items { name } }`, Context: ctx}))
+}
+
+func TestTracingExtension_ConcurrentRequests(t *testing.T) {
+	schema := tracingSchema(t, graphql.TracingOptions{})
+	cache := graphql.NewPlanCache(graphql.PlanCacheOptions{})
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			query := `query ($n: Int) { items(n: $n) { name } }`
+			args := map[string]interface{}{"n": n}
+			var result *graphql.Result
+			if n%2 == 0 {
+				result = graphql.Do(graphql.Params{Schema: *schema, RequestString: query, VariableValues: args})
+			} else {
+				pr := cache.Get(schema, query, "")
+				result = graphql.ExecutePlan(pr.Plan, graphql.ExecuteParams{Schema: *schema, Args: args})
+			}
+			tr, ok := result.Extensions["tracing"].(*graphql.TracingResult)
+			if !ok {
+				t.Errorf("expected a tracing result, got %#v", result.Extensions)
+				return
+			}
+			if len(tr.Execution.Resolvers) != n+1 {
+				t.Errorf("request %d: expected %d resolvers, got %d", n, n+1, len(tr.Execution.Resolvers))
+			}
+		}(i)
+	}
+	wg.Wait()
+}
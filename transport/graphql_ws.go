@@ -0,0 +1,327 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// SubprotocolGraphQLTransportWS is the WebSocket subprotocol served by
+// WebSocketHandler.
+const SubprotocolGraphQLTransportWS = "graphql-transport-ws"
+
+// Message types of the graphql-transport-ws protocol.
+const (
+	MessageConnectionInit = "connection_init"
+	MessageConnectionAck  = "connection_ack"
+	MessagePing           = "ping"
+	MessagePong           = "pong"
+	MessageSubscribe      = "subscribe"
+	MessageNext           = "next"
+	MessageError          = "error"
+	MessageComplete       = "complete"
+)
+
+// Close codes of the graphql-transport-ws protocol.
+const (
+	CloseInvalidMessage      = 4400
+	CloseUnauthorized        = 4401
+	CloseForbidden           = 4403
+	CloseInitTimeout         = 4408
+	CloseSubscriberExists    = 4409
+	CloseTooManyInitRequests = 4429
+)
+
+// ErrCodeTooManyOperations is reported in `extensions.code` when a
+// connection exceeds WebSocketOptions.MaxOperations.
+const ErrCodeTooManyOperations = "TOO_MANY_OPERATIONS"
+
+const (
+	defaultConnectionInitTimeout = 3 * time.Second
+	defaultKeepAlive             = 12 * time.Second
+	defaultMaxMessageSize        = 1 << 20
+)
+
+// WebSocketOptions tunes a WebSocketHandler. Zero values get sensible
+// defaults.
+type WebSocketOptions struct {
+	ExecutionOptions
+
+	// OnConnect is called with the payload of the client's
+	// connection_init message, typically to authenticate it. The returned
+	// context, which must derive from ctx, is used for every operation of
+	// the connection. An error closes the connection with CloseForbidden.
+	OnConnect func(ctx context.Context, payload map[string]interface{}) (context.Context, error)
+
+	// ConnectionInitTimeout bounds the wait for connection_init; the
+	// default is 3s.
+	ConnectionInitTimeout time.Duration
+
+	// KeepAlive is the interval of the server's ping messages; the default
+	// is 12s and a negative value disables them.
+	KeepAlive time.Duration
+
+	// MaxOperations caps the operations running at once on a connection;
+	// 0 means no limit. Operations past the cap get an error message.
+	MaxOperations int
+
+	// MaxMessageSize caps incoming messages, in bytes; the default is 1MiB.
+	MaxMessageSize int64
+
+	// CheckOrigin decides whether to accept the handshake. The default
+	// accepts requests without an Origin header and same-origin requests.
+	CheckOrigin func(r *http.Request) bool
+}
+
+// WebSocketHandler is an http.Handler serving GraphQL over the
+// graphql-transport-ws WebSocket subprotocol. Every operation of a
+// connection runs concurrently; subscriptions stream a next message per
+// event.
+type WebSocketHandler struct {
+	schema *graphql.Schema
+	opts   WebSocketOptions
+}
+
+// NewWebSocketHandler returns a handler executing operations against schema.
+func NewWebSocketHandler(schema *graphql.Schema, opts WebSocketOptions) *WebSocketHandler {
+	if opts.ConnectionInitTimeout <= 0 {
+		opts.ConnectionInitTimeout = defaultConnectionInitTimeout
+	}
+	if opts.KeepAlive == 0 {
+		opts.KeepAlive = defaultKeepAlive
+	}
+	if opts.MaxMessageSize <= 0 {
+		opts.MaxMessageSize = defaultMaxMessageSize
+	}
+	return &WebSocketHandler{schema: schema, opts: opts}
+}
+
+// ServeHTTP upgrades the request and serves the connection until it is
+// closed by either side or the request context is done.
+func (h *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgradeWebSocket(w, r, SubprotocolGraphQLTransportWS, h.opts.CheckOrigin)
+	if err != nil {
+		return
+	}
+	ws.maxRead = h.opts.MaxMessageSize
+	c := &wsConnection{
+		handler: h,
+		ws:      ws,
+		ops:     map[string]context.CancelFunc{},
+	}
+	c.ctx, c.cancel = context.WithCancel(r.Context())
+	c.serve()
+}
+
+// wsMessage is the envelope of every protocol message.
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// wsConnection is the state of one client connection.
+type wsConnection struct {
+	handler *WebSocketHandler
+	ws      *wsConn
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	// initialized is set on connection_init; opCtx once it is acked.
+	initialized atomic.Bool
+	opCtx       context.Context
+
+	mu  sync.Mutex
+	ops map[string]context.CancelFunc
+	wg  sync.WaitGroup
+}
+
+func (c *wsConnection) serve() {
+	defer func() {
+		c.cancel()
+		c.wg.Wait()
+		c.ws.close(closeNormal, "")
+	}()
+	initTimer := time.AfterFunc(c.handler.opts.ConnectionInitTimeout, func() {
+		if !c.initialized.Load() {
+			c.ws.close(CloseInitTimeout, "Connection initialisation timeout")
+		}
+	})
+	defer initTimer.Stop()
+	if c.handler.opts.KeepAlive > 0 {
+		c.wg.Add(1)
+		go c.keepAlive(c.handler.opts.KeepAlive)
+	}
+
+	for {
+		_, data, err := c.ws.readMessage()
+		if err != nil {
+			return
+		}
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil || msg.Type == "" {
+			c.ws.close(CloseInvalidMessage, "Invalid message received")
+			return
+		}
+		if !c.handle(msg) {
+			return
+		}
+	}
+}
+
+// handle processes one client message; it returns false once the
+// connection has been closed.
+func (c *wsConnection) handle(msg wsMessage) bool {
+	switch msg.Type {
+	case MessageConnectionInit:
+		if c.initialized.Swap(true) {
+			c.ws.close(CloseTooManyInitRequests, "Too many initialisation requests")
+			return false
+		}
+		var payload map[string]interface{}
+		if len(msg.Payload) > 0 {
+			if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+				c.ws.close(CloseInvalidMessage, "Invalid connection_init payload")
+				return false
+			}
+		}
+		ctx := c.ctx
+		if c.handler.opts.OnConnect != nil {
+			var err error
+			ctx, err = c.handler.opts.OnConnect(c.ctx, payload)
+			if err != nil {
+				c.ws.close(CloseForbidden, "Forbidden")
+				return false
+			}
+			if ctx == nil {
+				ctx = c.ctx
+			}
+		}
+		c.opCtx = ctx
+		c.send(wsMessage{Type: MessageConnectionAck})
+
+	case MessagePing:
+		c.send(wsMessage{Type: MessagePong})
+
+	case MessagePong:
+
+	case MessageSubscribe:
+		if c.opCtx == nil {
+			c.ws.close(CloseUnauthorized, "Unauthorized")
+			return false
+		}
+		var req graphql.Request
+		if msg.ID == "" || json.Unmarshal(msg.Payload, &req) != nil {
+			c.ws.close(CloseInvalidMessage, "Invalid subscribe message")
+			return false
+		}
+		return c.subscribe(msg.ID, req)
+
+	case MessageComplete:
+		c.release(msg.ID)
+
+	default:
+		c.ws.close(CloseInvalidMessage, fmt.Sprintf("Unexpected message of type %s received", msg.Type))
+		return false
+	}
+	return true
+}
+
+func (c *wsConnection) subscribe(id string, req graphql.Request) bool {
+	c.mu.Lock()
+	if _, exists := c.ops[id]; exists {
+		c.mu.Unlock()
+		c.ws.close(CloseSubscriberExists, fmt.Sprintf("Subscriber for %s already exists", id))
+		return false
+	}
+	if max := c.handler.opts.MaxOperations; max > 0 && len(c.ops) >= max {
+		c.mu.Unlock()
+		c.sendErrors(id, gqlerrors.FormatErrors(gqlerrors.NewCodedError(ErrCodeTooManyOperations,
+			fmt.Sprintf("Too many operations: at most %d may run at once.", max))))
+		return true
+	}
+	ctx, cancel := context.WithCancel(c.opCtx)
+	c.ops[id] = cancel
+	c.wg.Add(1)
+	c.mu.Unlock()
+
+	go c.run(ctx, id, req)
+	return true
+}
+
+func (c *wsConnection) run(ctx context.Context, id string, req graphql.Request) {
+	defer c.wg.Done()
+	opts := &c.handler.opts.ExecutionOptions
+	op, errs := opts.prepare(ctx, c.handler.schema, req)
+	if errs != nil {
+		if c.release(id) {
+			c.sendErrors(id, errs)
+		}
+		return
+	}
+	for result := range op.execute() {
+		if ctx.Err() != nil {
+			// completed by the client or the connection is going away;
+			// keep draining so the executor can finish
+			continue
+		}
+		payload, err := json.Marshal(result)
+		if err != nil {
+			payload, _ = json.Marshal(&graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		}
+		c.send(wsMessage{ID: id, Type: MessageNext, Payload: payload})
+	}
+	if c.release(id) {
+		c.send(wsMessage{ID: id, Type: MessageComplete})
+	}
+}
+
+// release forgets operation id and cancels it. It reports whether the
+// operation was still running, i.e. whether the server should send the
+// terminating message.
+func (c *wsConnection) release(id string) bool {
+	c.mu.Lock()
+	cancel, ok := c.ops[id]
+	delete(c.ops, id)
+	c.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+func (c *wsConnection) keepAlive(interval time.Duration) {
+	defer c.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.send(wsMessage{Type: MessagePing}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *wsConnection) sendErrors(id string, errs []gqlerrors.FormattedError) {
+	payload, _ := json.Marshal(errs)
+	c.send(wsMessage{ID: id, Type: MessageError, Payload: payload})
+}
+
+func (c *wsConnection) send(msg wsMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return c.ws.writeMessage(data)
+}
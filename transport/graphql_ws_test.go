@@ -0,0 +1,459 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// wsTestSchema has a query field, a mutation and a `counter(to)`
+// subscription counting from 1 to `to`, or forever when to is 0.
+func wsTestSchema(t *testing.T) *graphql.Schema {
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"hello": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						if user, ok := p.Context.Value(userKey{}).(string); ok {
+							return "hello " + user, nil
+						}
+						return "hello", nil
+					},
+				},
+			},
+		}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Mutation",
+			Fields: graphql.Fields{
+				"touch": &graphql.Field{
+					Type: graphql.Boolean,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return true, nil
+					},
+				},
+			},
+		}),
+		Subscription: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Subscription",
+			Fields: graphql.Fields{
+				"counter": &graphql.Field{
+					Type: graphql.Int,
+					Args: graphql.FieldConfigArgument{
+						"to": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+					},
+					Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+						to := p.Args["to"].(int)
+						ch := make(chan int)
+						go func() {
+							defer close(ch)
+							for i := 1; to == 0 || i <= to; i++ {
+								select {
+								case ch <- i:
+								case <-p.Context.Done():
+									return
+								}
+							}
+						}()
+						return ch, nil
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source, nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &schema
+}
+
+type userKey struct{}
+
+// dialWS opens a graphql-transport-ws client connection to srv.
+func dialWS(t *testing.T, srv *httptest.Server) *wsConn {
+	t.Helper()
+	conn, resp, err := handshakeWS(srv, SubprotocolGraphQLTransportWS)
+	if err != nil {
+		t.Fatalf("handshake failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Protocol"); got != SubprotocolGraphQLTransportWS {
+		t.Fatalf("expected subprotocol %q, got %q", SubprotocolGraphQLTransportWS, got)
+	}
+	t.Cleanup(func() { conn.close(closeNormal, "") })
+	return conn
+}
+
+func handshakeWS(srv *httptest.Server, subprotocol string) (*wsConn, *http.Response, error) {
+	addr := strings.TrimPrefix(srv.URL, "http://")
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	fmt.Fprintf(conn, "GET / HTTP/1.1\r\nHost: %s\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: %s\r\nSec-WebSocket-Version: 13\r\nSec-WebSocket-Protocol: %s\r\n\r\n", addr, key, subprotocol)
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	if resp.StatusCode == http.StatusSwitchingProtocols && resp.Header.Get("Sec-WebSocket-Accept") != websocketAccept(key) {
+		conn.Close()
+		return nil, nil, errors.New("bad Sec-WebSocket-Accept")
+	}
+	return &wsConn{conn: conn, br: br, client: true}, resp, nil
+}
+
+func sendWS(t *testing.T, c *wsConn, msg string) {
+	t.Helper()
+	if err := c.writeMessage([]byte(msg)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+}
+
+// readWS returns the next protocol message, skipping server pings.
+func readWS(t *testing.T, c *wsConn) wsMessage {
+	t.Helper()
+	for {
+		c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, data, err := c.readMessage()
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("bad message %s: %v", data, err)
+		}
+		if msg.Type != MessagePing {
+			return msg
+		}
+	}
+}
+
+// expectClose reads until the server closes the connection with code.
+func expectClose(t *testing.T, c *wsConn, code int) {
+	t.Helper()
+	for {
+		c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, data, err := c.readMessage()
+		if err == nil {
+			continue
+		}
+		var ce *closeError
+		if !errors.As(err, &ce) {
+			t.Fatalf("expected close %d, got error %v (last message %s)", code, err, data)
+		}
+		if ce.code != code {
+			t.Fatalf("expected close %d, got %d %q", code, ce.code, ce.reason)
+		}
+		return
+	}
+}
+
+func initWS(t *testing.T, c *wsConn) {
+	t.Helper()
+	sendWS(t, c, `{"type":"connection_init"}`)
+	if msg := readWS(t, c); msg.Type != MessageConnectionAck {
+		t.Fatalf("expected connection_ack, got %+v", msg)
+	}
+}
+
+func newWSServer(t *testing.T, opts WebSocketOptions) *httptest.Server {
+	srv := httptest.NewServer(NewWebSocketHandler(wsTestSchema(t), opts))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWebSocket_StreamsSubscription(t *testing.T) {
+	c := dialWS(t, newWSServer(t, WebSocketOptions{}))
+	initWS(t, c)
+	sendWS(t, c, `{"id":"1","type":"subscribe","payload":{"query":"subscription ($to: Int) { counter(to: $to) }","variables":{"to":3}}}`)
+	for i := 1; i <= 3; i++ {
+		msg := readWS(t, c)
+		if msg.Type != MessageNext || msg.ID != "1" {
+			t.Fatalf("expected next for 1, got %+v", msg)
+		}
+		if expected := fmt.Sprintf(`{"data":{"counter":%d}}`, i); string(msg.Payload) != expected {
+			t.Fatalf("expected %s, got %s", expected, msg.Payload)
+		}
+	}
+	if msg := readWS(t, c); msg.Type != MessageComplete || msg.ID != "1" {
+		t.Fatalf("expected complete for 1, got %+v", msg)
+	}
+}
+
+func TestWebSocket_ExecutesQueries(t *testing.T) {
+	c := dialWS(t, newWSServer(t, WebSocketOptions{
+		OnConnect: func(ctx context.Context, payload map[string]interface{}) (context.Context, error) {
+			return context.WithValue(ctx, userKey{}, payload["user"]), nil
+		},
+	}))
+	sendWS(t, c, `{"type":"connection_init","payload":{"user":"gopher"}}`)
+	if msg := readWS(t, c); msg.Type != MessageConnectionAck {
+		t.Fatalf("expected connection_ack, got %+v", msg)
+	}
+	sendWS(t, c, `{"id":"q","type":"subscribe","payload":{"query":"{ hello }"}}`)
+	msg := readWS(t, c)
+	if msg.Type != MessageNext || string(msg.Payload) != `{"data":{"hello":"hello gopher"}}` {
+		t.Fatalf("unexpected message %+v (%s)", msg, msg.Payload)
+	}
+	if msg := readWS(t, c); msg.Type != MessageComplete || msg.ID != "q" {
+		t.Fatalf("expected complete, got %+v", msg)
+	}
+}
+
+func TestWebSocket_ReportsRequestErrors(t *testing.T) {
+	c := dialWS(t, newWSServer(t, WebSocketOptions{}))
+	initWS(t, c)
+	sendWS(t, c, `{"id":"1","type":"subscribe","payload":{"query":"{ nope }"}}`)
+	msg := readWS(t, c)
+	if msg.Type != MessageError || msg.ID != "1" {
+		t.Fatalf("expected error for 1, got %+v", msg)
+	}
+	var errs []map[string]interface{}
+	if err := json.Unmarshal(msg.Payload, &errs); err != nil || len(errs) != 1 {
+		t.Fatalf("expected one error, got %s", msg.Payload)
+	}
+	if expected := `Cannot query field "nope" on type "Query".`; errs[0]["message"] != expected {
+		t.Fatalf("expected %q, got %v", expected, errs[0]["message"])
+	}
+
+	// the connection remains usable
+	sendWS(t, c, `{"id":"1","type":"subscribe","payload":{"query":"{ hello }"}}`)
+	if msg := readWS(t, c); msg.Type != MessageNext {
+		t.Fatalf("expected next, got %+v", msg)
+	}
+}
+
+func TestWebSocket_PersistedQueries(t *testing.T) {
+	c := dialWS(t, newWSServer(t, WebSocketOptions{
+		ExecutionOptions: ExecutionOptions{PersistedQueries: graphql.NewPersistedQueries(nil, nil)},
+	}))
+	initWS(t, c)
+	extensions := `{"persistedQuery":{"version":1,"sha256Hash":"` + graphql.HashQuery("{ hello }") + `"}}`
+
+	sendWS(t, c, `{"id":"1","type":"subscribe","payload":{"extensions":`+extensions+`}}`)
+	msg := readWS(t, c)
+	if msg.Type != MessageError || !strings.Contains(string(msg.Payload), graphql.ErrCodePersistedQueryNotFound) {
+		t.Fatalf("expected a %s error, got %+v (%s)", graphql.ErrCodePersistedQueryNotFound, msg, msg.Payload)
+	}
+
+	sendWS(t, c, `{"id":"2","type":"subscribe","payload":{"query":"{ hello }","extensions":`+extensions+`}}`)
+	if msg := readWS(t, c); msg.Type != MessageNext || string(msg.Payload) != `{"data":{"hello":"hello"}}` {
+		t.Fatalf("expected the query to be registered and run, got %+v (%s)", msg, msg.Payload)
+	}
+	readWS(t, c)
+
+	sendWS(t, c, `{"id":"3","type":"subscribe","payload":{"extensions":`+extensions+`}}`)
+	if msg := readWS(t, c); msg.Type != MessageNext || string(msg.Payload) != `{"data":{"hello":"hello"}}` {
+		t.Fatalf("expected the registered query to run from its hash, got %+v (%s)", msg, msg.Payload)
+	}
+}
+
+func TestWebSocket_TrustedDocuments(t *testing.T) {
+	schema := wsTestSchema(t)
+	td, err := graphql.NewTrustedDocuments(schema, map[string]string{"hello": "{ hello }"}, graphql.TrustedDocumentsOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	srv := httptest.NewServer(NewWebSocketHandler(schema, WebSocketOptions{
+		ExecutionOptions: ExecutionOptions{TrustedDocuments: td},
+	}))
+	t.Cleanup(srv.Close)
+	c := dialWS(t, srv)
+	initWS(t, c)
+
+	sendWS(t, c, `{"id":"1","type":"subscribe","payload":{"documentId":"hello"}}`)
+	if msg := readWS(t, c); msg.Type != MessageNext || string(msg.Payload) != `{"data":{"hello":"hello"}}` {
+		t.Fatalf("expected the document to run, got %+v (%s)", msg, msg.Payload)
+	}
+	readWS(t, c)
+
+	sendWS(t, c, `{"id":"2","type":"subscribe","payload":{"query":"{ __typename }"}}`)
+	msg := readWS(t, c)
+	if msg.Type != MessageError || !strings.Contains(string(msg.Payload), graphql.ErrCodeTrustedDocumentRequired) {
+		t.Fatalf("expected a %s error, got %+v (%s)", graphql.ErrCodeTrustedDocumentRequired, msg, msg.Payload)
+	}
+}
+
+func TestWebSocket_ClientComplete(t *testing.T) {
+	c := dialWS(t, newWSServer(t, WebSocketOptions{}))
+	initWS(t, c)
+	sendWS(t, c, `{"id":"1","type":"subscribe","payload":{"query":"subscription { counter }"}}`)
+	if msg := readWS(t, c); msg.Type != MessageNext {
+		t.Fatalf("expected next, got %+v", msg)
+	}
+	sendWS(t, c, `{"id":"1","type":"complete"}`)
+
+	// the id can be reused once the server has dropped the operation
+	sendWS(t, c, `{"id":"2","type":"subscribe","payload":{"query":"{ hello }"}}`)
+	for {
+		msg := readWS(t, c)
+		if msg.ID == "1" {
+			if msg.Type == MessageComplete {
+				t.Fatal("the server must not complete an operation the client completed")
+			}
+			continue // events in flight before the complete arrived
+		}
+		if msg.ID == "2" && msg.Type == MessageComplete {
+			break
+		}
+	}
+}
+
+func TestWebSocket_PingPong(t *testing.T) {
+	c := dialWS(t, newWSServer(t, WebSocketOptions{}))
+	sendWS(t, c, `{"type":"ping"}`)
+	if msg := readWS(t, c); msg.Type != MessagePong {
+		t.Fatalf("expected pong, got %+v", msg)
+	}
+}
+
+func TestWebSocket_KeepAlive(t *testing.T) {
+	c := dialWS(t, newWSServer(t, WebSocketOptions{KeepAlive: 10 * time.Millisecond}))
+	initWS(t, c)
+	c.conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, data, err := c.readMessage()
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if string(data) != `{"type":"ping"}` {
+		t.Fatalf("expected a ping, got %s", data)
+	}
+}
+
+func TestWebSocket_MaxOperations(t *testing.T) {
+	c := dialWS(t, newWSServer(t, WebSocketOptions{MaxOperations: 1}))
+	initWS(t, c)
+	sendWS(t, c, `{"id":"1","type":"subscribe","payload":{"query":"subscription { counter }"}}`)
+	sendWS(t, c, `{"id":"2","type":"subscribe","payload":{"query":"subscription { counter }"}}`)
+	for {
+		msg := readWS(t, c)
+		if msg.ID != "2" {
+			continue
+		}
+		if msg.Type != MessageError || !strings.Contains(string(msg.Payload), ErrCodeTooManyOperations) {
+			t.Fatalf("expected a %s error, got %+v (%s)", ErrCodeTooManyOperations, msg, msg.Payload)
+		}
+		return
+	}
+}
+
+func TestWebSocket_CloseCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     WebSocketOptions
+		messages []string
+		code     int
+	}{
+		{
+			name:     "subscribe before init",
+			messages: []string{`{"id":"1","type":"subscribe","payload":{"query":"{ hello }"}}`},
+			code:     CloseUnauthorized,
+		},
+		{
+			name: "rejected by OnConnect",
+			opts: WebSocketOptions{OnConnect: func(ctx context.Context, payload map[string]interface{}) (context.Context, error) {
+				return nil, errors.New("bad token")
+			}},
+			messages: []string{`{"type":"connection_init"}`},
+			code:     CloseForbidden,
+		},
+		{
+			name:     "second init",
+			messages: []string{`{"type":"connection_init"}`, `{"type":"connection_init"}`},
+			code:     CloseTooManyInitRequests,
+		},
+		{
+			name: "duplicate id",
+			messages: []string{
+				`{"type":"connection_init"}`,
+				`{"id":"1","type":"subscribe","payload":{"query":"subscription { counter }"}}`,
+				`{"id":"1","type":"subscribe","payload":{"query":"subscription { counter }"}}`,
+			},
+			code: CloseSubscriberExists,
+		},
+		{
+			name:     "invalid message",
+			messages: []string{`{"type":"connection_init"}`, `not json`},
+			code:     CloseInvalidMessage,
+		},
+		{
+			name:     "unknown message type",
+			messages: []string{`{"type":"start"}`},
+			code:     CloseInvalidMessage,
+		},
+		{
+			name:     "invalid UTF-8",
+			messages: []string{"{\"type\":\"connection_init\",\"payload\":{\"a\":\"\xff\"}}"},
+			code:     closeInvalidData,
+		},
+		{
+			name: "init timeout",
+			opts: WebSocketOptions{ConnectionInitTimeout: 10 * time.Millisecond},
+			code: CloseInitTimeout,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := dialWS(t, newWSServer(t, test.opts))
+			for _, msg := range test.messages {
+				sendWS(t, c, msg)
+			}
+			expectClose(t, c, test.code)
+		})
+	}
+}
+
+func TestWebSocket_RequiresSubprotocol(t *testing.T) {
+	srv := newWSServer(t, WebSocketOptions{})
+	_, resp, err := handshakeWS(srv, "graphql-ws")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestWebSocket_FragmentedMessages(t *testing.T) {
+	c := dialWS(t, newWSServer(t, WebSocketOptions{}))
+	msg := []byte(`{"type":"connection_init","payload":{"a":"é"}}`)
+	split := bytes.IndexRune(msg, 'é') + 1
+	// send the message in two frames, splitting a character: text without
+	// FIN, then continuation
+	frames := []struct {
+		first byte
+		data  []byte
+	}{{opText, msg[:split]}, {0x80 | opContinuation, msg[split:]}}
+	for _, f := range frames {
+		frame := append([]byte{f.first, 0x80 | byte(len(f.data))}, 0, 0, 0, 0)
+		frame = append(frame, f.data...)
+		if _, err := c.conn.Write(frame); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	if got := readWS(t, c); !reflect.DeepEqual(got, wsMessage{Type: MessageConnectionAck}) {
+		t.Fatalf("expected connection_ack, got %+v", got)
+	}
+}
@@ -0,0 +1,195 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Event names of the GraphQL over SSE protocol.
+const (
+	EventNext     = "next"
+	EventComplete = "complete"
+)
+
+const defaultMaxBodySize = 1 << 20
+
+// SSEOptions tunes an SSEHandler. Zero values get sensible defaults.
+type SSEOptions struct {
+	ExecutionOptions
+
+	// KeepAlive is the interval of the comment lines sent to keep idle
+	// streams open through proxies; the default is 12s and a negative
+	// value disables them.
+	KeepAlive time.Duration
+
+	// MaxBodySize caps POST bodies, in bytes; the default is 1MiB.
+	MaxBodySize int64
+}
+
+// SSEHandler is an http.Handler serving GraphQL over Server-Sent Events in
+// the "distinct connections" mode of the GraphQL over SSE protocol: every
+// request is one operation, streamed back as a `next` event per result and
+// a final `complete` event.
+//
+// Requests are POSTed as application/json or sent as GET with `query`,
+// `documentId`, `operationName`, `variables` and `extensions` URL
+// parameters; mutations are only accepted over POST. Since browsers
+// preflight cross-origin JSON POSTs and GETs can't mutate, no other CSRF
+// protection is needed. Requests that can't be executed at all are
+// answered with a JSON error response instead of a stream. The operation
+// is torn down as soon as the client disconnects.
+type SSEHandler struct {
+	schema *graphql.Schema
+	opts   SSEOptions
+}
+
+// NewSSEHandler returns a handler executing operations against schema.
+func NewSSEHandler(schema *graphql.Schema, opts SSEOptions) *SSEHandler {
+	if opts.KeepAlive == 0 {
+		opts.KeepAlive = defaultKeepAlive
+	}
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = defaultMaxBodySize
+	}
+	return &SSEHandler{schema: schema, opts: opts}
+}
+
+// ServeHTTP streams the operation of the request.
+func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	req, status, err := h.readRequest(w, r)
+	if err != nil {
+		writeErrors(w, status, graphql.PresentErrors(r.Context(), h.opts.ErrorPresenter, gqlerrors.FormatErrors(err)))
+		return
+	}
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	op, errs := h.opts.prepare(ctx, h.schema, req)
+	if errs != nil {
+		writeErrors(w, http.StatusBadRequest, errs)
+		return
+	}
+	if r.Method == http.MethodGet && op.plan.OperationType() == ast.OperationTypeMutation {
+		w.Header().Set("Allow", http.MethodPost)
+		writeErrors(w, http.StatusMethodNotAllowed, graphql.PresentErrors(ctx, h.opts.ErrorPresenter,
+			gqlerrors.FormatErrors(errors.New("Mutations can only be sent over POST."))))
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream; charset=utf-8")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var keepAlive <-chan time.Time
+	if h.opts.KeepAlive > 0 {
+		ticker := time.NewTicker(h.opts.KeepAlive)
+		defer ticker.Stop()
+		keepAlive = ticker.C
+	}
+	results := op.execute()
+	for {
+		select {
+		case <-keepAlive:
+			if _, err := io.WriteString(w, ":\n\n"); err != nil {
+				cancel()
+				continue
+			}
+			flusher.Flush()
+		case result, more := <-results:
+			if !more {
+				if ctx.Err() == nil {
+					writeEvent(w, EventComplete, nil)
+					flusher.Flush()
+				}
+				return
+			}
+			if ctx.Err() != nil {
+				// the client is gone; drain so the executor can finish
+				continue
+			}
+			data, err := json.Marshal(result)
+			if err != nil {
+				data, _ = json.Marshal(&graphql.Result{Errors: graphql.PresentErrors(ctx, h.opts.ErrorPresenter, gqlerrors.FormatErrors(err))})
+			}
+			if err := writeEvent(w, EventNext, data); err != nil {
+				cancel()
+				continue
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// readRequest decodes the request from the URL parameters of a GET or the
+// application/json body of a POST. On failure it returns the HTTP status
+// to answer with.
+func (h *SSEHandler) readRequest(w http.ResponseWriter, r *http.Request) (graphql.Request, int, error) {
+	var req graphql.Request
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.DocumentID = q.Get("documentId")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				return req, http.StatusBadRequest, fmt.Errorf("Invalid variables: %v", err)
+			}
+		}
+		if v := q.Get("extensions"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Extensions); err != nil {
+				return req, http.StatusBadRequest, fmt.Errorf("Invalid extensions: %v", err)
+			}
+		}
+	case http.MethodPost:
+		// other content types could be posted cross-origin by a plain
+		// HTML form, without a CORS preflight
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			return req, http.StatusUnsupportedMediaType, errors.New("Requests must be sent as application/json.")
+		}
+		body := http.MaxBytesReader(w, r.Body, h.opts.MaxBodySize)
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return req, http.StatusRequestEntityTooLarge, errors.New("Request body too large.")
+			}
+			return req, http.StatusBadRequest, fmt.Errorf("Invalid request body: %v", err)
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		return req, http.StatusMethodNotAllowed, fmt.Errorf("Method %s not allowed.", r.Method)
+	}
+	return req, 0, nil
+}
+
+// writeEvent writes one event; data must not contain newlines, which holds
+// for encoding/json output.
+func writeEvent(w io.Writer, event string, data []byte) error {
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
+
+func writeErrors(w http.ResponseWriter, status int, errs []gqlerrors.FormattedError) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(&graphql.Result{Errors: errs})
+}
@@ -0,0 +1,211 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+type sseEvent struct {
+	event string
+	data  string
+}
+
+// readSSE parses events from an event stream until it ends or n events
+// were read; comment lines are skipped.
+func readSSE(t *testing.T, body io.Reader, n int) []sseEvent {
+	t.Helper()
+	var events []sseEvent
+	var current sseEvent
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if current.event != "" {
+				events = append(events, current)
+				if len(events) == n {
+					return events
+				}
+			}
+			current = sseEvent{}
+		case strings.HasPrefix(line, ":"):
+		case strings.HasPrefix(line, "event: "):
+			current.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		default:
+			t.Fatalf("unexpected line %q", line)
+		}
+	}
+	return events
+}
+
+func newSSEServer(t *testing.T, opts SSEOptions) *httptest.Server {
+	srv := httptest.NewServer(NewSSEHandler(wsTestSchema(t), opts))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func postSSE(t *testing.T, ctx context.Context, srv *httptest.Server, body string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestSSE_StreamsSubscription(t *testing.T) {
+	srv := newSSEServer(t, SSEOptions{})
+	resp := postSSE(t, context.Background(), srv, `{"query":"subscription ($to: Int) { counter(to: $to) }","variables":{"to":2}}`)
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("expected an event stream, got %q", ct)
+	}
+	expected := []sseEvent{
+		{EventNext, `{"data":{"counter":1}}`},
+		{EventNext, `{"data":{"counter":2}}`},
+		{EventComplete, ""},
+	}
+	if got := readSSE(t, resp.Body, -1); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestSSE_ExecutesQueriesOverGet(t *testing.T) {
+	srv := newSSEServer(t, SSEOptions{})
+	resp, err := http.Get(srv.URL + "?query=" + url.QueryEscape("{ hello }"))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	expected := []sseEvent{{EventNext, `{"data":{"hello":"hello"}}`}, {EventComplete, ""}}
+	if got := readSSE(t, resp.Body, -1); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestSSE_RequestErrors(t *testing.T) {
+	srv := newSSEServer(t, SSEOptions{
+		MaxBodySize: 64,
+		ExecutionOptions: ExecutionOptions{
+			ErrorPresenter: func(ctx context.Context, err gqlerrors.FormattedError) gqlerrors.FormattedError {
+				err.Message = "presented: " + err.Message
+				return err
+			},
+		},
+	})
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"validation error", http.MethodPost, "", "application/json", `{"query":"{ nope }"}`, http.StatusBadRequest},
+		{"malformed body", http.MethodPost, "", "application/json", `{`, http.StatusBadRequest},
+		{"body too large", http.MethodPost, "", "application/json", `{"query":"` + strings.Repeat(" ", 64) + `{ hello }"}`, http.StatusRequestEntityTooLarge},
+		{"plain text body", http.MethodPost, "", "text/plain", `{"query":"{ hello }"}`, http.StatusUnsupportedMediaType},
+		{"no content type", http.MethodPost, "", "", `{"query":"{ hello }"}`, http.StatusUnsupportedMediaType},
+		{"bad variables", http.MethodGet, "?query=%7Bhello%7D&variables=%7B", "", "", http.StatusBadRequest},
+		{"unsupported method", http.MethodPut, "", "", "", http.StatusMethodNotAllowed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, _ := http.NewRequest(test.method, srv.URL+test.target, strings.NewReader(test.body))
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != test.status {
+				t.Fatalf("expected %d, got %d", test.status, resp.StatusCode)
+			}
+			if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+				t.Fatalf("expected a JSON error response, got %q", ct)
+			}
+			var result graphql.Result
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("invalid error response: %v", err)
+			}
+			if len(result.Errors) == 0 || !strings.HasPrefix(result.Errors[0].Message, "presented: ") {
+				t.Fatalf("expected presented errors, got %v", result.Errors)
+			}
+		})
+	}
+}
+
+func TestSSE_RejectsMutationsOverGet(t *testing.T) {
+	srv := newSSEServer(t, SSEOptions{})
+	resp, err := http.Get(srv.URL + "?query=" + url.QueryEscape("mutation { touch }"))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", resp.StatusCode)
+	}
+
+	resp = postSSE(t, context.Background(), srv, `{"query":"mutation { touch }"}`)
+	expected := []sseEvent{{EventNext, `{"data":{"touch":true}}`}, {EventComplete, ""}}
+	if got := readSSE(t, resp.Body, -1); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestSSE_StopsSubscriptionOnDisconnect(t *testing.T) {
+	srv := newSSEServer(t, SSEOptions{})
+	ctx, cancel := context.WithCancel(context.Background())
+	resp := postSSE(t, ctx, srv, `{"query":"subscription { counter }"}`)
+	if got := readSSE(t, resp.Body, 1); len(got) != 1 || got[0].event != EventNext {
+		t.Fatalf("expected a next event, got %v", got)
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		srv.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the handler kept streaming after the client disconnected")
+	}
+}
+
+func TestSSE_KeepAlive(t *testing.T) {
+	srv := newSSEServer(t, SSEOptions{KeepAlive: 10 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	resp := postSSE(t, ctx, srv, `{"query":"subscription { counter(to: 0) }"}`)
+	br := bufio.NewReader(resp.Body)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if line == ":\n" {
+			return
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Package transport serves GraphQL operations, subscriptions in particular,
+// over streaming transports:
+//
+//   - WebSocketHandler speaks the graphql-transport-ws WebSocket
+//     subprotocol.
+//   - SSEHandler streams one operation per request as Server-Sent Events.
+//
+// Every transport plans requests through an optional graphql.PlanCache,
+// graphql.PersistedQueries or graphql.TrustedDocuments and runs them with
+// graphql.ExecutePlan or graphql.ExecuteSubscriptionPlan.
+package transport
+
+import (
+	"context"
+	"errors"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// ExecutionOptions are the execution settings shared by the transports.
+// Cache, when set, caches the parsed, validated and planned requests.
+// PersistedQueries, when set, serves Automatic Persisted Queries through
+// its own PlanCache; TrustedDocuments, when set, only runs the registered
+// documents and takes precedence over both.
+type ExecutionOptions struct {
+	Cache            *graphql.PlanCache
+	PersistedQueries *graphql.PersistedQueries
+	TrustedDocuments *graphql.TrustedDocuments
+	RootObject       map[string]interface{}
+	ErrorPresenter   graphql.ErrorPresenterFn
+	PanicHandler     graphql.PanicHandlerFn
+}
+
+// operation is a planned request, ready to run.
+type operation struct {
+	plan   *graphql.Plan
+	params graphql.ExecuteParams
+}
+
+// prepare plans req. Requests that can't be executed at all (parse,
+// validation and planning errors) get the errors instead.
+func (o *ExecutionOptions) prepare(ctx context.Context, schema *graphql.Schema, req graphql.Request) (*operation, []gqlerrors.FormattedError) {
+	if req.Query == "" && req.DocumentID == "" && req.Extensions["persistedQuery"] == nil {
+		return nil, graphql.PresentErrors(ctx, o.ErrorPresenter, gqlerrors.FormatErrors(errors.New("Must provide an operation.")))
+	}
+	pr := o.planner().PlanRequest(ctx, schema, req)
+	if len(pr.Errors) > 0 {
+		return nil, graphql.PresentErrors(ctx, o.ErrorPresenter, pr.Errors)
+	}
+	args := req.Variables
+	if len(pr.SynthArgs) > 0 {
+		args = make(map[string]interface{}, len(req.Variables)+len(pr.SynthArgs))
+		for k, v := range req.Variables {
+			args[k] = v
+		}
+		for k, v := range pr.SynthArgs {
+			args[k] = v
+		}
+	}
+	return &operation{
+		plan: pr.Plan,
+		params: graphql.ExecuteParams{
+			Schema:         *schema,
+			Root:           o.RootObject,
+			Args:           args,
+			Context:        ctx,
+			ErrorPresenter: o.ErrorPresenter,
+			PanicHandler:   o.PanicHandler,
+		},
+	}, nil
+}
+
+// planner returns what plans requests: the trusted documents, the
+// persisted queries or the plan cache, in that order of precedence.
+func (o *ExecutionOptions) planner() graphql.RequestPlanner {
+	switch {
+	case o.TrustedDocuments != nil:
+		return o.TrustedDocuments
+	case o.PersistedQueries != nil:
+		return o.PersistedQueries
+	}
+	return o.Cache
+}
+
+// execute runs the operation. Subscriptions yield a result per event;
+// queries and mutations yield a single result. The channel is closed once
+// the operation is over and must be drained.
+func (op *operation) execute() <-chan *graphql.Result {
+	if op.plan.OperationType() == ast.OperationTypeSubscription {
+		return graphql.ExecuteSubscriptionPlan(op.plan, op.params)
+	}
+	results := make(chan *graphql.Result, 1)
+	results <- graphql.ExecutePlan(op.plan, op.params)
+	close(results)
+	return results
+}
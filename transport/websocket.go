@@ -0,0 +1,341 @@
+package transport
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// This file implements the subset of RFC 6455 the GraphQL transports need:
+// the server handshake and a framed connection carrying text messages.
+// Extensions (e.g. permessage-deflate) are not negotiated.
+
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// WebSocket close codes used by the transports.
+const (
+	closeNormal        = 1000
+	closeProtocolError = 1002
+	closeInvalidData   = 1007
+	closeMessageTooBig = 1009
+)
+
+const maxControlPayload = 125
+
+// closeError reports a close frame received from the peer.
+type closeError struct {
+	code   int
+	reason string
+}
+
+func (e *closeError) Error() string {
+	return fmt.Sprintf("websocket: closed by peer: %d %s", e.code, e.reason)
+}
+
+// wsConn is a WebSocket connection. Reads must come from a single
+// goroutine; writes are serialized and may come from any goroutine.
+type wsConn struct {
+	conn    net.Conn
+	br      *bufio.Reader
+	client  bool
+	maxRead int64
+
+	wmu       sync.Mutex
+	closeSent bool
+}
+
+// upgradeWebSocket performs the server side of the opening handshake,
+// selecting subprotocol, which the client must offer. On failure the
+// HTTP error response has been written.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request, subprotocol string, checkOrigin func(*http.Request) bool) (*wsConn, error) {
+	fail := func(status int, msg string) (*wsConn, error) {
+		http.Error(w, msg, status)
+		return nil, errors.New("websocket: " + msg)
+	}
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		return fail(http.StatusMethodNotAllowed, "method not allowed")
+	}
+	if !headerHasToken(r.Header, "Connection", "upgrade") || !headerHasToken(r.Header, "Upgrade", "websocket") {
+		return fail(http.StatusBadRequest, "not a websocket handshake")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return fail(http.StatusBadRequest, "unsupported websocket version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return fail(http.StatusBadRequest, "missing Sec-WebSocket-Key")
+	}
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+	if !checkOrigin(r) {
+		return fail(http.StatusForbidden, "origin not allowed")
+	}
+	if !headerHasToken(r.Header, "Sec-WebSocket-Protocol", subprotocol) {
+		return fail(http.StatusBadRequest, fmt.Sprintf("subprotocol %q is required", subprotocol))
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return fail(http.StatusInternalServerError, "connection does not support hijacking")
+	}
+	conn, brw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(brw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n"+
+		"Sec-WebSocket-Protocol: %s\r\n\r\n", websocketAccept(key), subprotocol)
+	if err := brw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &wsConn{conn: conn, br: brw.Reader}, nil
+}
+
+func websocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// headerHasToken reports whether the comma-separated header name contains
+// token, compared case-insensitively.
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sameOrigin accepts requests without an Origin header (non-browser
+// clients) and requests whose Origin host matches Host.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// readMessage returns the next text or binary message, answering pings
+// and reassembling fragments on the way. A close frame from the peer is
+// acknowledged and reported as a *closeError.
+func (c *wsConn) readMessage() (opcode int, payload []byte, err error) {
+	var message []byte
+	opcode = -1
+	for {
+		fin, op, data, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch op {
+		case opPing:
+			if err := c.writeFrame(opPong, data); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			ce := &closeError{code: 1005}
+			if len(data) >= 2 {
+				ce.code = int(binary.BigEndian.Uint16(data))
+				ce.reason = string(data[2:])
+			}
+			// 1005 (no status) must not be sent back
+			echo := ce.code
+			if echo == 1005 {
+				echo = closeNormal
+			}
+			c.close(echo, "")
+			return 0, nil, ce
+		case opText, opBinary:
+			if opcode != -1 {
+				c.close(closeProtocolError, "expected continuation frame")
+				return 0, nil, errors.New("websocket: expected continuation frame")
+			}
+			opcode = op
+		case opContinuation:
+			if opcode == -1 {
+				c.close(closeProtocolError, "unexpected continuation frame")
+				return 0, nil, errors.New("websocket: unexpected continuation frame")
+			}
+		default:
+			c.close(closeProtocolError, "unknown opcode")
+			return 0, nil, fmt.Errorf("websocket: unknown opcode %d", op)
+		}
+		message = append(message, data...)
+		if c.maxRead > 0 && int64(len(message)) > c.maxRead {
+			c.close(closeMessageTooBig, "message too big")
+			return 0, nil, errors.New("websocket: message too big")
+		}
+		if !fin {
+			continue
+		}
+		if opcode == opText && !utf8.Valid(message) {
+			c.close(closeInvalidData, "invalid UTF-8")
+			return 0, nil, errors.New("websocket: invalid UTF-8 in text message")
+		}
+		return opcode, message, nil
+	}
+}
+
+func (c *wsConn) readFrame() (fin bool, opcode int, payload []byte, err error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = head[0]&0x80 != 0
+	if head[0]&0x70 != 0 {
+		c.close(closeProtocolError, "reserved bits set")
+		return false, 0, nil, errors.New("websocket: reserved bits set")
+	}
+	opcode = int(head[0] & 0x0F)
+	masked := head[1]&0x80 != 0
+	if masked == c.client {
+		// clients mask every frame, servers none
+		c.close(closeProtocolError, "bad masking")
+		return false, 0, nil, errors.New("websocket: bad masking")
+	}
+	length := int64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+	if opcode >= opClose && (length > maxControlPayload || !fin) {
+		c.close(closeProtocolError, "bad control frame")
+		return false, 0, nil, errors.New("websocket: bad control frame")
+	}
+	if length < 0 || (c.maxRead > 0 && length > c.maxRead) {
+		c.close(closeMessageTooBig, "message too big")
+		return false, 0, nil, errors.New("websocket: message too big")
+	}
+	var key [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, key[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		maskBytes(key, payload)
+	}
+	return fin, opcode, payload, nil
+}
+
+// writeMessage sends data as a single text frame.
+func (c *wsConn) writeMessage(data []byte) error {
+	return c.writeFrame(opText, data)
+}
+
+func (c *wsConn) writeFrame(opcode int, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closeSent {
+		return net.ErrClosed
+	}
+	return c.writeFrameLocked(opcode, payload)
+}
+
+func (c *wsConn) writeFrameLocked(opcode int, payload []byte) error {
+	frame := make([]byte, 0, 14+len(payload))
+	frame = append(frame, 0x80|byte(opcode))
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n <= 125:
+		frame = append(frame, maskBit|byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	if c.client {
+		var key [4]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return err
+		}
+		frame = append(frame, key[:]...)
+		start := len(frame)
+		frame = append(frame, payload...)
+		maskBytes(key, frame[start:])
+	} else {
+		frame = append(frame, payload...)
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// close sends a close frame with code and reason, unless one was sent
+// already, and closes the underlying connection.
+func (c *wsConn) close(code int, reason string) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closeSent {
+		return nil
+	}
+	c.closeSent = true
+	if len(reason) > maxControlPayload-2 {
+		reason = reason[:maxControlPayload-2]
+	}
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	payload = append(payload, reason...)
+	c.writeFrameLocked(opClose, payload)
+	return c.conn.Close()
+}
+
+func maskBytes(key [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= key[i%4]
+	}
+}
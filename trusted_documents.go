@@ -0,0 +1,231 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Error codes reported in `extensions.code` by TrustedDocuments.
+const (
+	ErrCodeUnknownDocumentID       = "UNKNOWN_DOCUMENT_ID"
+	ErrCodeTrustedDocumentRequired = "TRUSTED_DOCUMENT_REQUIRED"
+)
+
+// TrustedDocuments is an operation allow-list: a registry of documents
+// known at build time, keyed by an opaque document ID. Every document is
+// parsed, validated and planned once, when the registry is built, so a
+// malformed manifest fails at startup rather than on the first request.
+//
+// Unless AllowArbitraryQueries is set, requests must name a registered
+// document (or send its exact text); anything else is rejected with
+// ErrCodeTrustedDocumentRequired.
+//
+// The registry is immutable once built and safe for concurrent use. Like
+// plans, it is bound to the *Schema it was built against.
+type TrustedDocuments struct {
+	opts   TrustedDocumentsOptions
+	schema *Schema
+	docs   map[string]*trustedDocument
+	byText map[string]*trustedDocument
+}
+
+// TrustedDocumentsOptions tunes the registry. AllowArbitraryQueries lets
+// requests that don't match a registered document through, planned via
+// Cache — useful while migrating clients; leave it off to enforce the
+// allow-list.
+type TrustedDocumentsOptions struct {
+	AllowArbitraryQueries bool
+	Cache                 *PlanCache
+}
+
+type trustedDocument struct {
+	id    string
+	query string
+	doc   *ast.Document
+
+	// plans holds one plan per operation, keyed by operation name; a
+	// single-operation document is also stored under "".
+	plans map[string]*Plan
+}
+
+// NewTrustedDocuments builds a registry from manifest, a mapping of
+// document ID to document text.
+func NewTrustedDocuments(schema *Schema, manifest map[string]string, opts TrustedDocumentsOptions) (*TrustedDocuments, error) {
+	if schema == nil {
+		return nil, errors.New("graphql: NewTrustedDocuments: schema is nil")
+	}
+	td := &TrustedDocuments{
+		opts:   opts,
+		schema: schema,
+		docs:   make(map[string]*trustedDocument, len(manifest)),
+		byText: make(map[string]*trustedDocument, len(manifest)),
+	}
+	// build in ID order so the reported error doesn't depend on map order
+	ids := make([]string, 0, len(manifest))
+	for id := range manifest {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		doc, err := planTrustedDocument(schema, id, manifest[id])
+		if err != nil {
+			return nil, err
+		}
+		td.docs[id] = doc
+		td.byText[doc.query] = doc
+	}
+	return td, nil
+}
+
+func planTrustedDocument(schema *Schema, id, query string) (*trustedDocument, error) {
+	src := source.NewSource(&source.Source{Body: []byte(query), Name: "GraphQL request"})
+	doc, err := parser.Parse(parser.ParseParams{Source: src})
+	if err != nil {
+		return nil, fmt.Errorf("trusted document %q: %v", id, err)
+	}
+	if vr := ValidateDocument(schema, doc, nil); !vr.IsValid {
+		return nil, fmt.Errorf("trusted document %q: %v", id, vr.Errors[0].Message)
+	}
+	td := &trustedDocument{id: id, query: query, doc: doc, plans: map[string]*Plan{}}
+	var operations []*ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		if op, ok := def.(*ast.OperationDefinition); ok {
+			operations = append(operations, op)
+		}
+	}
+	for _, op := range operations {
+		name := ""
+		if op.GetName() != nil {
+			name = op.GetName().Value
+		}
+		plan, err := PlanQuery(schema, doc, name)
+		if err != nil {
+			return nil, fmt.Errorf("trusted document %q: %v", id, err)
+		}
+		td.plans[name] = plan
+		if len(operations) == 1 {
+			td.plans[""] = plan
+		}
+	}
+	return td, nil
+}
+
+// Get resolves a request to a PlanResult. A non-empty documentID selects
+// a registered document; otherwise query must be the exact text of one,
+// unless AllowArbitraryQueries is set.
+func (td *TrustedDocuments) Get(documentID, query, operationName string) PlanResult {
+	return td.get(context.Background(), documentID, query, operationName)
+}
+
+// PlanRequest implements RequestPlanner. Besides Request.DocumentID, a
+// request without query text may name its document by the hash of its
+// `extensions.persistedQuery`, for clients whose manifest is keyed by
+// hash. schema must be the schema the registry was built against.
+func (td *TrustedDocuments) PlanRequest(ctx context.Context, schema *Schema, req Request) PlanResult {
+	if schema != td.schema {
+		return PlanResult{Errors: gqlerrors.FormatErrors(
+			errors.New("graphql: TrustedDocuments.PlanRequest: the registry was built against another schema"),
+		)}
+	}
+	documentID := req.DocumentID
+	if documentID == "" && req.Query == "" {
+		hash, _, err := PersistedQueryHash(req.Extensions)
+		if err != nil {
+			return PlanResult{Errors: gqlerrors.FormatErrors(err)}
+		}
+		documentID = hash
+	}
+	return td.get(ctx, documentID, req.Query, req.OperationName)
+}
+
+func (td *TrustedDocuments) get(ctx context.Context, documentID, query, operationName string) PlanResult {
+	var doc *trustedDocument
+	switch {
+	case documentID != "":
+		doc = td.docs[documentID]
+		if doc == nil {
+			return PlanResult{Errors: gqlerrors.FormatErrors(
+				gqlerrors.NewCodedError(ErrCodeUnknownDocumentID, fmt.Sprintf("Unknown document id %q.", documentID)),
+			)}
+		}
+	default:
+		doc = td.byText[query]
+		if doc == nil {
+			if td.opts.AllowArbitraryQueries {
+				return td.opts.Cache.GetContext(ctx, td.schema, query, operationName)
+			}
+			return PlanResult{Errors: gqlerrors.FormatErrors(
+				gqlerrors.NewCodedError(ErrCodeTrustedDocumentRequired, "Only trusted documents may be executed."),
+			)}
+		}
+	}
+	if plan, ok := doc.plans[operationName]; ok {
+		return PlanResult{Plan: plan}
+	}
+	// Unknown or ambiguous operation name: let the planner report it.
+	if _, err := PlanQuery(td.schema, doc.doc, operationName); err != nil {
+		return PlanResult{Errors: gqlerrors.FormatErrors(err)}
+	}
+	return PlanResult{Errors: gqlerrors.FormatErrors(fmt.Errorf(`Unknown operation named "%v".`, operationName))}
+}
+
+// Query returns the text of the document registered under documentID.
+func (td *TrustedDocuments) Query(documentID string) (string, bool) {
+	doc, ok := td.docs[documentID]
+	if !ok {
+		return "", false
+	}
+	return doc.query, true
+}
+
+// Len returns the number of registered documents.
+func (td *TrustedDocuments) Len() int {
+	return len(td.docs)
+}
+
+// ReadTrustedDocumentsManifest decodes a JSON manifest. Two layouts are
+// accepted: a flat object mapping document ID to document text, and the
+// persisted query manifest layout
+//
+//	{"format": "apollo-persisted-query-manifest", "version": 1,
+//	 "operations": [{"id": "...", "name": "...", "body": "..."}]}
+func ReadTrustedDocumentsManifest(r io.Reader) (map[string]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("trusted documents manifest: %v", err)
+	}
+	manifest := map[string]string{}
+	if ops, ok := raw["operations"]; ok {
+		var operations []struct {
+			ID   string `json:"id"`
+			Body string `json:"body"`
+		}
+		if err := json.Unmarshal(ops, &operations); err != nil {
+			return nil, fmt.Errorf("trusted documents manifest: %v", err)
+		}
+		for _, op := range operations {
+			if op.ID == "" {
+				return nil, errors.New("trusted documents manifest: operation without an id")
+			}
+			manifest[op.ID] = op.Body
+		}
+		return manifest, nil
+	}
+	for id, body := range raw {
+		var query string
+		if err := json.Unmarshal(body, &query); err != nil {
+			return nil, fmt.Errorf("trusted documents manifest: document %q: %v", id, err)
+		}
+		manifest[id] = query
+	}
+	return manifest, nil
+}
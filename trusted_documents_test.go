@@ -0,0 +1,111 @@
+package graphql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/benchutil"
+)
+
+func TestTrustedDocuments_ExecutesRegisteredDocuments(t *testing.T) {
+	schema := benchutil.WideArgedSchemaWithXFieldsAndYItems(5, 1)
+	td, err := graphql.NewTrustedDocuments(&schema, map[string]string{
+		"one":  `{ wide { a(value: "x") } }`,
+		"many": `query A { wide { a(value: "a") } } query B { wide { b(value: "b") } }`,
+	}, graphql.TrustedDocumentsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if td.Len() != 2 {
+		t.Fatalf("expected 2 documents, got %d", td.Len())
+	}
+
+	pr := td.Get("one", "", "")
+	if len(pr.Errors) > 0 || pr.Plan == nil {
+		t.Fatalf("one: %v", pr.Errors)
+	}
+	if again := td.Get("one", "", ""); again.Plan != pr.Plan {
+		t.Fatal("expected the pre-built plan to be reused")
+	}
+	result := graphql.ExecutePlan(pr.Plan, graphql.ExecuteParams{Schema: schema})
+	if len(result.Errors) > 0 {
+		t.Fatalf("execute: %v", result.Errors)
+	}
+
+	if pr := td.Get("many", "", "B"); len(pr.Errors) > 0 || pr.Plan == nil {
+		t.Fatalf("many/B: %v", pr.Errors)
+	}
+	if pr := td.Get("many", "", ""); len(pr.Errors) != 1 {
+		t.Fatalf("expected an ambiguous operation error, got %v", pr.Errors)
+	}
+
+	// the exact registered text is accepted without an ID
+	if pr := td.Get("", `{ wide { a(value: "x") } }`, ""); len(pr.Errors) > 0 {
+		t.Fatalf("registered text: %v", pr.Errors)
+	}
+}
+
+func TestTrustedDocuments_RejectsUnknownDocuments(t *testing.T) {
+	schema := benchutil.WideArgedSchemaWithXFieldsAndYItems(5, 1)
+	td, err := graphql.NewTrustedDocuments(&schema, map[string]string{
+		"one": `{ wide { a(value: "x") } }`,
+	}, graphql.TrustedDocumentsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pr := td.Get("two", "", "")
+	if len(pr.Errors) != 1 || pr.Errors[0].Extensions["code"] != graphql.ErrCodeUnknownDocumentID {
+		t.Fatalf("expected an unknown document error, got %v", pr.Errors)
+	}
+	pr = td.Get("", `{ wide { a(value: "y") } }`, "")
+	if len(pr.Errors) != 1 || pr.Errors[0].Extensions["code"] != graphql.ErrCodeTrustedDocumentRequired {
+		t.Fatalf("expected arbitrary queries to be rejected, got %v", pr.Errors)
+	}
+}
+
+func TestTrustedDocuments_AllowArbitraryQueries(t *testing.T) {
+	schema := benchutil.WideArgedSchemaWithXFieldsAndYItems(5, 1)
+	td, err := graphql.NewTrustedDocuments(&schema, nil, graphql.TrustedDocumentsOptions{
+		AllowArbitraryQueries: true,
+		Cache:                 graphql.NewPlanCache(graphql.PlanCacheOptions{}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr := td.Get("", `{ wide { a(value: "y") } }`, ""); len(pr.Errors) > 0 || pr.Plan == nil {
+		t.Fatalf("arbitrary query: %v", pr.Errors)
+	}
+}
+
+func TestTrustedDocuments_InvalidManifest(t *testing.T) {
+	schema := benchutil.WideArgedSchemaWithXFieldsAndYItems(5, 1)
+	_, err := graphql.NewTrustedDocuments(&schema, map[string]string{
+		"bad": `{ nope }`,
+	}, graphql.TrustedDocumentsOptions{})
+	if err == nil || !strings.Contains(err.Error(), `trusted document "bad"`) {
+		t.Fatalf("expected a build error naming the document, got %v", err)
+	}
+}
+
+func TestReadTrustedDocumentsManifest(t *testing.T) {
+	flat, err := graphql.ReadTrustedDocumentsManifest(strings.NewReader(`{"abc": "{ a }"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flat["abc"] != "{ a }" {
+		t.Fatalf("unexpected flat manifest %v", flat)
+	}
+	apollo, err := graphql.ReadTrustedDocumentsManifest(strings.NewReader(`{
+		"format": "apollo-persisted-query-manifest",
+		"version": 1,
+		"operations": [{"id": "def", "name": "B", "type": "query", "body": "query B { b }"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if apollo["def"] != "query B { b }" || len(apollo) != 1 {
+		t.Fatalf("unexpected operations manifest %v", apollo)
+	}
+}
@@ -0,0 +1,40 @@
+package graphql
+
+import (
+	"mime/multipart"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// UploadFile is a file sent along a GraphQL multipart request (see the
+// handler package), the value of Upload arguments. File is valid until the
+// request completes; resolvers that keep the content must copy it.
+type UploadFile struct {
+	Filename    string
+	ContentType string
+	Size        int64
+	File        multipart.File
+}
+
+// Upload is the scalar of file uploads. It is input-only: its values are
+// *UploadFile, placed into the request's variables by the transport, and
+// it has no literal form.
+var Upload = NewScalar(ScalarConfig{
+	Name:        "Upload",
+	Description: "The `Upload` scalar type represents a file sent with a multipart request.",
+	Serialize: func(value interface{}) interface{} {
+		return nil
+	},
+	ParseValue: func(value interface{}) interface{} {
+		switch value := value.(type) {
+		case *UploadFile:
+			return value
+		case UploadFile:
+			return &value
+		}
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		return nil
+	},
+})
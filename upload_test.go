@@ -0,0 +1,80 @@
+package graphql_test
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+type uploadTestFile struct {
+	*strings.Reader
+}
+
+func (uploadTestFile) Close() error { return nil }
+
+func uploadSchema(t *testing.T) graphql.Schema {
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"read": &graphql.Field{
+					Type: graphql.String,
+					Args: graphql.FieldConfigArgument{
+						"file": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Upload)},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						upload := p.Args["file"].(*graphql.UploadFile)
+						content, err := io.ReadAll(upload.File)
+						if err != nil {
+							return nil, err
+						}
+						return upload.Filename + ": " + string(content), nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return schema
+}
+
+func TestUpload_AcceptsFilesInVariables(t *testing.T) {
+	upload := &graphql.UploadFile{
+		Filename: "a.txt",
+		File:     uploadTestFile{strings.NewReader("content")},
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:         uploadSchema(t),
+		RequestString:  `query ($file: Upload!) { read(file: $file) }`,
+		VariableValues: map[string]interface{}{"file": upload},
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+	expected := map[string]interface{}{"read": "a.txt: content"}
+	if !reflect.DeepEqual(result.Data, expected) {
+		t.Fatalf("expected %v, got %v", expected, result.Data)
+	}
+}
+
+func TestUpload_RejectsOtherValues(t *testing.T) {
+	schema := uploadSchema(t)
+	for _, query := range []string{
+		`{ read(file: "a.txt") }`,
+		`query ($file: Upload!) { read(file: $file) }`,
+	} {
+		result := graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  query,
+			VariableValues: map[string]interface{}{"file": "a.txt"},
+		})
+		if len(result.Errors) == 0 {
+			t.Fatalf("%s: expected an error, got %v", query, result.Data)
+		}
+	}
+}